@@ -123,18 +123,128 @@ func (x *GetBlockFilterResponse) GetFilter() string {
 	return ""
 }
 
+type GetBlockTaprootFilterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockId uint32 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+}
+
+func (x *GetBlockTaprootFilterRequest) Reset() {
+	*x = GetBlockTaprootFilterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBlockTaprootFilterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockTaprootFilterRequest) ProtoMessage() {}
+
+func (x *GetBlockTaprootFilterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockTaprootFilterRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockTaprootFilterRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBlockTaprootFilterRequest) GetBlockId() uint32 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+type GetBlockTaprootFilterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Blockhash string `protobuf:"bytes,1,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+	// hex-encoded GCS filter (P = 19, M = 784931) over the x-only keys of the unspent taproot outputs.
+	Filter string `protobuf:"bytes,2,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (x *GetBlockTaprootFilterResponse) Reset() {
+	*x = GetBlockTaprootFilterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[3]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBlockTaprootFilterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockTaprootFilterResponse) ProtoMessage() {}
+
+func (x *GetBlockTaprootFilterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[3]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockTaprootFilterResponse.ProtoReflect.Descriptor instead.
+func (*GetBlockTaprootFilterResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetBlockTaprootFilterResponse) GetBlockhash() string {
+	if x != nil {
+		return x.Blockhash
+	}
+	return ""
+}
+
+func (x *GetBlockTaprootFilterResponse) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
 type GetBlockScalarsRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	BlockId uint32 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	// if true, the response includes the transactions view.
+	Verbose bool `protobuf:"varint,2,opt,name=verbose,proto3" json:"verbose,omitempty"`
+	// "unspent" (default) keeps the transactions with at least 1 unspent taproot output,
+	// "all" keeps every indexed transaction.
+	Filter string `protobuf:"bytes,3,opt,name=filter,proto3" json:"filter,omitempty"`
+	// drops the transactions with all their selected taproot outputs below dust_limit sats, 0 to keep dust.
+	DustLimit uint64 `protobuf:"varint,4,opt,name=dust_limit,json=dustLimit,proto3" json:"dust_limit,omitempty"`
 }
 
 func (x *GetBlockScalarsRequest) Reset() {
 	*x = GetBlockScalarsRequest{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_silentium_v1_silentium_proto_msgTypes[2]
+		mi := &file_silentium_v1_silentium_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -147,7 +257,7 @@ func (x *GetBlockScalarsRequest) String() string {
 func (*GetBlockScalarsRequest) ProtoMessage() {}
 
 func (x *GetBlockScalarsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_silentium_v1_silentium_proto_msgTypes[2]
+	mi := &file_silentium_v1_silentium_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -160,7 +270,7 @@ func (x *GetBlockScalarsRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockScalarsRequest.ProtoReflect.Descriptor instead.
 func (*GetBlockScalarsRequest) Descriptor() ([]byte, []int) {
-	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{2}
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *GetBlockScalarsRequest) GetBlockId() uint32 {
@@ -170,18 +280,40 @@ func (x *GetBlockScalarsRequest) GetBlockId() uint32 {
 	return 0
 }
 
+func (x *GetBlockScalarsRequest) GetVerbose() bool {
+	if x != nil {
+		return x.Verbose
+	}
+	return false
+}
+
+func (x *GetBlockScalarsRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *GetBlockScalarsRequest) GetDustLimit() uint64 {
+	if x != nil {
+		return x.DustLimit
+	}
+	return 0
+}
+
 type GetBlockScalarsResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Scalars []string `protobuf:"bytes,1,rep,name=scalars,proto3" json:"scalars,omitempty"`
+	Scalars      []string    `protobuf:"bytes,1,rep,name=scalars,proto3" json:"scalars,omitempty"`
+	Transactions []*TxScalar `protobuf:"bytes,2,rep,name=transactions,proto3" json:"transactions,omitempty"`
 }
 
 func (x *GetBlockScalarsResponse) Reset() {
 	*x = GetBlockScalarsResponse{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_silentium_v1_silentium_proto_msgTypes[3]
+		mi := &file_silentium_v1_silentium_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -194,7 +326,7 @@ func (x *GetBlockScalarsResponse) String() string {
 func (*GetBlockScalarsResponse) ProtoMessage() {}
 
 func (x *GetBlockScalarsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_silentium_v1_silentium_proto_msgTypes[3]
+	mi := &file_silentium_v1_silentium_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -207,7 +339,7 @@ func (x *GetBlockScalarsResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use GetBlockScalarsResponse.ProtoReflect.Descriptor instead.
 func (*GetBlockScalarsResponse) Descriptor() ([]byte, []int) {
-	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{3}
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *GetBlockScalarsResponse) GetScalars() []string {
@@ -217,31 +349,41 @@ func (x *GetBlockScalarsResponse) GetScalars() []string {
 	return nil
 }
 
-type GetChainTipHeightRequest struct {
+func (x *GetBlockScalarsResponse) GetTransactions() []*TxScalar {
+	if x != nil {
+		return x.Transactions
+	}
+	return nil
+}
+
+type TxScalar struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	BlockId uint32 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+	Txid   string `protobuf:"bytes,1,opt,name=txid,proto3" json:"txid,omitempty"`
+	Scalar string `protobuf:"bytes,2,opt,name=scalar,proto3" json:"scalar,omitempty"`
+	// taproot outputs of the transaction selected by the request filter and dust_limit.
+	Outputs []*TaprootOutput `protobuf:"bytes,3,rep,name=outputs,proto3" json:"outputs,omitempty"`
 }
 
-func (x *GetChainTipHeightRequest) Reset() {
-	*x = GetChainTipHeightRequest{}
+func (x *TxScalar) Reset() {
+	*x = TxScalar{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_silentium_v1_silentium_proto_msgTypes[4]
+		mi := &file_silentium_v1_silentium_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetChainTipHeightRequest) String() string {
+func (x *TxScalar) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetChainTipHeightRequest) ProtoMessage() {}
+func (*TxScalar) ProtoMessage() {}
 
-func (x *GetChainTipHeightRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_silentium_v1_silentium_proto_msgTypes[4]
+func (x *TxScalar) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -252,43 +394,61 @@ func (x *GetChainTipHeightRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetChainTipHeightRequest.ProtoReflect.Descriptor instead.
-func (*GetChainTipHeightRequest) Descriptor() ([]byte, []int) {
-	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{4}
+// Deprecated: Use TxScalar.ProtoReflect.Descriptor instead.
+func (*TxScalar) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *GetChainTipHeightRequest) GetBlockId() uint32 {
+func (x *TxScalar) GetTxid() string {
 	if x != nil {
-		return x.BlockId
+		return x.Txid
 	}
-	return 0
+	return ""
 }
 
-type GetChainTipHeightResponse struct {
+func (x *TxScalar) GetScalar() string {
+	if x != nil {
+		return x.Scalar
+	}
+	return ""
+}
+
+func (x *TxScalar) GetOutputs() []*TaprootOutput {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+type TaprootOutput struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Height uint32 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Index uint32 `protobuf:"varint,1,opt,name=index,proto3" json:"index,omitempty"`
+	// hex-encoded x-only output key, empty if not indexed.
+	Key    string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Amount uint64 `protobuf:"varint,3,opt,name=amount,proto3" json:"amount,omitempty"`
+	Spent  bool   `protobuf:"varint,4,opt,name=spent,proto3" json:"spent,omitempty"`
 }
 
-func (x *GetChainTipHeightResponse) Reset() {
-	*x = GetChainTipHeightResponse{}
+func (x *TaprootOutput) Reset() {
+	*x = TaprootOutput{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_silentium_v1_silentium_proto_msgTypes[5]
+		mi := &file_silentium_v1_silentium_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
 }
 
-func (x *GetChainTipHeightResponse) String() string {
+func (x *TaprootOutput) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetChainTipHeightResponse) ProtoMessage() {}
+func (*TaprootOutput) ProtoMessage() {}
 
-func (x *GetChainTipHeightResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_silentium_v1_silentium_proto_msgTypes[5]
+func (x *TaprootOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -299,179 +459,1709 @@ func (x *GetChainTipHeightResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetChainTipHeightResponse.ProtoReflect.Descriptor instead.
-func (*GetChainTipHeightResponse) Descriptor() ([]byte, []int) {
-	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{5}
+// Deprecated: Use TaprootOutput.ProtoReflect.Descriptor instead.
+func (*TaprootOutput) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *GetChainTipHeightResponse) GetHeight() uint32 {
+func (x *TaprootOutput) GetIndex() uint32 {
 	if x != nil {
-		return x.Height
+		return x.Index
 	}
 	return 0
 }
 
-var File_silentium_v1_silentium_proto protoreflect.FileDescriptor
+func (x *TaprootOutput) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
 
-var file_silentium_v1_silentium_proto_rawDesc = []byte{
-	0x0a, 0x1c, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2f, 0x76, 0x31, 0x2f, 0x73,
-	0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
-	0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x1a, 0x1c, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
-	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x32, 0x0a, 0x15, 0x47, 0x65,
-	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x22, 0x4e,
-	0x0a, 0x16, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f,
-	0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x33,
-	0x0a, 0x16, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72,
-	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x49, 0x64, 0x22, 0x33, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53,
-	0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
-	0x0a, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52,
-	0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x22, 0x35, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x43,
-	0x68, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x65, 0x71,
-	0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x22,
-	0x33, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x70, 0x48, 0x65,
-	0x69, 0x67, 0x68, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16, 0x0a, 0x06,
-	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68, 0x65,
-	0x69, 0x67, 0x68, 0x74, 0x32, 0x99, 0x03, 0x0a, 0x10, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69,
-	0x75, 0x6d, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x84, 0x01, 0x0a, 0x0f, 0x47, 0x65,
-	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x24, 0x2e,
-	0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
-	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e,
-	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61,
-	0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93,
-	0x02, 0x1e, 0x12, 0x1c, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2f, 0x7b, 0x62,
-	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73,
-	0x12, 0x80, 0x01, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c,
-	0x74, 0x65, 0x72, 0x12, 0x23, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e,
-	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65,
-	0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e,
-	0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
-	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x23,
-	0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x12, 0x1b, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63,
-	0x6b, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x66, 0x69, 0x6c,
-	0x74, 0x65, 0x72, 0x12, 0x7b, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54,
-	0x69, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x26, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e,
-	0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e,
-	0x54, 0x69, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
-	0x1a, 0x27, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e,
-	0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68,
-	0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02,
-	0x0f, 0x12, 0x0d, 0x2f, 0x76, 0x31, 0x2f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2f, 0x74, 0x69, 0x70,
-	0x42, 0xbf, 0x01, 0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69,
-	0x75, 0x6d, 0x2e, 0x76, 0x31, 0x42, 0x0e, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d,
-	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x4a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e,
-	0x63, 0x6f, 0x6d, 0x2f, 0x6c, 0x6f, 0x75, 0x69, 0x73, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x2f, 0x73,
-	0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x73, 0x69, 0x6c, 0x65, 0x6e,
-	0x74, 0x69, 0x75, 0x6d, 0x2f, 0x76, 0x31, 0x3b, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75,
-	0x6d, 0x76, 0x31, 0xa2, 0x02, 0x03, 0x53, 0x58, 0x58, 0xaa, 0x02, 0x0c, 0x53, 0x69, 0x6c, 0x65,
-	0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x0c, 0x53, 0x69, 0x6c, 0x65, 0x6e,
-	0x74, 0x69, 0x75, 0x6d, 0x5c, 0x56, 0x31, 0xe2, 0x02, 0x18, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74,
-	0x69, 0x75, 0x6d, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0xea, 0x02, 0x0d, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x3a, 0x3a,
-	0x56, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+func (x *TaprootOutput) GetAmount() uint64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
 }
 
-var (
-	file_silentium_v1_silentium_proto_rawDescOnce sync.Once
-	file_silentium_v1_silentium_proto_rawDescData = file_silentium_v1_silentium_proto_rawDesc
-)
+func (x *TaprootOutput) GetSpent() bool {
+	if x != nil {
+		return x.Spent
+	}
+	return false
+}
 
-func file_silentium_v1_silentium_proto_rawDescGZIP() []byte {
-	file_silentium_v1_silentium_proto_rawDescOnce.Do(func() {
-		file_silentium_v1_silentium_proto_rawDescData = protoimpl.X.CompressGZIP(file_silentium_v1_silentium_proto_rawDescData)
-	})
-	return file_silentium_v1_silentium_proto_rawDescData
+type GetBlockScalarsRangeRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	From uint32 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+	To   uint32 `protobuf:"varint,2,opt,name=to,proto3" json:"to,omitempty"`
+	// maximum number of blocks to return, capped by the server.
+	Limit uint32 `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`
+	// "unspent" (default) or "all", see GetBlockScalarsRequest.
+	Filter string `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+	// see GetBlockScalarsRequest.
+	DustLimit uint64 `protobuf:"varint,5,opt,name=dust_limit,json=dustLimit,proto3" json:"dust_limit,omitempty"`
 }
 
-var file_silentium_v1_silentium_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
-var file_silentium_v1_silentium_proto_goTypes = []interface{}{
-	(*GetBlockFilterRequest)(nil),     // 0: silentium.v1.GetBlockFilterRequest
-	(*GetBlockFilterResponse)(nil),    // 1: silentium.v1.GetBlockFilterResponse
-	(*GetBlockScalarsRequest)(nil),    // 2: silentium.v1.GetBlockScalarsRequest
-	(*GetBlockScalarsResponse)(nil),   // 3: silentium.v1.GetBlockScalarsResponse
-	(*GetChainTipHeightRequest)(nil),  // 4: silentium.v1.GetChainTipHeightRequest
-	(*GetChainTipHeightResponse)(nil), // 5: silentium.v1.GetChainTipHeightResponse
+func (x *GetBlockScalarsRangeRequest) Reset() {
+	*x = GetBlockScalarsRangeRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
 }
-var file_silentium_v1_silentium_proto_depIdxs = []int32{
-	2, // 0: silentium.v1.SilentiumService.GetBlockScalars:input_type -> silentium.v1.GetBlockScalarsRequest
-	0, // 1: silentium.v1.SilentiumService.GetBlockFilter:input_type -> silentium.v1.GetBlockFilterRequest
-	4, // 2: silentium.v1.SilentiumService.GetChainTipHeight:input_type -> silentium.v1.GetChainTipHeightRequest
-	3, // 3: silentium.v1.SilentiumService.GetBlockScalars:output_type -> silentium.v1.GetBlockScalarsResponse
-	1, // 4: silentium.v1.SilentiumService.GetBlockFilter:output_type -> silentium.v1.GetBlockFilterResponse
-	5, // 5: silentium.v1.SilentiumService.GetChainTipHeight:output_type -> silentium.v1.GetChainTipHeightResponse
-	3, // [3:6] is the sub-list for method output_type
-	0, // [0:3] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+
+func (x *GetBlockScalarsRangeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-func init() { file_silentium_v1_silentium_proto_init() }
-func file_silentium_v1_silentium_proto_init() {
-	if File_silentium_v1_silentium_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_silentium_v1_silentium_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockFilterRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_silentium_v1_silentium_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockFilterResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
-		}
-		file_silentium_v1_silentium_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockScalarsRequest); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
-			default:
-				return nil
-			}
+func (*GetBlockScalarsRangeRequest) ProtoMessage() {}
+
+func (x *GetBlockScalarsRangeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
 		}
-		file_silentium_v1_silentium_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetBlockScalarsResponse); i {
-			case 0:
-				return &v.state
-			case 1:
-				return &v.sizeCache
-			case 2:
-				return &v.unknownFields
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockScalarsRangeRequest.ProtoReflect.Descriptor instead.
+func (*GetBlockScalarsRangeRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetBlockScalarsRangeRequest) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *GetBlockScalarsRangeRequest) GetTo() uint32 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *GetBlockScalarsRangeRequest) GetLimit() uint32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetBlockScalarsRangeRequest) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+func (x *GetBlockScalarsRangeRequest) GetDustLimit() uint64 {
+	if x != nil {
+		return x.DustLimit
+	}
+	return 0
+}
+
+type BlockScalars struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Height    uint32   `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Blockhash string   `protobuf:"bytes,2,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+	Scalars   []string `protobuf:"bytes,3,rep,name=scalars,proto3" json:"scalars,omitempty"`
+}
+
+func (x *BlockScalars) Reset() {
+	*x = BlockScalars{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockScalars) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockScalars) ProtoMessage() {}
+
+func (x *BlockScalars) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockScalars.ProtoReflect.Descriptor instead.
+func (*BlockScalars) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *BlockScalars) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BlockScalars) GetBlockhash() string {
+	if x != nil {
+		return x.Blockhash
+	}
+	return ""
+}
+
+func (x *BlockScalars) GetScalars() []string {
+	if x != nil {
+		return x.Scalars
+	}
+	return nil
+}
+
+type GetBlockScalarsRangeResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Blocks []*BlockScalars `protobuf:"bytes,1,rep,name=blocks,proto3" json:"blocks,omitempty"`
+	// height to request the next page from, 0 if the range is complete.
+	Next uint32 `protobuf:"varint,2,opt,name=next,proto3" json:"next,omitempty"`
+}
+
+func (x *GetBlockScalarsRangeResponse) Reset() {
+	*x = GetBlockScalarsRangeResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetBlockScalarsRangeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlockScalarsRangeResponse) ProtoMessage() {}
+
+func (x *GetBlockScalarsRangeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlockScalarsRangeResponse.ProtoReflect.Descriptor instead.
+func (*GetBlockScalarsRangeResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetBlockScalarsRangeResponse) GetBlocks() []*BlockScalars {
+	if x != nil {
+		return x.Blocks
+	}
+	return nil
+}
+
+func (x *GetBlockScalarsRangeResponse) GetNext() uint32 {
+	if x != nil {
+		return x.Next
+	}
+	return 0
+}
+
+type GetMempoolScalarsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetMempoolScalarsRequest) Reset() {
+	*x = GetMempoolScalarsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetMempoolScalarsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMempoolScalarsRequest) ProtoMessage() {}
+
+func (x *GetMempoolScalarsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMempoolScalarsRequest.ProtoReflect.Descriptor instead.
+func (*GetMempoolScalarsRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{11}
+}
+
+type GetMempoolScalarsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Scalars []string `protobuf:"bytes,1,rep,name=scalars,proto3" json:"scalars,omitempty"`
+}
+
+func (x *GetMempoolScalarsResponse) Reset() {
+	*x = GetMempoolScalarsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetMempoolScalarsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMempoolScalarsResponse) ProtoMessage() {}
+
+func (x *GetMempoolScalarsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMempoolScalarsResponse.ProtoReflect.Descriptor instead.
+func (*GetMempoolScalarsResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *GetMempoolScalarsResponse) GetScalars() []string {
+	if x != nil {
+		return x.Scalars
+	}
+	return nil
+}
+
+type GetChainTipHeightRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	BlockId uint32 `protobuf:"varint,1,opt,name=block_id,json=blockId,proto3" json:"block_id,omitempty"`
+}
+
+func (x *GetChainTipHeightRequest) Reset() {
+	*x = GetChainTipHeightRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetChainTipHeightRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChainTipHeightRequest) ProtoMessage() {}
+
+func (x *GetChainTipHeightRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChainTipHeightRequest.ProtoReflect.Descriptor instead.
+func (*GetChainTipHeightRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetChainTipHeightRequest) GetBlockId() uint32 {
+	if x != nil {
+		return x.BlockId
+	}
+	return 0
+}
+
+type GetChainTipHeightResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Height uint32 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (x *GetChainTipHeightResponse) Reset() {
+	*x = GetChainTipHeightResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetChainTipHeightResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChainTipHeightResponse) ProtoMessage() {}
+
+func (x *GetChainTipHeightResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChainTipHeightResponse.ProtoReflect.Descriptor instead.
+func (*GetChainTipHeightResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *GetChainTipHeightResponse) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type SubscribeScalarsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// height to replay the indexed blocks from, 0 to only receive the new blocks.
+	From uint32 `protobuf:"varint,1,opt,name=from,proto3" json:"from,omitempty"`
+}
+
+func (x *SubscribeScalarsRequest) Reset() {
+	*x = SubscribeScalarsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeScalarsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeScalarsRequest) ProtoMessage() {}
+
+func (x *SubscribeScalarsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeScalarsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeScalarsRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SubscribeScalarsRequest) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+type SubscribeScalarsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*SubscribeScalarsResponse_Block
+	//	*SubscribeScalarsResponse_Reorg
+	Event isSubscribeScalarsResponse_Event `protobuf_oneof:"event"`
+}
+
+func (x *SubscribeScalarsResponse) Reset() {
+	*x = SubscribeScalarsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeScalarsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeScalarsResponse) ProtoMessage() {}
+
+func (x *SubscribeScalarsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeScalarsResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeScalarsResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{16}
+}
+
+func (m *SubscribeScalarsResponse) GetEvent() isSubscribeScalarsResponse_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *SubscribeScalarsResponse) GetBlock() *BlockScalarsEvent {
+	if x, ok := x.GetEvent().(*SubscribeScalarsResponse_Block); ok {
+		return x.Block
+	}
+	return nil
+}
+
+func (x *SubscribeScalarsResponse) GetReorg() *ReorgEvent {
+	if x, ok := x.GetEvent().(*SubscribeScalarsResponse_Reorg); ok {
+		return x.Reorg
+	}
+	return nil
+}
+
+type isSubscribeScalarsResponse_Event interface {
+	isSubscribeScalarsResponse_Event()
+}
+
+type SubscribeScalarsResponse_Block struct {
+	Block *BlockScalarsEvent `protobuf:"bytes,1,opt,name=block,proto3,oneof"`
+}
+
+type SubscribeScalarsResponse_Reorg struct {
+	Reorg *ReorgEvent `protobuf:"bytes,2,opt,name=reorg,proto3,oneof"`
+}
+
+func (*SubscribeScalarsResponse_Block) isSubscribeScalarsResponse_Event() {}
+
+func (*SubscribeScalarsResponse_Reorg) isSubscribeScalarsResponse_Event() {}
+
+type BlockScalarsEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Height    uint32   `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Blockhash string   `protobuf:"bytes,2,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+	Scalars   []string `protobuf:"bytes,3,rep,name=scalars,proto3" json:"scalars,omitempty"`
+	// hex-encoded taproot filter of the block, see GetBlockTaprootFilterResponse. empty for the replayed blocks indexed before the taproot filters were stored.
+	Filter string `protobuf:"bytes,4,opt,name=filter,proto3" json:"filter,omitempty"`
+}
+
+func (x *BlockScalarsEvent) Reset() {
+	*x = BlockScalarsEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BlockScalarsEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BlockScalarsEvent) ProtoMessage() {}
+
+func (x *BlockScalarsEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BlockScalarsEvent.ProtoReflect.Descriptor instead.
+func (*BlockScalarsEvent) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *BlockScalarsEvent) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *BlockScalarsEvent) GetBlockhash() string {
+	if x != nil {
+		return x.Blockhash
+	}
+	return ""
+}
+
+func (x *BlockScalarsEvent) GetScalars() []string {
+	if x != nil {
+		return x.Scalars
+	}
+	return nil
+}
+
+func (x *BlockScalarsEvent) GetFilter() string {
+	if x != nil {
+		return x.Filter
+	}
+	return ""
+}
+
+// ReorgEvent notifies that the blocks above fork_height are orphaned.
+type ReorgEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	ForkHeight uint32 `protobuf:"varint,1,opt,name=fork_height,json=forkHeight,proto3" json:"fork_height,omitempty"`
+}
+
+func (x *ReorgEvent) Reset() {
+	*x = ReorgEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ReorgEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReorgEvent) ProtoMessage() {}
+
+func (x *ReorgEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReorgEvent.ProtoReflect.Descriptor instead.
+func (*ReorgEvent) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ReorgEvent) GetForkHeight() uint32 {
+	if x != nil {
+		return x.ForkHeight
+	}
+	return 0
+}
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// hex-encoded scan private key.
+	ScanPrivateKey string `protobuf:"bytes,1,opt,name=scan_private_key,json=scanPrivateKey,proto3" json:"scan_private_key,omitempty"`
+	// hex-encoded compressed spend public key.
+	SpendPublicKey string `protobuf:"bytes,2,opt,name=spend_public_key,json=spendPublicKey,proto3" json:"spend_public_key,omitempty"`
+	// m values of the labeled addresses to scan, 0 being the change label.
+	Labels []uint32 `protobuf:"varint,3,rep,packed,name=labels,proto3" json:"labels,omitempty"`
+	// height to scan the indexed blocks from, 0 to only scan the new blocks.
+	Birthday uint32 `protobuf:"varint,4,opt,name=birthday,proto3" json:"birthday,omitempty"`
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RegisterRequest) GetScanPrivateKey() string {
+	if x != nil {
+		return x.ScanPrivateKey
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetSpendPublicKey() string {
+	if x != nil {
+		return x.SpendPublicKey
+	}
+	return ""
+}
+
+func (x *RegisterRequest) GetLabels() []uint32 {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *RegisterRequest) GetBirthday() uint32 {
+	if x != nil {
+		return x.Birthday
+	}
+	return 0
+}
+
+type RegisterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// id of the registration, required by the other methods.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// unix timestamp after which the registration expires and the keys are wiped.
+	ExpiresAt int64 `protobuf:"varint,2,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+}
+
+func (x *RegisterResponse) Reset() {
+	*x = RegisterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RegisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterResponse) ProtoMessage() {}
+
+func (x *RegisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterResponse.ProtoReflect.Descriptor instead.
+func (*RegisterResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RegisterResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *RegisterResponse) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+type UnregisterRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *UnregisterRequest) Reset() {
+	*x = UnregisterRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnregisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterRequest) ProtoMessage() {}
+
+func (x *UnregisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterRequest.ProtoReflect.Descriptor instead.
+func (*UnregisterRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *UnregisterRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type UnregisterResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *UnregisterResponse) Reset() {
+	*x = UnregisterResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UnregisterResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnregisterResponse) ProtoMessage() {}
+
+func (x *UnregisterResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnregisterResponse.ProtoReflect.Descriptor instead.
+func (*UnregisterResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{22}
+}
+
+type GetScanOutputsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// height to return the outputs found from.
+	From uint32 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+}
+
+func (x *GetScanOutputsRequest) Reset() {
+	*x = GetScanOutputsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetScanOutputsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScanOutputsRequest) ProtoMessage() {}
+
+func (x *GetScanOutputsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScanOutputsRequest.ProtoReflect.Descriptor instead.
+func (*GetScanOutputsRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetScanOutputsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetScanOutputsRequest) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+type GetScanOutputsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Outputs []*ScanOutput `protobuf:"bytes,1,rep,name=outputs,proto3" json:"outputs,omitempty"`
+	// height of the last scanned block.
+	ScannedHeight uint32 `protobuf:"varint,2,opt,name=scanned_height,json=scannedHeight,proto3" json:"scanned_height,omitempty"`
+}
+
+func (x *GetScanOutputsResponse) Reset() {
+	*x = GetScanOutputsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetScanOutputsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetScanOutputsResponse) ProtoMessage() {}
+
+func (x *GetScanOutputsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetScanOutputsResponse.ProtoReflect.Descriptor instead.
+func (*GetScanOutputsResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GetScanOutputsResponse) GetOutputs() []*ScanOutput {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *GetScanOutputsResponse) GetScannedHeight() uint32 {
+	if x != nil {
+		return x.ScannedHeight
+	}
+	return 0
+}
+
+type ScanOutput struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Height    uint32 `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Blockhash string `protobuf:"bytes,2,opt,name=blockhash,proto3" json:"blockhash,omitempty"`
+	Txid      string `protobuf:"bytes,3,opt,name=txid,proto3" json:"txid,omitempty"`
+	Index     uint32 `protobuf:"varint,4,opt,name=index,proto3" json:"index,omitempty"`
+	// hex-encoded x-only output key.
+	Key    string `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
+	Amount uint64 `protobuf:"varint,6,opt,name=amount,proto3" json:"amount,omitempty"`
+	// hex-encoded tweak to add to the spend private key to spend the output.
+	Tweak string `protobuf:"bytes,7,opt,name=tweak,proto3" json:"tweak,omitempty"`
+	// label of the address receiving the output, unset if not labeled.
+	Label *uint32 `protobuf:"varint,8,opt,name=label,proto3,oneof" json:"label,omitempty"`
+}
+
+func (x *ScanOutput) Reset() {
+	*x = ScanOutput{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScanOutput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanOutput) ProtoMessage() {}
+
+func (x *ScanOutput) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanOutput.ProtoReflect.Descriptor instead.
+func (*ScanOutput) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *ScanOutput) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ScanOutput) GetBlockhash() string {
+	if x != nil {
+		return x.Blockhash
+	}
+	return ""
+}
+
+func (x *ScanOutput) GetTxid() string {
+	if x != nil {
+		return x.Txid
+	}
+	return ""
+}
+
+func (x *ScanOutput) GetIndex() uint32 {
+	if x != nil {
+		return x.Index
+	}
+	return 0
+}
+
+func (x *ScanOutput) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *ScanOutput) GetAmount() uint64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ScanOutput) GetTweak() string {
+	if x != nil {
+		return x.Tweak
+	}
+	return ""
+}
+
+func (x *ScanOutput) GetLabel() uint32 {
+	if x != nil && x.Label != nil {
+		return *x.Label
+	}
+	return 0
+}
+
+type SubscribeScanOutputsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// height to replay the outputs found from.
+	From uint32 `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`
+}
+
+func (x *SubscribeScanOutputsRequest) Reset() {
+	*x = SubscribeScanOutputsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeScanOutputsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeScanOutputsRequest) ProtoMessage() {}
+
+func (x *SubscribeScanOutputsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeScanOutputsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeScanOutputsRequest) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SubscribeScanOutputsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SubscribeScanOutputsRequest) GetFrom() uint32 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+type SubscribeScanOutputsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Types that are assignable to Event:
+	//	*SubscribeScanOutputsResponse_Outputs
+	//	*SubscribeScanOutputsResponse_Reorg
+	Event isSubscribeScanOutputsResponse_Event `protobuf_oneof:"event"`
+}
+
+func (x *SubscribeScanOutputsResponse) Reset() {
+	*x = SubscribeScanOutputsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SubscribeScanOutputsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeScanOutputsResponse) ProtoMessage() {}
+
+func (x *SubscribeScanOutputsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeScanOutputsResponse.ProtoReflect.Descriptor instead.
+func (*SubscribeScanOutputsResponse) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{27}
+}
+
+func (m *SubscribeScanOutputsResponse) GetEvent() isSubscribeScanOutputsResponse_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (x *SubscribeScanOutputsResponse) GetOutputs() *ScanOutputsEvent {
+	if x, ok := x.GetEvent().(*SubscribeScanOutputsResponse_Outputs); ok {
+		return x.Outputs
+	}
+	return nil
+}
+
+func (x *SubscribeScanOutputsResponse) GetReorg() *ReorgEvent {
+	if x, ok := x.GetEvent().(*SubscribeScanOutputsResponse_Reorg); ok {
+		return x.Reorg
+	}
+	return nil
+}
+
+type isSubscribeScanOutputsResponse_Event interface {
+	isSubscribeScanOutputsResponse_Event()
+}
+
+type SubscribeScanOutputsResponse_Outputs struct {
+	Outputs *ScanOutputsEvent `protobuf:"bytes,1,opt,name=outputs,proto3,oneof"`
+}
+
+type SubscribeScanOutputsResponse_Reorg struct {
+	Reorg *ReorgEvent `protobuf:"bytes,2,opt,name=reorg,proto3,oneof"`
+}
+
+func (*SubscribeScanOutputsResponse_Outputs) isSubscribeScanOutputsResponse_Event() {}
+
+func (*SubscribeScanOutputsResponse_Reorg) isSubscribeScanOutputsResponse_Event() {}
+
+// ScanOutputsEvent groups the outputs found in a block.
+type ScanOutputsEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Height  uint32        `protobuf:"varint,1,opt,name=height,proto3" json:"height,omitempty"`
+	Outputs []*ScanOutput `protobuf:"bytes,2,rep,name=outputs,proto3" json:"outputs,omitempty"`
+}
+
+func (x *ScanOutputsEvent) Reset() {
+	*x = ScanOutputsEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_silentium_v1_silentium_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScanOutputsEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScanOutputsEvent) ProtoMessage() {}
+
+func (x *ScanOutputsEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_silentium_v1_silentium_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScanOutputsEvent.ProtoReflect.Descriptor instead.
+func (*ScanOutputsEvent) Descriptor() ([]byte, []int) {
+	return file_silentium_v1_silentium_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ScanOutputsEvent) GetHeight() uint32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ScanOutputsEvent) GetOutputs() []*ScanOutput {
+	if x != nil {
+		return x.Outputs
+	}
+	return nil
+}
+
+var File_silentium_v1_silentium_proto protoreflect.FileDescriptor
+
+var file_silentium_v1_silentium_proto_rawDesc = []byte{
+	0x0a, 0x1c, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2f, 0x76, 0x31, 0x2f, 0x73,
+	0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x0c,
+	0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x1a, 0x1c, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x61, 0x6e, 0x6e, 0x6f, 0x74, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x32, 0x0a, 0x15, 0x47, 0x65,
+	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x22, 0x4e,
+	0x0a, 0x16, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x22, 0x39,
+	0x0a, 0x1c, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54, 0x61, 0x70, 0x72, 0x6f, 0x6f,
+	0x74, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19,
+	0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x22, 0x55, 0x0a, 0x1d, 0x47, 0x65, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54, 0x61, 0x70, 0x72, 0x6f, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x22, 0x84, 0x01, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61,
+	0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x49, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x62, 0x6f, 0x73,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x76, 0x65, 0x72, 0x62, 0x6f, 0x73, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x75, 0x73, 0x74,
+	0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x64, 0x75,
+	0x73, 0x74, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22, 0x6f, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x42, 0x6c,
+	0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x3a, 0x0a, 0x0c,
+	0x74, 0x72, 0x61, 0x6e, 0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x16, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76,
+	0x31, 0x2e, 0x54, 0x78, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x52, 0x0c, 0x74, 0x72, 0x61, 0x6e,
+	0x73, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x22, 0x6d, 0x0a, 0x08, 0x54, 0x78, 0x53, 0x63,
+	0x61, 0x6c, 0x61, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x78, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x78, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x63, 0x61, 0x6c,
+	0x61, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72,
+	0x12, 0x35, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x1b, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x61, 0x70, 0x72, 0x6f, 0x6f, 0x74, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x07,
+	0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x22, 0x65, 0x0a, 0x0d, 0x54, 0x61, 0x70, 0x72, 0x6f,
+	0x6f, 0x74, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65,
+	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79,
+	0x12, 0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x70, 0x65, 0x6e,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x73, 0x70, 0x65, 0x6e, 0x74, 0x22, 0x8e,
+	0x01, 0x0a, 0x1b, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61,
+	0x72, 0x73, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12,
+	0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x04, 0x66, 0x72,
+	0x6f, 0x6d, 0x12, 0x0e, 0x0a, 0x02, 0x74, 0x6f, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x02,
+	0x74, 0x6f, 0x12, 0x14, 0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74,
+	0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72,
+	0x12, 0x1d, 0x0a, 0x0a, 0x64, 0x75, 0x73, 0x74, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x09, 0x64, 0x75, 0x73, 0x74, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x22,
+	0x5e, 0x0a, 0x0c, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12,
+	0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b,
+	0x68, 0x61, 0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63,
+	0x6b, 0x68, 0x61, 0x73, 0x68, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x22,
+	0x66, 0x0a, 0x1c, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61,
+	0x72, 0x73, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x32, 0x0a, 0x06, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x06, 0x62, 0x6c, 0x6f,
+	0x63, 0x6b, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x04, 0x6e, 0x65, 0x78, 0x74, 0x22, 0x1a, 0x0a, 0x18, 0x47, 0x65, 0x74, 0x4d, 0x65,
+	0x6d, 0x70, 0x6f, 0x6f, 0x6c, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x22, 0x35, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x70, 0x6f, 0x6f,
+	0x6c, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x22, 0x35, 0x0a, 0x18, 0x47, 0x65,
+	0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x49,
+	0x64, 0x22, 0x33, 0x0a, 0x19, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x70,
+	0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06,
+	0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x2d, 0x0a, 0x17, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x22, 0x8e, 0x01, 0x0a, 0x18, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x37, 0x0a, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1f, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x45, 0x76, 0x65,
+	0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x12, 0x30, 0x0a, 0x05, 0x72,
+	0x65, 0x6f, 0x72, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x69, 0x6c,
+	0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6f, 0x72, 0x67, 0x45,
+	0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x72, 0x65, 0x6f, 0x72, 0x67, 0x42, 0x07, 0x0a,
+	0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x7b, 0x0a, 0x11, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53,
+	0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61, 0x73,
+	0x68, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x07, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x66,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x69, 0x6c,
+	0x74, 0x65, 0x72, 0x22, 0x2d, 0x0a, 0x0a, 0x52, 0x65, 0x6f, 0x72, 0x67, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x66, 0x6f, 0x72, 0x6b, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x66, 0x6f, 0x72, 0x6b, 0x48, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x22, 0x99, 0x01, 0x0a, 0x0f, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x28, 0x0a, 0x10, 0x73, 0x63, 0x61, 0x6e, 0x5f, 0x70,
+	0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0e, 0x73, 0x63, 0x61, 0x6e, 0x50, 0x72, 0x69, 0x76, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79,
+	0x12, 0x28, 0x0a, 0x10, 0x73, 0x70, 0x65, 0x6e, 0x64, 0x5f, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63,
+	0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x73, 0x70, 0x65, 0x6e,
+	0x64, 0x50, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x61,
+	0x62, 0x65, 0x6c, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0d, 0x52, 0x06, 0x6c, 0x61, 0x62, 0x65,
+	0x6c, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x62, 0x69, 0x72, 0x74, 0x68, 0x64, 0x61, 0x79, 0x22, 0x41,
+	0x0a, 0x10, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41,
+	0x74, 0x22, 0x23, 0x0a, 0x11, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69,
+	0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x3b, 0x0a, 0x15,
+	0x47, 0x65, 0x74, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0d, 0x52, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x22, 0x73, 0x0a, 0x16, 0x47, 0x65, 0x74,
+	0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x52, 0x07,
+	0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x73, 0x63, 0x61, 0x6e, 0x6e,
+	0x65, 0x64, 0x5f, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x0d, 0x73, 0x63, 0x61, 0x6e, 0x6e, 0x65, 0x64, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0xd1,
+	0x01, 0x0a, 0x0a, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x06, 0x68,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x1c, 0x0a, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68, 0x61,
+	0x73, 0x68, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x68,
+	0x61, 0x73, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x78, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x04, 0x74, 0x78, 0x69, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x69, 0x6e, 0x64, 0x65, 0x78, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x61, 0x6d, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x77, 0x65, 0x61, 0x6b,
+	0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x77, 0x65, 0x61, 0x6b, 0x12, 0x19, 0x0a,
+	0x05, 0x6c, 0x61, 0x62, 0x65, 0x6c, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x48, 0x00, 0x52, 0x05,
+	0x6c, 0x61, 0x62, 0x65, 0x6c, 0x88, 0x01, 0x01, 0x42, 0x08, 0x0a, 0x06, 0x5f, 0x6c, 0x61, 0x62,
+	0x65, 0x6c, 0x22, 0x41, 0x0a, 0x1b, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x53,
+	0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x66, 0x72, 0x6f, 0x6d, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d, 0x52,
+	0x04, 0x66, 0x72, 0x6f, 0x6d, 0x22, 0x95, 0x01, 0x0a, 0x1c, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72,
+	0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3a, 0x0a, 0x07, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74,
+	0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x73, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x70, 0x75,
+	0x74, 0x73, 0x12, 0x30, 0x0a, 0x05, 0x72, 0x65, 0x6f, 0x72, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x18, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x52, 0x65, 0x6f, 0x72, 0x67, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x48, 0x00, 0x52, 0x05, 0x72,
+	0x65, 0x6f, 0x72, 0x67, 0x42, 0x07, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x22, 0x5e, 0x0a,
+	0x10, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x45, 0x76, 0x65, 0x6e,
+	0x74, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0d, 0x52, 0x06, 0x68, 0x65, 0x69, 0x67, 0x68, 0x74, 0x12, 0x32, 0x0a, 0x07, 0x6f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x73, 0x69, 0x6c,
+	0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x52, 0x07, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x32, 0xba, 0x07,
+	0x0a, 0x10, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x53, 0x65, 0x72, 0x76, 0x69,
+	0x63, 0x65, 0x12, 0x84, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53,
+	0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x24, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69,
+	0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63,
+	0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x73,
+	0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42,
+	0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x24, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1e, 0x12, 0x1c, 0x2f, 0x76, 0x31,
+	0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64,
+	0x7d, 0x2f, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x95, 0x01, 0x0a, 0x14, 0x47, 0x65,
+	0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x61, 0x6e,
+	0x67, 0x65, 0x12, 0x29, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72,
+	0x73, 0x52, 0x61, 0x6e, 0x67, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e,
+	0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x61, 0x6e, 0x67,
+	0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x26, 0x82, 0xd3, 0xe4, 0x93, 0x02,
+	0x20, 0x12, 0x1e, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x2f, 0x7b, 0x66,
+	0x72, 0x6f, 0x6d, 0x7d, 0x2f, 0x7b, 0x74, 0x6f, 0x7d, 0x2f, 0x73, 0x63, 0x61, 0x6c, 0x61, 0x72,
+	0x73, 0x12, 0x81, 0x01, 0x0a, 0x11, 0x47, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x70, 0x6f, 0x6f, 0x6c,
+	0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x26, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74,
+	0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4d, 0x65, 0x6d, 0x70, 0x6f, 0x6f,
+	0x6c, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x27, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x4d, 0x65, 0x6d, 0x70, 0x6f, 0x6f, 0x6c, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15,
+	0x12, 0x13, 0x2f, 0x76, 0x31, 0x2f, 0x6d, 0x65, 0x6d, 0x70, 0x6f, 0x6f, 0x6c, 0x2f, 0x73, 0x63,
+	0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x80, 0x01, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f,
+	0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x23, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e,
+	0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b,
+	0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e,
+	0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x23, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x1d, 0x12, 0x1b, 0x2f, 0x76, 0x31,
+	0x2f, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64,
+	0x7d, 0x2f, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x63, 0x0a, 0x10, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x12, 0x25, 0x2e, 0x73,
+	0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73,
+	0x63, 0x72, 0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6c, 0x61, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6c,
+	0x61, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x12, 0x9d, 0x01,
+	0x0a, 0x15, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54, 0x61, 0x70, 0x72, 0x6f, 0x6f,
+	0x74, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x2a, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74,
+	0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54,
+	0x61, 0x70, 0x72, 0x6f, 0x6f, 0x74, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x2b, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e,
+	0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54, 0x61, 0x70, 0x72, 0x6f,
+	0x6f, 0x74, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x2b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x25, 0x12, 0x23, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x6c,
+	0x6f, 0x63, 0x6b, 0x2f, 0x7b, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x69, 0x64, 0x7d, 0x2f, 0x66,
+	0x69, 0x6c, 0x74, 0x65, 0x72, 0x2f, 0x74, 0x61, 0x70, 0x72, 0x6f, 0x6f, 0x74, 0x12, 0x7b, 0x0a,
+	0x11, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x70, 0x48, 0x65, 0x69, 0x67,
+	0x68, 0x74, 0x12, 0x26, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61, 0x69, 0x6e, 0x54, 0x69, 0x70, 0x48, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x73, 0x69, 0x6c,
+	0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x68, 0x61,
+	0x69, 0x6e, 0x54, 0x69, 0x70, 0x48, 0x65, 0x69, 0x67, 0x68, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x22, 0x15, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x0f, 0x12, 0x0d, 0x2f, 0x76, 0x31,
+	0x2f, 0x63, 0x68, 0x61, 0x69, 0x6e, 0x2f, 0x74, 0x69, 0x70, 0x32, 0xd2, 0x03, 0x0a, 0x0b, 0x53,
+	0x63, 0x61, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x67, 0x0a, 0x08, 0x52, 0x65,
+	0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x12, 0x1d, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69,
+	0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75,
+	0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1c, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x16, 0x22, 0x11, 0x2f,
+	0x76, 0x31, 0x2f, 0x73, 0x63, 0x61, 0x6e, 0x2f, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72,
+	0x3a, 0x01, 0x2a, 0x12, 0x6f, 0x0a, 0x0a, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65,
+	0x72, 0x12, 0x1f, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x20, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76,
+	0x31, 0x2e, 0x55, 0x6e, 0x72, 0x65, 0x67, 0x69, 0x73, 0x74, 0x65, 0x72, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x1e, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x18, 0x3a, 0x01, 0x2a, 0x22,
+	0x13, 0x2f, 0x76, 0x31, 0x2f, 0x73, 0x63, 0x61, 0x6e, 0x2f, 0x75, 0x6e, 0x72, 0x65, 0x67, 0x69,
+	0x73, 0x74, 0x65, 0x72, 0x12, 0x78, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x53, 0x63, 0x61, 0x6e, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x12, 0x23, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69,
+	0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74,
+	0x70, 0x75, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x73, 0x69,
+	0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x63,
+	0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x1b, 0x82, 0xd3, 0xe4, 0x93, 0x02, 0x15, 0x22, 0x10, 0x2f, 0x76, 0x31, 0x2f, 0x73,
+	0x63, 0x61, 0x6e, 0x2f, 0x6f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x3a, 0x01, 0x2a, 0x12, 0x6f,
+	0x0a, 0x14, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6e, 0x4f,
+	0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x12, 0x29, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69,
+	0x75, 0x6d, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x53,
+	0x63, 0x61, 0x6e, 0x4f, 0x75, 0x74, 0x70, 0x75, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x2a, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x2e, 0x76, 0x31,
+	0x2e, 0x53, 0x75, 0x62, 0x73, 0x63, 0x72, 0x69, 0x62, 0x65, 0x53, 0x63, 0x61, 0x6e, 0x4f, 0x75,
+	0x74, 0x70, 0x75, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x30, 0x01, 0x42,
+	0xbf, 0x01, 0x0a, 0x10, 0x63, 0x6f, 0x6d, 0x2e, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75,
+	0x6d, 0x2e, 0x76, 0x31, 0x42, 0x0e, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x50,
+	0x72, 0x6f, 0x74, 0x6f, 0x50, 0x01, 0x5a, 0x4a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x6c, 0x6f, 0x75, 0x69, 0x73, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x2f, 0x73, 0x69,
+	0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x64, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74,
+	0x69, 0x75, 0x6d, 0x2f, 0x76, 0x31, 0x3b, 0x73, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d,
+	0x76, 0x31, 0xa2, 0x02, 0x03, 0x53, 0x58, 0x58, 0xaa, 0x02, 0x0c, 0x53, 0x69, 0x6c, 0x65, 0x6e,
+	0x74, 0x69, 0x75, 0x6d, 0x2e, 0x56, 0x31, 0xca, 0x02, 0x0c, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74,
+	0x69, 0x75, 0x6d, 0x5c, 0x56, 0x31, 0xe2, 0x02, 0x18, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69,
+	0x75, 0x6d, 0x5c, 0x56, 0x31, 0x5c, 0x47, 0x50, 0x42, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0xea, 0x02, 0x0d, 0x53, 0x69, 0x6c, 0x65, 0x6e, 0x74, 0x69, 0x75, 0x6d, 0x3a, 0x3a, 0x56,
+	0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_silentium_v1_silentium_proto_rawDescOnce sync.Once
+	file_silentium_v1_silentium_proto_rawDescData = file_silentium_v1_silentium_proto_rawDesc
+)
+
+func file_silentium_v1_silentium_proto_rawDescGZIP() []byte {
+	file_silentium_v1_silentium_proto_rawDescOnce.Do(func() {
+		file_silentium_v1_silentium_proto_rawDescData = protoimpl.X.CompressGZIP(file_silentium_v1_silentium_proto_rawDescData)
+	})
+	return file_silentium_v1_silentium_proto_rawDescData
+}
+
+var file_silentium_v1_silentium_proto_msgTypes = make([]protoimpl.MessageInfo, 29)
+var file_silentium_v1_silentium_proto_goTypes = []interface{}{
+	(*GetBlockFilterRequest)(nil),         // 0: silentium.v1.GetBlockFilterRequest
+	(*GetBlockFilterResponse)(nil),        // 1: silentium.v1.GetBlockFilterResponse
+	(*GetBlockTaprootFilterRequest)(nil),  // 2: silentium.v1.GetBlockTaprootFilterRequest
+	(*GetBlockTaprootFilterResponse)(nil), // 3: silentium.v1.GetBlockTaprootFilterResponse
+	(*GetBlockScalarsRequest)(nil),        // 4: silentium.v1.GetBlockScalarsRequest
+	(*GetBlockScalarsResponse)(nil),       // 5: silentium.v1.GetBlockScalarsResponse
+	(*TxScalar)(nil),                      // 6: silentium.v1.TxScalar
+	(*TaprootOutput)(nil),                 // 7: silentium.v1.TaprootOutput
+	(*GetBlockScalarsRangeRequest)(nil),   // 8: silentium.v1.GetBlockScalarsRangeRequest
+	(*BlockScalars)(nil),                  // 9: silentium.v1.BlockScalars
+	(*GetBlockScalarsRangeResponse)(nil),  // 10: silentium.v1.GetBlockScalarsRangeResponse
+	(*GetMempoolScalarsRequest)(nil),      // 11: silentium.v1.GetMempoolScalarsRequest
+	(*GetMempoolScalarsResponse)(nil),     // 12: silentium.v1.GetMempoolScalarsResponse
+	(*GetChainTipHeightRequest)(nil),      // 13: silentium.v1.GetChainTipHeightRequest
+	(*GetChainTipHeightResponse)(nil),     // 14: silentium.v1.GetChainTipHeightResponse
+	(*SubscribeScalarsRequest)(nil),       // 15: silentium.v1.SubscribeScalarsRequest
+	(*SubscribeScalarsResponse)(nil),      // 16: silentium.v1.SubscribeScalarsResponse
+	(*BlockScalarsEvent)(nil),             // 17: silentium.v1.BlockScalarsEvent
+	(*ReorgEvent)(nil),                    // 18: silentium.v1.ReorgEvent
+	(*RegisterRequest)(nil),               // 19: silentium.v1.RegisterRequest
+	(*RegisterResponse)(nil),              // 20: silentium.v1.RegisterResponse
+	(*UnregisterRequest)(nil),             // 21: silentium.v1.UnregisterRequest
+	(*UnregisterResponse)(nil),            // 22: silentium.v1.UnregisterResponse
+	(*GetScanOutputsRequest)(nil),         // 23: silentium.v1.GetScanOutputsRequest
+	(*GetScanOutputsResponse)(nil),        // 24: silentium.v1.GetScanOutputsResponse
+	(*ScanOutput)(nil),                    // 25: silentium.v1.ScanOutput
+	(*SubscribeScanOutputsRequest)(nil),   // 26: silentium.v1.SubscribeScanOutputsRequest
+	(*SubscribeScanOutputsResponse)(nil),  // 27: silentium.v1.SubscribeScanOutputsResponse
+	(*ScanOutputsEvent)(nil),              // 28: silentium.v1.ScanOutputsEvent
+}
+var file_silentium_v1_silentium_proto_depIdxs = []int32{
+	6,  // 0: silentium.v1.GetBlockScalarsResponse.transactions:type_name -> silentium.v1.TxScalar
+	7,  // 1: silentium.v1.TxScalar.outputs:type_name -> silentium.v1.TaprootOutput
+	9,  // 2: silentium.v1.GetBlockScalarsRangeResponse.blocks:type_name -> silentium.v1.BlockScalars
+	17, // 3: silentium.v1.SubscribeScalarsResponse.block:type_name -> silentium.v1.BlockScalarsEvent
+	18, // 4: silentium.v1.SubscribeScalarsResponse.reorg:type_name -> silentium.v1.ReorgEvent
+	25, // 5: silentium.v1.GetScanOutputsResponse.outputs:type_name -> silentium.v1.ScanOutput
+	28, // 6: silentium.v1.SubscribeScanOutputsResponse.outputs:type_name -> silentium.v1.ScanOutputsEvent
+	18, // 7: silentium.v1.SubscribeScanOutputsResponse.reorg:type_name -> silentium.v1.ReorgEvent
+	25, // 8: silentium.v1.ScanOutputsEvent.outputs:type_name -> silentium.v1.ScanOutput
+	4,  // 9: silentium.v1.SilentiumService.GetBlockScalars:input_type -> silentium.v1.GetBlockScalarsRequest
+	8,  // 10: silentium.v1.SilentiumService.GetBlockScalarsRange:input_type -> silentium.v1.GetBlockScalarsRangeRequest
+	11, // 11: silentium.v1.SilentiumService.GetMempoolScalars:input_type -> silentium.v1.GetMempoolScalarsRequest
+	0,  // 12: silentium.v1.SilentiumService.GetBlockFilter:input_type -> silentium.v1.GetBlockFilterRequest
+	15, // 13: silentium.v1.SilentiumService.SubscribeScalars:input_type -> silentium.v1.SubscribeScalarsRequest
+	2,  // 14: silentium.v1.SilentiumService.GetBlockTaprootFilter:input_type -> silentium.v1.GetBlockTaprootFilterRequest
+	13, // 15: silentium.v1.SilentiumService.GetChainTipHeight:input_type -> silentium.v1.GetChainTipHeightRequest
+	19, // 16: silentium.v1.ScanService.Register:input_type -> silentium.v1.RegisterRequest
+	21, // 17: silentium.v1.ScanService.Unregister:input_type -> silentium.v1.UnregisterRequest
+	23, // 18: silentium.v1.ScanService.GetScanOutputs:input_type -> silentium.v1.GetScanOutputsRequest
+	26, // 19: silentium.v1.ScanService.SubscribeScanOutputs:input_type -> silentium.v1.SubscribeScanOutputsRequest
+	5,  // 20: silentium.v1.SilentiumService.GetBlockScalars:output_type -> silentium.v1.GetBlockScalarsResponse
+	10, // 21: silentium.v1.SilentiumService.GetBlockScalarsRange:output_type -> silentium.v1.GetBlockScalarsRangeResponse
+	12, // 22: silentium.v1.SilentiumService.GetMempoolScalars:output_type -> silentium.v1.GetMempoolScalarsResponse
+	1,  // 23: silentium.v1.SilentiumService.GetBlockFilter:output_type -> silentium.v1.GetBlockFilterResponse
+	16, // 24: silentium.v1.SilentiumService.SubscribeScalars:output_type -> silentium.v1.SubscribeScalarsResponse
+	3,  // 25: silentium.v1.SilentiumService.GetBlockTaprootFilter:output_type -> silentium.v1.GetBlockTaprootFilterResponse
+	14, // 26: silentium.v1.SilentiumService.GetChainTipHeight:output_type -> silentium.v1.GetChainTipHeightResponse
+	20, // 27: silentium.v1.ScanService.Register:output_type -> silentium.v1.RegisterResponse
+	22, // 28: silentium.v1.ScanService.Unregister:output_type -> silentium.v1.UnregisterResponse
+	24, // 29: silentium.v1.ScanService.GetScanOutputs:output_type -> silentium.v1.GetScanOutputsResponse
+	27, // 30: silentium.v1.ScanService.SubscribeScanOutputs:output_type -> silentium.v1.SubscribeScanOutputsResponse
+	20, // [20:31] is the sub-list for method output_type
+	9,  // [9:20] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_silentium_v1_silentium_proto_init() }
+func file_silentium_v1_silentium_proto_init() {
+	if File_silentium_v1_silentium_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_silentium_v1_silentium_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBlockFilterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBlockFilterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBlockTaprootFilterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBlockTaprootFilterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
 			default:
 				return nil
 			}
 		}
 		file_silentium_v1_silentium_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GetChainTipHeightRequest); i {
+			switch v := v.(*GetBlockScalarsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -483,6 +2173,114 @@ func file_silentium_v1_silentium_proto_init() {
 			}
 		}
 		file_silentium_v1_silentium_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBlockScalarsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TxScalar); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TaprootOutput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBlockScalarsRangeRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlockScalars); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetBlockScalarsRangeResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMempoolScalarsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetMempoolScalarsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetChainTipHeightRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*GetChainTipHeightResponse); i {
 			case 0:
 				return &v.state
@@ -494,6 +2292,183 @@ func file_silentium_v1_silentium_proto_init() {
 				return nil
 			}
 		}
+		file_silentium_v1_silentium_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeScalarsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeScalarsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BlockScalarsEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ReorgEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RegisterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnregisterRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UnregisterResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetScanOutputsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetScanOutputsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScanOutput); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeScanOutputsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SubscribeScanOutputsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_silentium_v1_silentium_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScanOutputsEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_silentium_v1_silentium_proto_msgTypes[16].OneofWrappers = []interface{}{
+		(*SubscribeScalarsResponse_Block)(nil),
+		(*SubscribeScalarsResponse_Reorg)(nil),
+	}
+	file_silentium_v1_silentium_proto_msgTypes[25].OneofWrappers = []interface{}{}
+	file_silentium_v1_silentium_proto_msgTypes[27].OneofWrappers = []interface{}{
+		(*SubscribeScanOutputsResponse_Outputs)(nil),
+		(*SubscribeScanOutputsResponse_Reorg)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -501,9 +2476,9 @@ func file_silentium_v1_silentium_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_silentium_v1_silentium_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   6,
+			NumMessages:   29,
 			NumExtensions: 0,
-			NumServices:   1,
+			NumServices:   2,
 		},
 		GoTypes:           file_silentium_v1_silentium_proto_goTypes,
 		DependencyIndexes: file_silentium_v1_silentium_proto_depIdxs,
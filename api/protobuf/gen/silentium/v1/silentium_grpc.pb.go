@@ -19,7 +19,11 @@ const _ = grpc.SupportPackageIsVersion7
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SilentiumServiceClient interface {
 	GetBlockScalars(ctx context.Context, in *GetBlockScalarsRequest, opts ...grpc.CallOption) (*GetBlockScalarsResponse, error)
+	GetBlockScalarsRange(ctx context.Context, in *GetBlockScalarsRangeRequest, opts ...grpc.CallOption) (*GetBlockScalarsRangeResponse, error)
+	GetMempoolScalars(ctx context.Context, in *GetMempoolScalarsRequest, opts ...grpc.CallOption) (*GetMempoolScalarsResponse, error)
 	GetBlockFilter(ctx context.Context, in *GetBlockFilterRequest, opts ...grpc.CallOption) (*GetBlockFilterResponse, error)
+	SubscribeScalars(ctx context.Context, in *SubscribeScalarsRequest, opts ...grpc.CallOption) (SilentiumService_SubscribeScalarsClient, error)
+	GetBlockTaprootFilter(ctx context.Context, in *GetBlockTaprootFilterRequest, opts ...grpc.CallOption) (*GetBlockTaprootFilterResponse, error)
 	GetChainTipHeight(ctx context.Context, in *GetChainTipHeightRequest, opts ...grpc.CallOption) (*GetChainTipHeightResponse, error)
 }
 
@@ -40,6 +44,24 @@ func (c *silentiumServiceClient) GetBlockScalars(ctx context.Context, in *GetBlo
 	return out, nil
 }
 
+func (c *silentiumServiceClient) GetBlockScalarsRange(ctx context.Context, in *GetBlockScalarsRangeRequest, opts ...grpc.CallOption) (*GetBlockScalarsRangeResponse, error) {
+	out := new(GetBlockScalarsRangeResponse)
+	err := c.cc.Invoke(ctx, "/silentium.v1.SilentiumService/GetBlockScalarsRange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *silentiumServiceClient) GetMempoolScalars(ctx context.Context, in *GetMempoolScalarsRequest, opts ...grpc.CallOption) (*GetMempoolScalarsResponse, error) {
+	out := new(GetMempoolScalarsResponse)
+	err := c.cc.Invoke(ctx, "/silentium.v1.SilentiumService/GetMempoolScalars", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *silentiumServiceClient) GetBlockFilter(ctx context.Context, in *GetBlockFilterRequest, opts ...grpc.CallOption) (*GetBlockFilterResponse, error) {
 	out := new(GetBlockFilterResponse)
 	err := c.cc.Invoke(ctx, "/silentium.v1.SilentiumService/GetBlockFilter", in, out, opts...)
@@ -49,6 +71,47 @@ func (c *silentiumServiceClient) GetBlockFilter(ctx context.Context, in *GetBloc
 	return out, nil
 }
 
+func (c *silentiumServiceClient) SubscribeScalars(ctx context.Context, in *SubscribeScalarsRequest, opts ...grpc.CallOption) (SilentiumService_SubscribeScalarsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SilentiumService_ServiceDesc.Streams[0], "/silentium.v1.SilentiumService/SubscribeScalars", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &silentiumServiceSubscribeScalarsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SilentiumService_SubscribeScalarsClient interface {
+	Recv() (*SubscribeScalarsResponse, error)
+	grpc.ClientStream
+}
+
+type silentiumServiceSubscribeScalarsClient struct {
+	grpc.ClientStream
+}
+
+func (x *silentiumServiceSubscribeScalarsClient) Recv() (*SubscribeScalarsResponse, error) {
+	m := new(SubscribeScalarsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *silentiumServiceClient) GetBlockTaprootFilter(ctx context.Context, in *GetBlockTaprootFilterRequest, opts ...grpc.CallOption) (*GetBlockTaprootFilterResponse, error) {
+	out := new(GetBlockTaprootFilterResponse)
+	err := c.cc.Invoke(ctx, "/silentium.v1.SilentiumService/GetBlockTaprootFilter", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *silentiumServiceClient) GetChainTipHeight(ctx context.Context, in *GetChainTipHeightRequest, opts ...grpc.CallOption) (*GetChainTipHeightResponse, error) {
 	out := new(GetChainTipHeightResponse)
 	err := c.cc.Invoke(ctx, "/silentium.v1.SilentiumService/GetChainTipHeight", in, out, opts...)
@@ -63,7 +126,11 @@ func (c *silentiumServiceClient) GetChainTipHeight(ctx context.Context, in *GetC
 // for forward compatibility
 type SilentiumServiceServer interface {
 	GetBlockScalars(context.Context, *GetBlockScalarsRequest) (*GetBlockScalarsResponse, error)
+	GetBlockScalarsRange(context.Context, *GetBlockScalarsRangeRequest) (*GetBlockScalarsRangeResponse, error)
+	GetMempoolScalars(context.Context, *GetMempoolScalarsRequest) (*GetMempoolScalarsResponse, error)
 	GetBlockFilter(context.Context, *GetBlockFilterRequest) (*GetBlockFilterResponse, error)
+	SubscribeScalars(*SubscribeScalarsRequest, SilentiumService_SubscribeScalarsServer) error
+	GetBlockTaprootFilter(context.Context, *GetBlockTaprootFilterRequest) (*GetBlockTaprootFilterResponse, error)
 	GetChainTipHeight(context.Context, *GetChainTipHeightRequest) (*GetChainTipHeightResponse, error)
 }
 
@@ -74,9 +141,21 @@ type UnimplementedSilentiumServiceServer struct {
 func (UnimplementedSilentiumServiceServer) GetBlockScalars(context.Context, *GetBlockScalarsRequest) (*GetBlockScalarsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlockScalars not implemented")
 }
+func (UnimplementedSilentiumServiceServer) GetBlockScalarsRange(context.Context, *GetBlockScalarsRangeRequest) (*GetBlockScalarsRangeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockScalarsRange not implemented")
+}
+func (UnimplementedSilentiumServiceServer) GetMempoolScalars(context.Context, *GetMempoolScalarsRequest) (*GetMempoolScalarsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMempoolScalars not implemented")
+}
 func (UnimplementedSilentiumServiceServer) GetBlockFilter(context.Context, *GetBlockFilterRequest) (*GetBlockFilterResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetBlockFilter not implemented")
 }
+func (UnimplementedSilentiumServiceServer) SubscribeScalars(*SubscribeScalarsRequest, SilentiumService_SubscribeScalarsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeScalars not implemented")
+}
+func (UnimplementedSilentiumServiceServer) GetBlockTaprootFilter(context.Context, *GetBlockTaprootFilterRequest) (*GetBlockTaprootFilterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockTaprootFilter not implemented")
+}
 func (UnimplementedSilentiumServiceServer) GetChainTipHeight(context.Context, *GetChainTipHeightRequest) (*GetChainTipHeightResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChainTipHeight not implemented")
 }
@@ -110,6 +189,42 @@ func _SilentiumService_GetBlockScalars_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SilentiumService_GetBlockScalarsRange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockScalarsRangeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SilentiumServiceServer).GetBlockScalarsRange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/silentium.v1.SilentiumService/GetBlockScalarsRange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SilentiumServiceServer).GetBlockScalarsRange(ctx, req.(*GetBlockScalarsRangeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SilentiumService_GetMempoolScalars_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMempoolScalarsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SilentiumServiceServer).GetMempoolScalars(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/silentium.v1.SilentiumService/GetMempoolScalars",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SilentiumServiceServer).GetMempoolScalars(ctx, req.(*GetMempoolScalarsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SilentiumService_GetBlockFilter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetBlockFilterRequest)
 	if err := dec(in); err != nil {
@@ -128,6 +243,45 @@ func _SilentiumService_GetBlockFilter_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SilentiumService_SubscribeScalars_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeScalarsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SilentiumServiceServer).SubscribeScalars(m, &silentiumServiceSubscribeScalarsServer{stream})
+}
+
+type SilentiumService_SubscribeScalarsServer interface {
+	Send(*SubscribeScalarsResponse) error
+	grpc.ServerStream
+}
+
+type silentiumServiceSubscribeScalarsServer struct {
+	grpc.ServerStream
+}
+
+func (x *silentiumServiceSubscribeScalarsServer) Send(m *SubscribeScalarsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SilentiumService_GetBlockTaprootFilter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlockTaprootFilterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SilentiumServiceServer).GetBlockTaprootFilter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/silentium.v1.SilentiumService/GetBlockTaprootFilter",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SilentiumServiceServer).GetBlockTaprootFilter(ctx, req.(*GetBlockTaprootFilterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SilentiumService_GetChainTipHeight_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetChainTipHeightRequest)
 	if err := dec(in); err != nil {
@@ -157,15 +311,253 @@ var SilentiumService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetBlockScalars",
 			Handler:    _SilentiumService_GetBlockScalars_Handler,
 		},
+		{
+			MethodName: "GetBlockScalarsRange",
+			Handler:    _SilentiumService_GetBlockScalarsRange_Handler,
+		},
+		{
+			MethodName: "GetMempoolScalars",
+			Handler:    _SilentiumService_GetMempoolScalars_Handler,
+		},
 		{
 			MethodName: "GetBlockFilter",
 			Handler:    _SilentiumService_GetBlockFilter_Handler,
 		},
+		{
+			MethodName: "GetBlockTaprootFilter",
+			Handler:    _SilentiumService_GetBlockTaprootFilter_Handler,
+		},
 		{
 			MethodName: "GetChainTipHeight",
 			Handler:    _SilentiumService_GetChainTipHeight_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeScalars",
+			Handler:       _SilentiumService_SubscribeScalars_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "silentium/v1/silentium.proto",
+}
+
+// ScanServiceClient is the client API for ScanService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ScanServiceClient interface {
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error)
+	Unregister(ctx context.Context, in *UnregisterRequest, opts ...grpc.CallOption) (*UnregisterResponse, error)
+	GetScanOutputs(ctx context.Context, in *GetScanOutputsRequest, opts ...grpc.CallOption) (*GetScanOutputsResponse, error)
+	SubscribeScanOutputs(ctx context.Context, in *SubscribeScanOutputsRequest, opts ...grpc.CallOption) (ScanService_SubscribeScanOutputsClient, error)
+}
+
+type scanServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScanServiceClient(cc grpc.ClientConnInterface) ScanServiceClient {
+	return &scanServiceClient{cc}
+}
+
+func (c *scanServiceClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*RegisterResponse, error) {
+	out := new(RegisterResponse)
+	err := c.cc.Invoke(ctx, "/silentium.v1.ScanService/Register", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scanServiceClient) Unregister(ctx context.Context, in *UnregisterRequest, opts ...grpc.CallOption) (*UnregisterResponse, error) {
+	out := new(UnregisterResponse)
+	err := c.cc.Invoke(ctx, "/silentium.v1.ScanService/Unregister", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scanServiceClient) GetScanOutputs(ctx context.Context, in *GetScanOutputsRequest, opts ...grpc.CallOption) (*GetScanOutputsResponse, error) {
+	out := new(GetScanOutputsResponse)
+	err := c.cc.Invoke(ctx, "/silentium.v1.ScanService/GetScanOutputs", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *scanServiceClient) SubscribeScanOutputs(ctx context.Context, in *SubscribeScanOutputsRequest, opts ...grpc.CallOption) (ScanService_SubscribeScanOutputsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &ScanService_ServiceDesc.Streams[0], "/silentium.v1.ScanService/SubscribeScanOutputs", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &scanServiceSubscribeScanOutputsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ScanService_SubscribeScanOutputsClient interface {
+	Recv() (*SubscribeScanOutputsResponse, error)
+	grpc.ClientStream
+}
+
+type scanServiceSubscribeScanOutputsClient struct {
+	grpc.ClientStream
+}
+
+func (x *scanServiceSubscribeScanOutputsClient) Recv() (*SubscribeScanOutputsResponse, error) {
+	m := new(SubscribeScanOutputsResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ScanServiceServer is the server API for ScanService service.
+// All implementations should embed UnimplementedScanServiceServer
+// for forward compatibility
+type ScanServiceServer interface {
+	Register(context.Context, *RegisterRequest) (*RegisterResponse, error)
+	Unregister(context.Context, *UnregisterRequest) (*UnregisterResponse, error)
+	GetScanOutputs(context.Context, *GetScanOutputsRequest) (*GetScanOutputsResponse, error)
+	SubscribeScanOutputs(*SubscribeScanOutputsRequest, ScanService_SubscribeScanOutputsServer) error
+}
+
+// UnimplementedScanServiceServer should be embedded to have forward compatible implementations.
+type UnimplementedScanServiceServer struct {
+}
+
+func (UnimplementedScanServiceServer) Register(context.Context, *RegisterRequest) (*RegisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedScanServiceServer) Unregister(context.Context, *UnregisterRequest) (*UnregisterResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unregister not implemented")
+}
+func (UnimplementedScanServiceServer) GetScanOutputs(context.Context, *GetScanOutputsRequest) (*GetScanOutputsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetScanOutputs not implemented")
+}
+func (UnimplementedScanServiceServer) SubscribeScanOutputs(*SubscribeScanOutputsRequest, ScanService_SubscribeScanOutputsServer) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribeScanOutputs not implemented")
+}
+
+// UnsafeScanServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScanServiceServer will
+// result in compilation errors.
+type UnsafeScanServiceServer interface {
+	mustEmbedUnimplementedScanServiceServer()
+}
+
+func RegisterScanServiceServer(s grpc.ServiceRegistrar, srv ScanServiceServer) {
+	s.RegisterService(&ScanService_ServiceDesc, srv)
+}
+
+func _ScanService_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScanServiceServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/silentium.v1.ScanService/Register",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScanServiceServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScanService_Unregister_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScanServiceServer).Unregister(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/silentium.v1.ScanService/Unregister",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScanServiceServer).Unregister(ctx, req.(*UnregisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScanService_GetScanOutputs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetScanOutputsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScanServiceServer).GetScanOutputs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/silentium.v1.ScanService/GetScanOutputs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScanServiceServer).GetScanOutputs(ctx, req.(*GetScanOutputsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ScanService_SubscribeScanOutputs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeScanOutputsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ScanServiceServer).SubscribeScanOutputs(m, &scanServiceSubscribeScanOutputsServer{stream})
+}
+
+type ScanService_SubscribeScanOutputsServer interface {
+	Send(*SubscribeScanOutputsResponse) error
+	grpc.ServerStream
+}
+
+type scanServiceSubscribeScanOutputsServer struct {
+	grpc.ServerStream
+}
+
+func (x *scanServiceSubscribeScanOutputsServer) Send(m *SubscribeScanOutputsResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// ScanService_ServiceDesc is the grpc.ServiceDesc for ScanService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ScanService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "silentium.v1.ScanService",
+	HandlerType: (*ScanServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _ScanService_Register_Handler,
+		},
+		{
+			MethodName: "Unregister",
+			Handler:    _ScanService_Unregister_Handler,
+		},
+		{
+			MethodName: "GetScanOutputs",
+			Handler:    _ScanService_GetScanOutputs_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeScanOutputs",
+			Handler:       _ScanService_SubscribeScanOutputs_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "silentium/v1/silentium.proto",
 }
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"os"
 	"os/signal"
@@ -8,7 +9,9 @@ import (
 
 	"github.com/louisinger/silentiumd/internal/application"
 	"github.com/louisinger/silentiumd/internal/config"
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/inmemory"
 	grpcservice "github.com/louisinger/silentiumd/internal/interface/grpc"
+	"github.com/louisinger/silentiumd/internal/ports"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,6 +23,59 @@ func main() {
 
 	logrus.Info("config OK")
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			version, err := cfg.Migrate()
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			logrus.Infof("db schema is up to date (version %d)", version)
+			logrus.Exit(0)
+		case "export", "import":
+			if len(os.Args) < 3 {
+				logrus.Fatalf("usage: silentiumd %s <snapshot file>", os.Args[1])
+			}
+
+			snapshot := exportSnapshot
+			if os.Args[1] == "import" {
+				snapshot = importSnapshot
+			}
+
+			info, err := snapshot(cfg, os.Args[2])
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			logrus.Infof("%sed %d blocks (%d to %d)", os.Args[1], info.Blocks, info.FirstHeight, info.LastHeight)
+			logrus.Exit(0)
+		case "verify":
+			report, err := verify(cfg, os.Args[2:])
+			if err != nil {
+				logrus.Fatal(err)
+			}
+
+			for _, mismatch := range report.Mismatches {
+				logrus.Warn(mismatch)
+			}
+
+			logrus.Infof(
+				"verified %d blocks (%d to %d) and %d taproot outputs: %d mismatches",
+				report.Blocks, report.From, report.To, report.Outputs, len(report.Mismatches),
+			)
+
+			if len(report.Mismatches) > 0 && !report.Repaired {
+				logrus.Exit(1)
+			}
+
+			if report.Repaired {
+				logrus.Info("mismatches repaired")
+			}
+			logrus.Exit(0)
+		}
+	}
+
 	chainSource, err := cfg.GetChainsource()
 	if err != nil {
 		logrus.Fatal(err)
@@ -34,11 +90,15 @@ func main() {
 
 	logrus.Info("db OK")
 
+	events := application.NewEventBus()
+
 	service, err := application.NewSyncerService(
 		scalarsRepository,
 		chainSource,
 		cfg.ChainParams,
 		cfg.StartHeight,
+		cfg.SyncWorkers,
+		events,
 	)
 	if err != nil {
 		logrus.Fatal(err)
@@ -50,14 +110,40 @@ func main() {
 
 	logrus.Info("syncer service OK")
 
-	silentiumSvc := application.NewSilentiumService(scalarsRepository, chainSource)
+	// the mempool scalars are only served if the watcher is enabled
+	var mempoolRepository ports.MempoolRepository
+
+	var mempoolSvc application.MempoolService
+	if cfg.Mempool {
+		mempoolRepository = inmemory.NewMempoolRepository()
+		mempoolSvc = application.NewMempoolService(mempoolRepository, chainSource, events)
+		if err := mempoolSvc.Start(); err != nil {
+			logrus.Fatal(err)
+		}
+
+		logrus.Info("mempool service OK")
+	}
+
+	var scanSvc application.ScanService
+	if cfg.ScanService {
+		scanSvc = application.NewScanService(scalarsRepository, events, cfg.ScanKeyTTL)
+		if err := scanSvc.Start(); err != nil {
+			logrus.Fatal(err)
+		}
+
+		logrus.Warn("scan service enabled, the clients registering their scan keys trade their privacy for bandwidth")
+	}
+
+	silentiumSvc := application.NewSilentiumService(scalarsRepository, chainSource, events, mempoolRepository)
 
 	grpcSvc, err := grpcservice.NewService(
 		grpcservice.Config{
-			AppService: silentiumSvc,
-			Port:       cfg.Port,
-			TLSKey:     cfg.KeyFileTLS,
-			TLSCert:    cfg.CertFileTLS,
+			AppService:       silentiumSvc,
+			ScanService:      scanSvc,
+			Port:             cfg.Port,
+			TLSKey:           cfg.KeyFileTLS,
+			TLSCert:          cfg.CertFileTLS,
+			WSAllowedOrigins: cfg.WSAllowedOrigins,
 		},
 	)
 	if err != nil {
@@ -78,6 +164,98 @@ func main() {
 		log.Fatal(err)
 	}
 
+	if mempoolSvc != nil {
+		if err := mempoolSvc.Stop(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if scanSvc != nil {
+		if err := scanSvc.Stop(); err != nil {
+			log.Fatal(err)
+		}
+	}
+
 	logrus.Info("shutting down service...")
 	logrus.Exit(0)
 }
+
+// exportSnapshot dumps the repository to a new file, removed if the export fails.
+func exportSnapshot(cfg *config.Config, path string) (*application.SnapshotInfo, error) {
+	store, err := cfg.GetRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := application.ExportSnapshot(store, cfg.ChainParams, file)
+	if err == nil {
+		err = file.Sync()
+	}
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	return info, nil
+}
+
+func importSnapshot(cfg *config.Config, path string) (*application.SnapshotInfo, error) {
+	store, err := cfg.GetRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return application.ImportSnapshot(store, cfg.ChainParams, file)
+}
+
+// verify parses the verify command flags: --from and --to default to the indexed range.
+func verify(cfg *config.Config, args []string) (*application.VerifyReport, error) {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	from := flags.Int("from", 0, "first block height to verify")
+	to := flags.Int("to", 0, "last block height to verify (default: index tip)")
+	repair := flags.Bool("repair", false, "repair the mismatches")
+
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	chainSource, err := cfg.GetChainsource()
+	if err != nil {
+		return nil, err
+	}
+
+	store, err := cfg.GetRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	service, err := application.NewSyncerService(
+		store,
+		chainSource,
+		cfg.ChainParams,
+		cfg.StartHeight,
+		cfg.SyncWorkers,
+		application.NewEventBus(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Verify(int32(*from), int32(*to), *repair)
+}
@@ -0,0 +1,87 @@
+package application
+
+import (
+	"sync"
+
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// subscriberBufferSize is the number of events buffered per subscriber,
+// a subscriber falling behind is dropped to never block the syncer.
+const subscriberBufferSize = 100
+
+type EventType int
+
+const (
+	BlockIndexed EventType = iota
+	ChainReorg
+)
+
+// ChainEvent notifies the changes of the indexed chain.
+type ChainEvent struct {
+	Type EventType
+	// Block is the committed block, set for BlockIndexed events.
+	Block domain.BlockScalars
+	// Filter is the hex-encoded taproot filter of the block (see domain.NewTaprootFilter), set for BlockIndexed events.
+	// it is empty for the replayed blocks indexed before the taproot filters were stored.
+	Filter string
+	// ForkHeight is the last block kept by a ChainReorg, the blocks above are orphaned.
+	ForkHeight int32
+	// Replayed is set for the indexed blocks replayed by SilentiumService.SubscribeScalars.
+	Replayed bool
+}
+
+// EventBus dispatches the syncer events to the subscribers.
+type EventBus interface {
+	Publish(event ChainEvent)
+	// Subscribe returns a channel receiving the published events and a function to unsubscribe.
+	// the channel is closed on unsubscribe or if the subscriber is too slow to consume the events.
+	Subscribe() (<-chan ChainEvent, func())
+}
+
+type eventBus struct {
+	lock        sync.Mutex
+	subscribers map[int]chan ChainEvent
+	nextID      int
+}
+
+func NewEventBus() EventBus {
+	return &eventBus{subscribers: make(map[int]chan ChainEvent)}
+}
+
+func (b *eventBus) Publish(event ChainEvent) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	for id, ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			logrus.Warnf("event subscriber %d is too slow, dropping it", id)
+			close(ch)
+			delete(b.subscribers, id)
+		}
+	}
+}
+
+func (b *eventBus) Subscribe() (<-chan ChainEvent, func()) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	id := b.nextID
+	b.nextID++
+
+	ch := make(chan ChainEvent, subscriberBufferSize)
+	b.subscribers[id] = ch
+
+	return ch, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		if _, ok := b.subscribers[id]; ok {
+			close(ch)
+			delete(b.subscribers, id)
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package application
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	mempoolPollingInterval = 10 * time.Second
+	// mempoolTxTTL is the time after which an unconfirmed transaction is dropped
+	mempoolTxTTL = 24 * time.Hour
+)
+
+// MempoolService watches the mempool in background
+// and stores the scalars of the unconfirmed silent payment eligible transactions.
+// the transactions are dropped once they leave the mempool (confirmed, replaced or evicted) or expire.
+type MempoolService interface {
+	Start() error
+	Stop() error
+}
+
+type mempoolWatcher struct {
+	store       ports.MempoolRepository
+	chainsource ports.ChainSource
+	events      EventBus
+
+	// ignored are the mempool transactions not eligible or expired, they are not fetched again
+	ignored map[chainhash.Hash]struct{}
+	quit    chan struct{}
+}
+
+func NewMempoolService(
+	store ports.MempoolRepository,
+	chainsrc ports.ChainSource,
+	events EventBus,
+) MempoolService {
+	return &mempoolWatcher{
+		store:       store,
+		chainsource: chainsrc,
+		events:      events,
+		ignored:     make(map[chainhash.Hash]struct{}),
+	}
+}
+
+func (m *mempoolWatcher) Start() error {
+	m.quit = make(chan struct{})
+	go m.run()
+	return nil
+}
+
+func (m *mempoolWatcher) Stop() error {
+	close(m.quit)
+	return nil
+}
+
+// run syncs the mempool periodically and as soon as a block is indexed.
+func (m *mempoolWatcher) run() {
+	ticker := time.NewTicker(mempoolPollingInterval)
+	defer ticker.Stop()
+
+	events, unsubscribe := m.events.Subscribe()
+	defer func() { unsubscribe() }()
+
+	m.sync()
+
+	for {
+		select {
+		case <-m.quit:
+			logrus.Info("stop mempool watcher")
+			return
+		case <-ticker.C:
+		case event, ok := <-events:
+			if !ok {
+				events, unsubscribe = m.events.Subscribe()
+				continue
+			}
+
+			if event.Type != BlockIndexed {
+				continue
+			}
+		}
+
+		m.sync()
+	}
+}
+
+func (m *mempoolWatcher) sync() {
+	txids, err := m.chainsource.GetMempoolTxids()
+	if err != nil {
+		logrus.Errorf("unable to get mempool: %s", err)
+		return
+	}
+
+	inMempool := make(map[chainhash.Hash]struct{}, len(txids))
+	for _, txid := range txids {
+		inMempool[txid] = struct{}{}
+	}
+
+	stored, err := m.store.GetTxids()
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	known := make(map[chainhash.Hash]struct{}, len(stored))
+	dropped := make([]chainhash.Hash, 0)
+
+	for _, txid := range stored {
+		if _, ok := inMempool[txid]; ok {
+			known[txid] = struct{}{}
+			continue
+		}
+
+		dropped = append(dropped, txid)
+	}
+
+	if err := m.store.Remove(dropped); err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	expired, err := m.store.RemoveExpired(time.Now().Add(-mempoolTxTTL))
+	if err != nil {
+		logrus.Error(err)
+		return
+	}
+
+	for _, txid := range expired {
+		delete(known, txid)
+		m.ignored[txid] = struct{}{}
+	}
+
+	for txid := range m.ignored {
+		if _, ok := inMempool[txid]; !ok {
+			delete(m.ignored, txid)
+		}
+	}
+
+	added := 0
+
+	for _, txid := range txids {
+		if _, ok := known[txid]; ok {
+			continue
+		}
+
+		if _, ok := m.ignored[txid]; ok {
+			continue
+		}
+
+		select {
+		case <-m.quit:
+			return
+		default:
+		}
+
+		ok, err := m.addTx(txid)
+		if err != nil {
+			// the transaction may have left the mempool, retry on next sync
+			logrus.Debugf("unable to add mempool tx %s: %s", txid, err)
+			continue
+		}
+
+		if !ok {
+			m.ignored[txid] = struct{}{}
+			continue
+		}
+
+		added++
+	}
+
+	logrus.Debugf("mempool synced: %d txs added, %d txs dropped, %d txs expired", added, len(dropped), len(expired))
+}
+
+// addTx stores the scalar of the mempool transaction,
+// it returns false if the transaction is not eligible to silent payments.
+func (m *mempoolWatcher) addTx(txid chainhash.Hash) (bool, error) {
+	tx, err := m.chainsource.GetMempoolTx(txid)
+	if err != nil {
+		return false, err
+	}
+
+	if !isSilentPaymentElligibleTx(tx) {
+		return false, nil
+	}
+
+	prevouts, err := m.chainsource.GetMempoolPrevouts(tx)
+	if err != nil {
+		return false, err
+	}
+
+	scalar, err := computeTxScalar(tx, prevouts.GetPrevoutScript)
+	if err != nil {
+		logrus.Errorf("unable to compute scalar of %s: %s", tx.Hash(), err)
+		return false, nil
+	}
+
+	if scalar == nil {
+		return false, nil
+	}
+
+	return true, m.store.Add(scalar, time.Now())
+}
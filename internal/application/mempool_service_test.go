@@ -0,0 +1,66 @@
+package application
+
+import (
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/inmemory"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMempoolWatcher(t *testing.T) {
+	mempoolPollingInterval = 10 * time.Millisecond
+
+	chain := newFakeChain(t, 1)
+	store := inmemory.NewMempoolRepository()
+
+	svc := NewMempoolService(store, chain, NewEventBus())
+
+	eligibleTx := newP2WPKHSpend(t)
+
+	notEligibleTx := newP2WPKHSpend(t)
+	notEligibleTx.TxOut[0].PkScript = []byte{0x00, 0x14}
+
+	chain.setMempool(eligibleTx, notEligibleTx)
+
+	require.NoError(t, svc.Start())
+	defer svc.Stop()
+
+	requireMempoolTxs := func(txs ...*wire.MsgTx) {
+		t.Helper()
+
+		require.Eventually(t, func() bool {
+			txids, err := store.GetTxids()
+			if err != nil || len(txids) != len(txs) {
+				return false
+			}
+
+			for i, tx := range txs {
+				if txids[i] != tx.TxHash() {
+					return false
+				}
+			}
+
+			return true
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	requireMempoolTxs(eligibleTx)
+
+	scalars, err := store.GetScalars()
+	require.NoError(t, err)
+	require.Len(t, scalars, 1)
+
+	// replaced
+	replacementTx := eligibleTx.Copy()
+	replacementTx.TxOut[0].Value--
+	chain.setMempool(replacementTx, notEligibleTx)
+
+	requireMempoolTxs(replacementTx)
+
+	// confirmed
+	chain.setMempool(notEligibleTx)
+
+	requireMempoolTxs()
+}
@@ -0,0 +1,569 @@
+package application
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/louisinger/silentiumd/pkg/scanner"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxScanKeys is the maximum number of keys registered at the same time.
+	MaxScanKeys = 100
+	// MaxScanLabels is the maximum number of labels per registered key.
+	MaxScanLabels = 1000
+)
+
+var (
+	// scanRetryInterval is the period of the checks of the chain tip, in case an event is missed or a scan failed.
+	scanRetryInterval = time.Minute
+
+	ErrScanKeyNotFound       = errors.New("scan key not found, unregistered or expired")
+	ErrTooManyScanKeys       = errors.New("too many scan keys registered")
+	ErrTooManyScanLabels     = errors.New("too many labels")
+	ErrScanServiceNotStarted = errors.New("scan service not started")
+)
+
+type ScanEventType int
+
+const (
+	OutputsFound ScanEventType = iota
+	ScanReorg
+)
+
+// ScanOutput is an indexed taproot output paying a registered key.
+type ScanOutput struct {
+	scanner.Output
+	Height    int32
+	BlockHash chainhash.Hash
+	Value     int64
+}
+
+// ScanEvent notifies the changes of the outputs found for a registered key.
+type ScanEvent struct {
+	Type ScanEventType
+	// Height is the block of the outputs, set for OutputsFound events.
+	Height  int32
+	Outputs []ScanOutput
+	// ForkHeight is the last block kept by a ScanReorg, the outputs above are dropped.
+	ForkHeight int32
+}
+
+// ScanService scans the indexed blocks on behalf of the clients trading their privacy for bandwidth.
+// the keys are only kept in memory, they are wiped when unregistered, expired or when the service stops.
+type ScanService interface {
+	Start() error
+	Stop() error
+	// Register starts scanning the blocks from birthday (0 to only scan the new blocks) for the address
+	// of the keys and its labels. it returns the id of the registration and the time it expires.
+	// the service owns scanKey, it is zeroed when the registration ends.
+	Register(scanKey *btcec.PrivateKey, spendKey *btcec.PublicKey, labels []uint32, birthday uint32) (id string, expiresAt time.Time, err error)
+	// Unregister stops the scan and wipes the keys.
+	Unregister(id string) error
+	// GetOutputs returns the outputs found in the blocks from the given height and the height scanned so far.
+	GetOutputs(id string, from uint32) (outputs []ScanOutput, scannedHeight int32, err error)
+	// SubscribeOutputs replays the outputs found from the given height and then sends the outputs as they are found.
+	// the channel is closed when ctx is done, the registration ends or if the subscriber is too slow.
+	SubscribeOutputs(ctx context.Context, id string, from uint32) (<-chan ScanEvent, error)
+}
+
+type scanService struct {
+	repo   ports.ScalarRepository
+	events EventBus
+	ttl    time.Duration
+
+	lock          sync.Mutex
+	registrations map[string]*registration
+	ctx           context.Context
+	cancel        context.CancelFunc
+	wg            sync.WaitGroup
+}
+
+// registration is a key scanned by the service, the scanner is only used by its scan goroutine.
+type registration struct {
+	scanner   *scanner.Scanner
+	birthday  int32
+	expiresAt time.Time
+	cancel    context.CancelFunc
+	// stopped is closed once the scan is stopped and the keys wiped
+	stopped chan struct{}
+
+	lock    sync.Mutex
+	outputs []ScanOutput
+	scanned int32
+	// scannedHash is the hash of the last scanned block, zero if unknown
+	scannedHash  chainhash.Hash
+	subscribers  map[int]chan ScanEvent
+	nextSubID    int
+	unregistered bool
+}
+
+func NewScanService(repo ports.ScalarRepository, events EventBus, ttl time.Duration) ScanService {
+	return &scanService{
+		repo:          repo,
+		events:        events,
+		ttl:           ttl,
+		registrations: make(map[string]*registration),
+	}
+}
+
+func (s *scanService) Start() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	return nil
+}
+
+// Stop stops all the scans and waits for their keys to be wiped.
+func (s *scanService) Stop() error {
+	s.lock.Lock()
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.ctx = nil
+	s.lock.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+func (s *scanService) Register(scanKey *btcec.PrivateKey, spendKey *btcec.PublicKey, labels []uint32, birthday uint32) (string, time.Time, error) {
+	if birthday > math.MaxInt32 {
+		return "", time.Time{}, ErrInvalidBlockRange
+	}
+
+	if len(labels) > MaxScanLabels {
+		return "", time.Time{}, ErrTooManyScanLabels
+	}
+
+	tip, err := s.repo.GetLatestBlockHeight()
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ctx == nil {
+		return "", time.Time{}, ErrScanServiceNotStarted
+	}
+
+	if len(s.registrations) >= MaxScanKeys {
+		return "", time.Time{}, ErrTooManyScanKeys
+	}
+
+	keyScanner, err := scanner.New(scanKey, spendKey, labels...)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	id, err := newRegistrationID()
+	if err != nil {
+		keyScanner.Zero()
+		return "", time.Time{}, err
+	}
+
+	reg := &registration{
+		scanner:     keyScanner,
+		birthday:    int32(birthday),
+		expiresAt:   time.Now().Add(s.ttl),
+		stopped:     make(chan struct{}),
+		outputs:     make([]ScanOutput, 0),
+		subscribers: make(map[int]chan ScanEvent),
+	}
+
+	// 0 only scans the blocks above the current tip
+	if birthday == 0 {
+		reg.birthday = tip + 1
+	}
+	reg.scanned = reg.birthday - 1
+
+	ctx, cancel := context.WithDeadline(s.ctx, reg.expiresAt)
+	reg.cancel = cancel
+	s.registrations[id] = reg
+
+	s.wg.Add(1)
+	go s.scan(ctx, id, reg)
+
+	return id, reg.expiresAt, nil
+}
+
+func (s *scanService) Unregister(id string) error {
+	reg, err := s.get(id)
+	if err != nil {
+		return err
+	}
+
+	reg.cancel()
+	<-reg.stopped
+	return nil
+}
+
+func (s *scanService) GetOutputs(id string, from uint32) ([]ScanOutput, int32, error) {
+	reg, err := s.get(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	if reg.unregistered {
+		return nil, 0, ErrScanKeyNotFound
+	}
+
+	return reg.outputsFrom(from), reg.scanned, nil
+}
+
+func (s *scanService) SubscribeOutputs(ctx context.Context, id string, from uint32) (<-chan ScanEvent, error) {
+	reg, err := s.get(id)
+	if err != nil {
+		return nil, err
+	}
+
+	reg.lock.Lock()
+	if reg.unregistered {
+		reg.lock.Unlock()
+		return nil, ErrScanKeyNotFound
+	}
+
+	// the replay and the subscription are atomic to not miss or duplicate outputs
+	replay := groupByBlock(reg.outputsFrom(from))
+	live := make(chan ScanEvent, subscriberBufferSize)
+	subID := reg.nextSubID
+	reg.nextSubID++
+	reg.subscribers[subID] = live
+	reg.lock.Unlock()
+
+	events := make(chan ScanEvent)
+
+	go func() {
+		defer close(events)
+		defer reg.unsubscribe(subID)
+
+		for _, event := range replay {
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-live:
+				if !ok {
+					return
+				}
+
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (s *scanService) get(id string) (*registration, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	reg, ok := s.registrations[id]
+	if !ok {
+		return nil, ErrScanKeyNotFound
+	}
+
+	return reg, nil
+}
+
+// scan scans the indexed blocks up to the tip and then the new blocks, until ctx is done.
+func (s *scanService) scan(ctx context.Context, id string, reg *registration) {
+	defer s.wg.Done()
+	defer s.remove(id, reg)
+
+	ticker := time.NewTicker(scanRetryInterval)
+	defer ticker.Stop()
+
+	events, unsubscribe := s.events.Subscribe()
+	defer func() { unsubscribe() }()
+
+	for {
+		if err := s.catchUp(ctx, reg); err != nil {
+			logrus.Errorf("scan %s: %s", id, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case event, ok := <-events:
+			if !ok {
+				events, unsubscribe = s.events.Subscribe()
+				continue
+			}
+
+			if event.Type == ChainReorg {
+				reg.rollback(event.ForkHeight)
+			}
+		}
+	}
+}
+
+// catchUp scans the blocks above the last scanned one up to the tip.
+// the scan restarts from the birthday if the last scanned block has been orphaned by a missed reorg.
+func (s *scanService) catchUp(ctx context.Context, reg *registration) error {
+	tip, err := s.repo.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	scanned, scannedHash := reg.lastScanned()
+	if scannedHash != (chainhash.Hash{}) {
+		header, err := s.repo.GetBlockHeader(scanned)
+		if err != nil && !errors.As(err, &ports.ErrBlockNotFound{}) {
+			return err
+		}
+
+		if header == nil || header.Hash != scannedHash {
+			reg.rollback(reg.birthday - 1)
+			scanned = reg.birthday - 1
+		}
+	}
+
+	for height := scanned + 1; height <= tip; height++ {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err := s.scanBlock(reg, height); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *scanService) scanBlock(reg *registration, height int32) error {
+	// the blocks indexed before the hashes were stored have no header
+	var blockHash chainhash.Hash
+	header, err := s.repo.GetBlockHeader(height)
+	if err != nil && !errors.As(err, &ports.ErrBlockNotFound{}) {
+		return err
+	}
+	if header != nil {
+		blockHash = header.Hash
+	}
+
+	silentScalars, err := s.repo.GetSilentScalars(height, ports.AllScalars, 0)
+	if err != nil {
+		// the blocks below the start height are not indexed
+		if errors.As(err, &ports.ErrBlockNotFound{}) {
+			reg.addBlock(height, blockHash, nil)
+			return nil
+		}
+
+		return err
+	}
+
+	txs := make([]scanner.Transaction, 0, len(silentScalars))
+	values := make(map[chainhash.Hash]map[uint32]int64, len(silentScalars))
+
+	for _, silentScalar := range silentScalars {
+		scalar, err := btcec.ParsePubKey(silentScalar.Scalar)
+		if err != nil {
+			logrus.Warnf("[%d] invalid scalar of %s: %s", height, silentScalar.TxHash, err)
+			continue
+		}
+
+		tx := scanner.Transaction{TxHash: *silentScalar.TxHash, Scalar: scalar}
+		values[tx.TxHash] = make(map[uint32]int64, len(silentScalar.TaprootOutputs))
+
+		for _, out := range silentScalar.TaprootOutputs {
+			// the keys of the outputs indexed before the keys were stored are unknown
+			if len(out.Key) == 0 {
+				continue
+			}
+
+			tx.Outputs = append(tx.Outputs, scanner.TaprootOutput{Index: out.Index, Key: out.Key})
+			values[tx.TxHash][out.Index] = out.Value
+		}
+
+		if len(tx.Outputs) > 0 {
+			txs = append(txs, tx)
+		}
+	}
+
+	found, err := reg.scanner.ScanBlock(txs)
+	if err != nil {
+		return err
+	}
+
+	outputs := make([]ScanOutput, 0, len(found))
+	for _, output := range found {
+		outputs = append(outputs, ScanOutput{
+			Output:    output,
+			Height:    height,
+			BlockHash: blockHash,
+			Value:     values[output.TxHash][output.Index],
+		})
+	}
+
+	reg.addBlock(height, blockHash, outputs)
+	return nil
+}
+
+// remove drops the registration, closes its subscriptions and wipes its keys.
+// the keys are wiped before the registration is dropped.
+func (s *scanService) remove(id string, reg *registration) {
+	defer close(reg.stopped)
+
+	reg.lock.Lock()
+	reg.unregistered = true
+	for subID, ch := range reg.subscribers {
+		close(ch)
+		delete(reg.subscribers, subID)
+	}
+
+	for i := range reg.outputs {
+		reg.outputs[i].Tweak = nil
+	}
+	reg.outputs = nil
+
+	reg.scanner.Zero()
+	reg.cancel()
+	reg.lock.Unlock()
+
+	s.lock.Lock()
+	if s.registrations[id] == reg {
+		delete(s.registrations, id)
+	}
+	s.lock.Unlock()
+}
+
+func (r *registration) lastScanned() (int32, chainhash.Hash) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.scanned, r.scannedHash
+}
+
+func (r *registration) addBlock(height int32, blockHash chainhash.Hash, outputs []ScanOutput) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.scanned = height
+	r.scannedHash = blockHash
+
+	if len(outputs) == 0 {
+		return
+	}
+
+	r.outputs = append(r.outputs, outputs...)
+	r.publish(ScanEvent{Type: OutputsFound, Height: height, Outputs: outputs})
+}
+
+// rollback drops the outputs above forkHeight, the blocks above are scanned again.
+func (r *registration) rollback(forkHeight int32) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if forkHeight < r.birthday-1 {
+		forkHeight = r.birthday - 1
+	}
+
+	if forkHeight >= r.scanned {
+		return
+	}
+
+	r.scanned = forkHeight
+	r.scannedHash = chainhash.Hash{}
+
+	kept := make([]ScanOutput, 0, len(r.outputs))
+	for _, output := range r.outputs {
+		if output.Height <= forkHeight {
+			kept = append(kept, output)
+		}
+	}
+
+	if len(kept) == len(r.outputs) {
+		return
+	}
+
+	r.outputs = kept
+	r.publish(ScanEvent{Type: ScanReorg, ForkHeight: forkHeight})
+}
+
+// publish sends the event to the subscribers, a subscriber falling behind is dropped.
+// it must be called with the lock held.
+func (r *registration) publish(event ScanEvent) {
+	for subID, ch := range r.subscribers {
+		select {
+		case ch <- event:
+		default:
+			close(ch)
+			delete(r.subscribers, subID)
+		}
+	}
+}
+
+func (r *registration) unsubscribe(subID int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if ch, ok := r.subscribers[subID]; ok {
+		close(ch)
+		delete(r.subscribers, subID)
+	}
+}
+
+// outputsFrom returns the outputs found in the blocks from the given height, it must be called with the lock held.
+func (r *registration) outputsFrom(from uint32) []ScanOutput {
+	outputs := make([]ScanOutput, 0)
+	for _, output := range r.outputs {
+		if int64(output.Height) >= int64(from) {
+			outputs = append(outputs, output)
+		}
+	}
+
+	return outputs
+}
+
+// groupByBlock returns an OutputsFound event per block, the outputs are ordered by height.
+func groupByBlock(outputs []ScanOutput) []ScanEvent {
+	events := make([]ScanEvent, 0)
+	for _, output := range outputs {
+		if len(events) == 0 || events[len(events)-1].Height != output.Height {
+			events = append(events, ScanEvent{Type: OutputsFound, Height: output.Height})
+		}
+
+		last := &events[len(events)-1]
+		last.Outputs = append(last.Outputs, output)
+	}
+
+	return events
+}
+
+func newRegistrationID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,274 @@
+package application
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/louisinger/silentiumd/pkg/address"
+	"github.com/louisinger/silentiumd/pkg/sender"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanService(t *testing.T) {
+	scanKey, spendKey := newScanKeys(t)
+
+	addr, err := address.New(scanKey.PubKey(), spendKey, &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+	labeled, err := address.NewLabeled(scanKey, spendKey, 1, &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+
+	// the blocks 2 and 3 pay the address and its label 1, the others pay another address
+	store := newEmptyStore(t)
+	writeScanBlock(t, store, 1, newOtherAddress(t))
+	writeScanBlock(t, store, 2, addr)
+	writeScanBlock(t, store, 3, labeled)
+
+	events := NewEventBus()
+	svc := NewScanService(store, events, time.Hour)
+	require.NoError(t, svc.Start())
+	defer svc.Stop()
+
+	id, expiresAt, err := svc.Register(scanKey, spendKey, []uint32{1}, 1)
+	require.NoError(t, err)
+	require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+
+	outputs := requireScanned(t, svc, id, 3)
+	require.Len(t, outputs, 2)
+	require.Equal(t, int32(2), outputs[0].Height)
+	require.Nil(t, outputs[0].Label)
+	require.Equal(t, int32(3), outputs[1].Height)
+	require.Equal(t, uint32(1), *outputs[1].Label)
+	require.Equal(t, int64(1000), outputs[1].Value)
+
+	outputs, _, err = svc.GetOutputs(id, 3)
+	require.NoError(t, err)
+	require.Len(t, outputs, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	subscription, err := svc.SubscribeOutputs(ctx, id, 0)
+	require.NoError(t, err)
+
+	// replay
+	requireOutputsEvent(t, subscription, 2)
+	requireOutputsEvent(t, subscription, 3)
+
+	// new block
+	writeScanBlock(t, store, 4, addr)
+	events.Publish(ChainEvent{Type: BlockIndexed, Block: domain.BlockScalars{Height: 4}})
+	requireOutputsEvent(t, subscription, 4)
+
+	// reorg
+	require.NoError(t, store.Rollback(3))
+	events.Publish(ChainEvent{Type: ChainReorg, ForkHeight: 3})
+
+	event := receiveScanEvent(t, subscription)
+	require.Equal(t, ScanReorg, event.Type)
+	require.Equal(t, int32(3), event.ForkHeight)
+
+	outputs = requireScanned(t, svc, id, 3)
+	require.Len(t, outputs, 2)
+
+	t.Run("unregister", func(t *testing.T) {
+		require.NoError(t, svc.Unregister(id))
+
+		_, _, err := svc.GetOutputs(id, 0)
+		require.ErrorIs(t, err, ErrScanKeyNotFound)
+		require.ErrorIs(t, svc.Unregister(id), ErrScanKeyNotFound)
+
+		// the key is wiped
+		require.True(t, scanKey.Key.IsZero())
+
+		select {
+		case _, ok := <-subscription:
+			require.False(t, ok)
+		case <-time.After(5 * time.Second):
+			t.Fatal("subscription not closed")
+		}
+	})
+
+	t.Run("new blocks only", func(t *testing.T) {
+		scanKey, spendKey := newScanKeys(t)
+		id, _, err := svc.Register(scanKey, spendKey, nil, 0)
+		require.NoError(t, err)
+
+		outputs, scanned, err := svc.GetOutputs(id, 0)
+		require.NoError(t, err)
+		require.Empty(t, outputs)
+		require.Equal(t, int32(3), scanned)
+
+		require.NoError(t, svc.Unregister(id))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := svc.SubscribeOutputs(ctx, "unknown", 0)
+		require.ErrorIs(t, err, ErrScanKeyNotFound)
+
+		scanKey, spendKey := newScanKeys(t)
+		_, _, err = svc.Register(scanKey, spendKey, make([]uint32, MaxScanLabels+1), 0)
+		require.ErrorIs(t, err, ErrTooManyScanLabels)
+	})
+}
+
+func TestScanServiceBirthdayBelowIndexedBlocks(t *testing.T) {
+	scanKey, spendKey := newScanKeys(t)
+
+	addr, err := address.New(scanKey.PubKey(), spendKey, &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+
+	// the blocks below the height 3 are not indexed
+	store := newEmptyStore(t)
+	writeScanBlock(t, store, 3, newOtherAddress(t))
+	writeScanBlock(t, store, 4, addr)
+
+	svc := NewScanService(store, NewEventBus(), time.Hour)
+	require.NoError(t, svc.Start())
+	defer svc.Stop()
+
+	id, _, err := svc.Register(scanKey, spendKey, nil, 1)
+	require.NoError(t, err)
+
+	outputs := requireScanned(t, svc, id, 4)
+	require.Len(t, outputs, 1)
+	require.Equal(t, int32(4), outputs[0].Height)
+}
+
+func TestScanServiceExpiry(t *testing.T) {
+	store := newEmptyStore(t)
+	writeScanBlock(t, store, 1, newOtherAddress(t))
+
+	svc := NewScanService(store, NewEventBus(), 100*time.Millisecond)
+	require.NoError(t, svc.Start())
+
+	scanKey, spendKey := newScanKeys(t)
+	id, _, err := svc.Register(scanKey, spendKey, nil, 1)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, _, err := svc.GetOutputs(id, 0)
+		return err == ErrScanKeyNotFound
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, scanKey.Key.IsZero())
+
+	// stopping the service wipes the registered keys
+	scanKey, spendKey = newScanKeys(t)
+	_, _, err = svc.Register(scanKey, spendKey, nil, 1)
+	require.NoError(t, err)
+
+	require.NoError(t, svc.Stop())
+	require.True(t, scanKey.Key.IsZero())
+
+	_, _, err = svc.Register(scanKey, spendKey, nil, 1)
+	require.ErrorIs(t, err, ErrScanServiceNotStarted)
+}
+
+func requireScanned(t *testing.T, svc ScanService, id string, height int32) []ScanOutput {
+	t.Helper()
+
+	var outputs []ScanOutput
+	require.Eventually(t, func() bool {
+		var scanned int32
+		var err error
+
+		outputs, scanned, err = svc.GetOutputs(id, 0)
+		return err == nil && scanned == height
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return outputs
+}
+
+func receiveScanEvent(t *testing.T, events <-chan ScanEvent) ScanEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		require.True(t, ok, "subscription closed")
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for scan event")
+		return ScanEvent{}
+	}
+}
+
+func requireOutputsEvent(t *testing.T, events <-chan ScanEvent, height int32) {
+	t.Helper()
+
+	event := receiveScanEvent(t, events)
+	require.Equal(t, OutputsFound, event.Type)
+	require.Equal(t, height, event.Height)
+	require.Len(t, event.Outputs, 1)
+}
+
+// writeScanBlock writes a block with a transaction paying the address and a transaction without taproot key.
+func writeScanBlock(t *testing.T, store ports.ScalarRepository, height int32, recipient *address.Address) {
+	t.Helper()
+
+	inputKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	prevoutScript, err := txscript.PayToTaprootScript(inputKey.PubKey())
+	require.NoError(t, err)
+
+	var prevTxHash chainhash.Hash
+	binary.BigEndian.PutUint32(prevTxHash[:], uint32(height))
+
+	txIn := &wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: prevTxHash},
+		Witness:          wire.TxWitness{make([]byte, 64)},
+	}
+
+	scripts, err := sender.OutputScripts(
+		[]sender.Input{{TxIn: txIn, PrevoutScript: prevoutScript, PrivateKey: inputKey}},
+		[]*address.Address{recipient},
+	)
+	require.NoError(t, err)
+
+	payment := &domain.SilentScalar{TxIn: []*wire.TxIn{txIn}}
+	require.NoError(t, payment.ComputeScalar(func(wire.OutPoint) ([]byte, error) { return prevoutScript, nil }))
+
+	txHash := chainhash.HashH(prevTxHash[:])
+	payment.TxHash = &txHash
+	payment.TaprootOutputs = []domain.TaprootOutput{{Index: 0, Key: scripts[0][2:], Value: 1000}}
+
+	otherHash := chainhash.HashH(txHash[:])
+	other := &domain.SilentScalar{
+		TxHash:         &otherHash,
+		Scalar:         payment.Scalar,
+		TaprootOutputs: []domain.TaprootOutput{{Index: 0}},
+	}
+
+	var hash chainhash.Hash
+	binary.BigEndian.PutUint32(hash[:], uint32(height))
+
+	require.NoError(t, store.Write([]*domain.SilentScalar{payment, other}, domain.BlockHeader{Height: height, Hash: hash}, nil))
+}
+
+func newScanKeys(t *testing.T) (*btcec.PrivateKey, *btcec.PublicKey) {
+	t.Helper()
+
+	scanKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+	spendKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	return scanKey, spendKey.PubKey()
+}
+
+func newOtherAddress(t *testing.T) *address.Address {
+	t.Helper()
+
+	scanKey, spendKey := newScanKeys(t)
+	addr, err := address.New(scanKey.PubKey(), spendKey, &chaincfg.RegressionNetParams)
+	require.NoError(t, err)
+
+	return addr
+}
@@ -1,22 +1,70 @@
 package application
 
 import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"math"
+
+	"github.com/louisinger/silentiumd/internal/domain"
 	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxRangeBlocks is the maximum number of blocks returned by GetScalarsRange.
+	MaxRangeBlocks = 1000
+	// MaxRangeScalars caps the number of scalars returned by GetScalarsRange,
+	// the page is cut after the block reaching the cap.
+	MaxRangeScalars = 10000
+
+	rangeChunkSize = 100
+)
+
+var (
+	ErrInvalidBlockRange = errors.New("invalid block range: from must be lower or equal to to")
+	ErrMempoolDisabled   = errors.New("mempool watcher is disabled")
 )
 
 type SilentiumService interface {
-	GetScalarsByHeight(height uint32) ([]string, error)
+	// GetScalarsByHeight returns the scalars of the block transactions selected by filter,
+	// the transactions with all their taproot outputs below dustLimit sats are dropped.
+	GetScalarsByHeight(height uint32, filter ports.ScalarsFilter, dustLimit int64) ([]string, error)
+	// GetTransactionsByHeight returns the silent scalars of the block selected by filter and dustLimit
+	// with their selected taproot outputs, the spent ones are only kept with ports.AllScalars.
+	GetTransactionsByHeight(height uint32, filter ports.ScalarsFilter, dustLimit int64) ([]*domain.SilentScalar, error)
+	// GetScalarsRange returns a page of the indexed blocks in [from, to] with their scalars.
+	// next is the height to request the following page from, 0 if the range is complete.
+	GetScalarsRange(from, to, limit uint32, filter ports.ScalarsFilter, dustLimit int64) (blocks []domain.BlockScalars, next uint32, err error)
+	// GetMempoolScalars returns the scalars of the unconfirmed transactions,
+	// ErrMempoolDisabled if the service has no mempool repository.
+	GetMempoolScalars() ([]string, error)
 	GetBlockFilter(height uint32) (filter string, header string, err error)
+	// GetBlockTaprootFilter returns the hex-encoded filter over the unspent taproot output keys of the block.
+	GetBlockTaprootFilter(height uint32) (filter string, blockhash string, err error)
 	GetChainTip() (uint32, error)
+	// SubscribeScalars replays the indexed blocks from the given height (0 to skip the replay)
+	// and then sends the chain events as the syncer commits them, with the blocks taproot filters.
+	// the channel is closed when ctx is done or if the subscription fails.
+	SubscribeScalars(ctx context.Context, from uint32) (<-chan ChainEvent, error)
 }
 
 type silentium struct {
 	repo        ports.ScalarRepository
 	chainsource ports.ChainSource
+	events      EventBus
+	mempool     ports.MempoolRepository
 }
 
-func NewSilentiumService(repo ports.ScalarRepository, chainsource ports.ChainSource) SilentiumService {
-	return &silentium{repo, chainsource}
+// NewSilentiumService returns the service serving the indexed blocks,
+// mempool is nil if the mempool watcher is disabled.
+func NewSilentiumService(
+	repo ports.ScalarRepository,
+	chainsource ports.ChainSource,
+	events EventBus,
+	mempool ports.MempoolRepository,
+) SilentiumService {
+	return &silentium{repo, chainsource, events, mempool}
 }
 
 func (e *silentium) GetChainTip() (uint32, error) {
@@ -28,10 +76,231 @@ func (e *silentium) GetChainTip() (uint32, error) {
 	return uint32(last), nil
 }
 
-func (e *silentium) GetScalarsByHeight(height uint32) ([]string, error) {
-	return e.repo.GetScalars(int32(height))
+func (e *silentium) GetScalarsByHeight(height uint32, filter ports.ScalarsFilter, dustLimit int64) ([]string, error) {
+	return e.repo.GetScalars(int32(height), filter, dustLimit)
+}
+
+func (e *silentium) GetTransactionsByHeight(height uint32, filter ports.ScalarsFilter, dustLimit int64) ([]*domain.SilentScalar, error) {
+	silentScalars, err := e.repo.GetSilentScalars(int32(height), filter, dustLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, silentScalar := range silentScalars {
+		selected := make([]domain.TaprootOutput, 0, len(silentScalar.TaprootOutputs))
+		for _, out := range silentScalar.TaprootOutputs {
+			if filter.Selects(out, dustLimit) {
+				selected = append(selected, out)
+			}
+		}
+
+		silentScalar.TaprootOutputs = selected
+	}
+
+	return silentScalars, nil
+}
+
+func (e *silentium) GetScalarsRange(from, to, limit uint32, filter ports.ScalarsFilter, dustLimit int64) ([]domain.BlockScalars, uint32, error) {
+	if from > to {
+		return nil, 0, ErrInvalidBlockRange
+	}
+
+	tip, err := e.GetChainTip()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if to > tip {
+		to = tip
+	}
+
+	if from > to {
+		return []domain.BlockScalars{}, 0, nil
+	}
+
+	if limit == 0 || limit > MaxRangeBlocks {
+		limit = MaxRangeBlocks
+	}
+
+	end := to
+	if to-from >= limit {
+		end = from + limit - 1
+	}
+
+	blocks := make([]domain.BlockScalars, 0)
+	numOfScalars := 0
+	last := from - 1
+
+	for chunkStart := from; chunkStart <= end && numOfScalars < MaxRangeScalars; chunkStart += rangeChunkSize {
+		chunkEnd := end
+		if end-chunkStart >= rangeChunkSize {
+			chunkEnd = chunkStart + rangeChunkSize - 1
+		}
+
+		chunk, err := e.repo.GetScalarsRange(int32(chunkStart), int32(chunkEnd), filter, dustLimit)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		last = chunkEnd
+
+		for _, block := range chunk {
+			blocks = append(blocks, block)
+			numOfScalars += len(block.Scalars)
+
+			if numOfScalars >= MaxRangeScalars {
+				last = uint32(block.Height)
+				break
+			}
+		}
+	}
+
+	if last >= to {
+		return blocks, 0, nil
+	}
+
+	return blocks, last + 1, nil
+}
+
+func (e *silentium) GetMempoolScalars() ([]string, error) {
+	if e.mempool == nil {
+		return nil, ErrMempoolDisabled
+	}
+
+	return e.mempool.GetScalars()
+}
+
+func (e *silentium) GetBlockTaprootFilter(height uint32) (string, string, error) {
+	header, err := e.repo.GetBlockHeader(int32(height))
+	if err != nil {
+		return "", "", err
+	}
+
+	filter, err := e.repo.GetTaprootFilter(int32(height))
+	if err != nil {
+		return "", "", err
+	}
+
+	return hex.EncodeToString(filter), header.Hash.String(), nil
 }
 
 func (e *silentium) GetBlockFilter(height uint32) (filter string, blockhash string, err error) {
 	return e.chainsource.GetBlockFilterByHeight(int32(height))
 }
+
+func (e *silentium) SubscribeScalars(ctx context.Context, from uint32) (<-chan ChainEvent, error) {
+	if from > math.MaxInt32 {
+		return nil, ErrInvalidBlockRange
+	}
+
+	// subscribe before the replay to not miss the blocks committed meanwhile
+	live, unsubscribe := e.events.Subscribe()
+	events := make(chan ChainEvent)
+
+	go func() {
+		defer close(events)
+		defer unsubscribe()
+
+		// queued keeps the live events received during the replay,
+		// the event bus would drop the subscription if its buffer filled up meanwhile.
+		var queued []ChainEvent
+
+		replay := func(event ChainEvent) bool {
+			for {
+				select {
+				case events <- event:
+					return true
+				case liveEvent, ok := <-live:
+					if !ok {
+						return false
+					}
+					queued = append(queued, liveEvent)
+				case <-ctx.Done():
+					return false
+				}
+			}
+		}
+
+		// last is the height of the last block sent
+		last := int32(from) - 1
+
+		for next := from; next > 0; {
+			blocks, nextPage, err := e.GetScalarsRange(next, math.MaxInt32, 0, ports.UnspentScalars, 0)
+			if err != nil {
+				logrus.Error(err)
+				return
+			}
+
+			for _, block := range blocks {
+				filter, err := e.getTaprootFilter(block.Height)
+				if err != nil {
+					logrus.Errorf("[%d] unable to get taproot filter: %s", block.Height, err)
+					return
+				}
+
+				if !replay(ChainEvent{Type: BlockIndexed, Block: block, Filter: filter, Replayed: true}) {
+					return
+				}
+				last = block.Height
+			}
+
+			next = nextPage
+		}
+
+		forward := func(event ChainEvent) bool {
+			switch event.Type {
+			case BlockIndexed:
+				// already sent by the replay
+				if event.Block.Height <= last {
+					return true
+				}
+				last = event.Block.Height
+			case ChainReorg:
+				if event.ForkHeight < last {
+					last = event.ForkHeight
+				}
+			}
+
+			select {
+			case events <- event:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, event := range queued {
+			if !forward(event) {
+				return
+			}
+		}
+		queued = nil
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-live:
+				if !ok || !forward(event) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// getTaprootFilter returns the hex-encoded taproot filter stored with the block,
+// it is empty for the blocks indexed before the taproot filters were stored.
+func (e *silentium) getTaprootFilter(height int32) (string, error) {
+	filter, err := e.repo.GetTaprootFilter(height)
+	if err != nil {
+		if errors.As(err, &ports.ErrBlockNotFound{}) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return hex.EncodeToString(filter), nil
+}
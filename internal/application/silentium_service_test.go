@@ -0,0 +1,322 @@
+package application
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/gcs"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	badgerdb "github.com/louisinger/silentiumd/internal/infrastructure/db/badger"
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/inmemory"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetScalarsRange(t *testing.T) {
+	store, err := badgerdb.New("", nil)
+	require.NoError(t, err)
+
+	// 1 scalar per block up to 1500, then 2 blocks above the scalars cap
+	for height := int32(1); height <= 1500; height++ {
+		writeBlock(t, store, height, 1)
+	}
+	writeBlock(t, store, 1501, MaxRangeScalars-2)
+	writeBlock(t, store, 1502, 2)
+	writeBlock(t, store, 1503, 1)
+
+	svc := NewSilentiumService(store, nil, NewEventBus(), inmemory.NewMempoolRepository())
+
+	t.Run("invalid range", func(t *testing.T) {
+		_, _, err := svc.GetScalarsRange(10, 9, 0, ports.UnspentScalars, 0)
+		require.ErrorIs(t, err, ErrInvalidBlockRange)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		blocks, next, err := svc.GetScalarsRange(1, 100, 10, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 10)
+		require.Equal(t, int32(1), blocks[0].Height)
+		require.Equal(t, int32(10), blocks[9].Height)
+		require.Equal(t, uint32(11), next)
+	})
+
+	t.Run("max blocks", func(t *testing.T) {
+		blocks, next, err := svc.GetScalarsRange(1, 1500, 0, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, MaxRangeBlocks)
+		require.Equal(t, uint32(MaxRangeBlocks+1), next)
+
+		blocks, next, err = svc.GetScalarsRange(next, 1500, 0, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 500)
+		require.Equal(t, int32(1500), blocks[499].Height)
+		require.Equal(t, uint32(0), next)
+	})
+
+	t.Run("max scalars", func(t *testing.T) {
+		blocks, next, err := svc.GetScalarsRange(1500, 1503, 0, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 3)
+		require.Equal(t, int32(1502), blocks[2].Height)
+		require.Equal(t, uint32(1503), next)
+
+		blocks, next, err = svc.GetScalarsRange(next, 1503, 0, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		require.Equal(t, uint32(0), next)
+	})
+
+	t.Run("above tip", func(t *testing.T) {
+		blocks, next, err := svc.GetScalarsRange(1503, 5000, 0, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 1)
+		require.Equal(t, uint32(0), next)
+
+		blocks, next, err = svc.GetScalarsRange(2000, 5000, 0, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, blocks, 0)
+		require.Equal(t, uint32(0), next)
+	})
+}
+
+func TestSubscribeScalars(t *testing.T) {
+	chain := newFakeChain(t, 7)
+	s, store := newTestSyncer(t, chain)
+	svc := NewSilentiumService(store, chain, s.events, inmemory.NewMempoolRepository())
+
+	require.NoError(t, s.syncBlocks(1, 5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := svc.SubscribeScalars(ctx, 3)
+	require.NoError(t, err)
+
+	// replay
+	for height := int32(3); height <= 5; height++ {
+		requireBlockEvent(t, events, chain, height)
+	}
+
+	// new blocks
+	require.NoError(t, s.syncBlocks(6, 7))
+	requireBlockEvent(t, events, chain, 6)
+	requireBlockEvent(t, events, chain, 7)
+
+	// reorg
+	chain.fork(t, 5, 8)
+	newTip, err := chain.GetBlockByHeight(8)
+	require.NoError(t, err)
+	require.NoError(t, s.indexBlock(newTip))
+
+	event := receiveEvent(t, events)
+	require.Equal(t, ChainReorg, event.Type)
+	require.Equal(t, int32(5), event.ForkHeight)
+
+	for height := int32(6); height <= 8; height++ {
+		requireBlockEvent(t, events, chain, height)
+	}
+
+	cancel()
+	select {
+	case _, ok := <-events:
+		require.False(t, ok)
+	case <-time.After(5 * time.Second):
+		t.Fatal("subscription not closed")
+	}
+}
+
+func TestSubscribeScalarsSlowSubscriber(t *testing.T) {
+	chain := newFakeChain(t, 5+2*subscriberBufferSize)
+	s, store := newTestSyncer(t, chain)
+	svc := NewSilentiumService(store, chain, s.events, inmemory.NewMempoolRepository())
+
+	require.NoError(t, s.syncBlocks(1, 5))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := svc.SubscribeScalars(ctx, 1)
+	require.NoError(t, err)
+	requireBlockEvent(t, events, chain, 1)
+
+	// more blocks than the subscriber buffer are committed during the replay
+	tip := int32(5 + 2*subscriberBufferSize)
+	require.NoError(t, s.syncBlocks(6, tip))
+
+	for height := int32(2); height <= tip; height++ {
+		requireBlockEvent(t, events, chain, height)
+	}
+}
+
+func TestSubscribeScalarsWithoutTaprootFilter(t *testing.T) {
+	store, err := badgerdb.New("", nil)
+	require.NoError(t, err)
+
+	// a block indexed before the taproot filters were stored
+	require.NoError(t, store.Write(nil, domain.BlockHeader{Height: 1, Hash: chainhash.Hash{0x01}}, nil))
+
+	svc := NewSilentiumService(store, nil, NewEventBus(), inmemory.NewMempoolRepository())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := svc.SubscribeScalars(ctx, 1)
+	require.NoError(t, err)
+
+	event := receiveEvent(t, events)
+	require.Equal(t, int32(1), event.Block.Height)
+	require.True(t, event.Replayed)
+	require.Empty(t, event.Filter)
+}
+
+func TestGetTransactionsByHeight(t *testing.T) {
+	chain := newFakeChain(t, 2)
+	s, store := newTestSyncer(t, chain)
+	svc := NewSilentiumService(store, chain, s.events, inmemory.NewMempoolRepository())
+
+	require.NoError(t, s.syncBlocks(1, 2))
+
+	block, err := chain.GetBlockByHeight(2)
+	require.NoError(t, err)
+	tx := block.Transactions()[1]
+
+	txs, err := svc.GetTransactionsByHeight(2, ports.UnspentScalars, 0)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, *tx.Hash(), *txs[0].TxHash)
+	require.Equal(t, []domain.TaprootOutput{
+		{
+			Index: 0,
+			Key:   taprootScript[2:],
+			Value: tx.MsgTx().TxOut[0].Value,
+		},
+	}, txs[0].TaprootOutputs)
+
+	// spent outputs are filtered out
+	require.NoError(t, store.MarkSpent([]wire.OutPoint{{Hash: *tx.Hash(), Index: 0}}, 3))
+
+	txs, err = svc.GetTransactionsByHeight(2, ports.UnspentScalars, 0)
+	require.NoError(t, err)
+	require.Len(t, txs, 0)
+
+	// the all filter keeps the spent transactions and outputs
+	txs, err = svc.GetTransactionsByHeight(2, ports.AllScalars, 0)
+	require.NoError(t, err)
+	require.Len(t, txs, 1)
+	require.Equal(t, []domain.TaprootOutput{
+		{
+			Index:       0,
+			Spent:       true,
+			SpentHeight: 3,
+			Key:         taprootScript[2:],
+			Value:       tx.MsgTx().TxOut[0].Value,
+		},
+	}, txs[0].TaprootOutputs)
+
+	// the output is dust for a limit above its value
+	txs, err = svc.GetTransactionsByHeight(2, ports.AllScalars, tx.MsgTx().TxOut[0].Value+1)
+	require.NoError(t, err)
+	require.Len(t, txs, 0)
+}
+
+func TestGetMempoolScalarsDisabled(t *testing.T) {
+	store, err := badgerdb.New("", nil)
+	require.NoError(t, err)
+
+	svc := NewSilentiumService(store, nil, NewEventBus(), nil)
+
+	_, err = svc.GetMempoolScalars()
+	require.ErrorIs(t, err, ErrMempoolDisabled)
+}
+
+func TestGetBlockTaprootFilter(t *testing.T) {
+	chain := newFakeChain(t, 2)
+	s, store := newTestSyncer(t, chain)
+	svc := NewSilentiumService(store, chain, s.events, inmemory.NewMempoolRepository())
+
+	require.NoError(t, s.syncBlocks(1, 2))
+
+	block, err := chain.GetBlockByHeight(2)
+	require.NoError(t, err)
+
+	filterHex, blockhash, err := svc.GetBlockTaprootFilter(2)
+	require.NoError(t, err)
+	require.Equal(t, block.Hash().String(), blockhash)
+
+	filterBytes, err := hex.DecodeString(filterHex)
+	require.NoError(t, err)
+
+	filter, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM, filterBytes)
+	require.NoError(t, err)
+
+	match, err := filter.Match(builder.DeriveKey(block.Hash()), taprootScript[2:])
+	require.NoError(t, err)
+	require.True(t, match)
+
+	_, _, err = svc.GetBlockTaprootFilter(3)
+	require.ErrorAs(t, err, &ports.ErrBlockNotFound{})
+}
+
+func receiveEvent(t *testing.T, events <-chan ChainEvent) ChainEvent {
+	t.Helper()
+
+	select {
+	case event, ok := <-events:
+		require.True(t, ok)
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return ChainEvent{}
+	}
+}
+
+func requireBlockEvent(t *testing.T, events <-chan ChainEvent, chain *fakeChain, height int32) {
+	t.Helper()
+
+	block, err := chain.GetBlockByHeight(height)
+	require.NoError(t, err)
+
+	event := receiveEvent(t, events)
+	require.Equal(t, BlockIndexed, event.Type)
+	require.Equal(t, height, event.Block.Height)
+	require.Equal(t, *block.Hash(), event.Block.Hash)
+	require.Len(t, event.Block.Scalars, 1)
+
+	// the taproot filter of the block matches its output key
+	filterBytes, err := hex.DecodeString(event.Filter)
+	require.NoError(t, err)
+
+	filter, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM, filterBytes)
+	require.NoError(t, err)
+
+	match, err := filter.Match(builder.DeriveKey(block.Hash()), taprootScript[2:])
+	require.NoError(t, err)
+	require.True(t, match)
+}
+
+func writeBlock(t *testing.T, store ports.ScalarRepository, height int32, numOfScalars int) {
+	scalars := make([]*domain.SilentScalar, 0, numOfScalars)
+
+	for i := 0; i < numOfScalars; i++ {
+		var txHash chainhash.Hash
+		binary.BigEndian.PutUint32(txHash[:], uint32(height))
+		binary.BigEndian.PutUint32(txHash[4:], uint32(i))
+
+		scalars = append(scalars, &domain.SilentScalar{
+			TxHash:         &txHash,
+			Scalar:         txHash[:8],
+			TaprootOutputs: []domain.TaprootOutput{{Index: 0}},
+		})
+	}
+
+	var hash chainhash.Hash
+	binary.BigEndian.PutUint32(hash[:], uint32(height))
+
+	require.NoError(t, store.Write(scalars, domain.BlockHeader{Height: height, Hash: hash}, nil))
+}
@@ -0,0 +1,498 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/sirupsen/logrus"
+)
+
+// a snapshot dumps the indexed blocks in a backend agnostic format, it is used to bootstrap a new replica.
+// integers are big endian, byte fields are prefixed by their uint32 length:
+//
+//	header:  magic "SLNTSNAP" | version uint16 | genesis hash [32] | first height int32 | last height int32
+//	block:   0x01 | height int32 | hash [32] | prev hash [32] | taproot filter bytes | txs count uint32
+//	tx:      hash [32] | scalar bytes | outputs count uint32
+//	output:  index uint32 | key bytes | value int64 | spent height int32 (0 if unspent)
+//	trailer: 0x00 | sha256 of all the previous bytes [32]
+//
+// the blocks indexed before the hashes were stored have zero hashes.
+const (
+	snapshotMagic   = "SLNTSNAP"
+	snapshotVersion = uint16(1)
+
+	snapshotBlockRecord = byte(0x01)
+	snapshotEndRecord   = byte(0x00)
+
+	// maxSnapshotFieldSize bounds the byte fields so a corrupted length can't exhaust the memory
+	maxSnapshotFieldSize = 1 << 24
+
+	snapshotLogInterval = 10000
+)
+
+var (
+	ErrInvalidSnapshot  = errors.New("invalid snapshot")
+	ErrSnapshotChecksum = errors.New("snapshot checksum mismatch")
+	ErrNotEmptyStore    = errors.New("the repository must be empty to import a snapshot")
+)
+
+// SnapshotInfo describes a snapshot file.
+type SnapshotInfo struct {
+	Version     uint16
+	GenesisHash chainhash.Hash
+	FirstHeight int32
+	LastHeight  int32
+	Blocks      int
+}
+
+// ExportSnapshot writes the blocks indexed from the lowest indexed height to the tip,
+// the blocks indexed with a lower start height than the configured one are exported too.
+func ExportSnapshot(store ports.ScalarRepository, network chaincfg.Params, w io.Writer) (*SnapshotInfo, error) {
+	tip, err := store.GetLatestBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := firstIndexedHeight(store, tip)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &SnapshotInfo{
+		Version:     snapshotVersion,
+		GenesisHash: *network.GenesisHash,
+		FirstHeight: first,
+		LastHeight:  tip,
+	}
+
+	checksum := sha256.New()
+	enc := &snapshotEncoder{w: bufio.NewWriter(io.MultiWriter(w, checksum))}
+
+	enc.writeHeader(info)
+
+	firstHashed, err := store.GetFirstHashedHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	for height := info.FirstHeight; height <= tip; height++ {
+		header, err := store.GetBlockHeader(height)
+		if err != nil {
+			if !errors.As(err, &ports.ErrBlockNotFound{}) {
+				return nil, err
+			}
+
+			// the blocks indexed before the hashes were stored are exported with a zero hash
+			if firstHashed != 0 && height >= firstHashed {
+				continue
+			}
+			header = &domain.BlockHeader{Height: height}
+		}
+
+		taprootFilter, err := store.GetTaprootFilter(height)
+		if err != nil && !errors.As(err, &ports.ErrBlockNotFound{}) {
+			return nil, err
+		}
+
+		scalars, err := store.GetSilentScalars(height, ports.AllScalars, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		enc.writeBlock(*header, taprootFilter, scalars)
+		if enc.err != nil {
+			return nil, enc.err
+		}
+
+		info.Blocks++
+		if info.Blocks%snapshotLogInterval == 0 {
+			logrus.Infof("[%d] %d blocks exported", height, info.Blocks)
+		}
+	}
+
+	enc.writeByte(snapshotEndRecord)
+	if err := enc.flush(); err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(checksum.Sum(nil)); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// firstIndexedHeight returns the lowest height with an indexed block, tip + 1 if there is none.
+// the store doesn't keep it, the heights are scanned from the first block.
+func firstIndexedHeight(store ports.ScalarRepository, tip int32) (int32, error) {
+	for from := int32(1); from <= tip; from += rangeChunkSize {
+		to := min(from+rangeChunkSize-1, tip)
+
+		blocks, err := store.GetScalarsRange(from, to, ports.AllScalars, 0)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(blocks) > 0 {
+			return blocks[0].Height, nil
+		}
+	}
+
+	return tip + 1, nil
+}
+
+// ImportSnapshot verifies the snapshot checksum then writes its blocks to an empty repository.
+// blocks with a zero hash are written without header, as the blocks indexed before the hashes were stored.
+// the blocks are applied in order, so the outputs are written before the blocks spending them.
+// if the import fails, the repository must be emptied before trying again.
+func ImportSnapshot(store ports.ScalarRepository, network chaincfg.Params, r io.ReadSeeker) (*SnapshotInfo, error) {
+	info, err := ReadSnapshot(r, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.GenesisHash != *network.GenesisHash {
+		return nil, fmt.Errorf("%w: snapshot of another network (genesis %s)", ErrInvalidSnapshot, info.GenesisHash)
+	}
+
+	tip, err := store.GetLatestBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	if tip > 0 {
+		return nil, ErrNotEmptyStore
+	}
+
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	imported := 0
+	if _, err := ReadSnapshot(r, func(header domain.BlockHeader, taprootFilter []byte, scalars []*domain.SilentScalar) error {
+		// the block must only flag the outputs it spends, the ones spent by later blocks are marked once it is written
+		spentLater := make(map[int32][]wire.OutPoint)
+		for _, scalar := range scalars {
+			for i, out := range scalar.TaprootOutputs {
+				if out.SpentHeight > header.Height {
+					spentLater[out.SpentHeight] = append(spentLater[out.SpentHeight], wire.OutPoint{Hash: *scalar.TxHash, Index: out.Index})
+					scalar.TaprootOutputs[i].Spent = false
+					scalar.TaprootOutputs[i].SpentHeight = 0
+				}
+			}
+		}
+
+		if err := store.ApplyBlock(scalars, header, taprootFilter, nil); err != nil {
+			return err
+		}
+
+		for spentHeight, outpoints := range spentLater {
+			if err := store.MarkSpent(outpoints, spentHeight); err != nil {
+				return err
+			}
+		}
+
+		imported++
+		if imported%snapshotLogInterval == 0 {
+			logrus.Infof("[%d] %d blocks imported", header.Height, imported)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return info, nil
+}
+
+// ReadSnapshot decodes the snapshot and checks its checksum, blocks are passed in order to onBlock if not nil.
+// the outputs created and spent by the same block are flagged as spent.
+func ReadSnapshot(r io.Reader, onBlock func(header domain.BlockHeader, taprootFilter []byte, scalars []*domain.SilentScalar) error) (*SnapshotInfo, error) {
+	br := bufio.NewReader(r)
+	checksum := sha256.New()
+	dec := &snapshotDecoder{r: io.TeeReader(br, checksum)}
+
+	info := dec.readHeader()
+	if dec.err != nil {
+		return nil, dec.err
+	}
+
+	if info.Version != snapshotVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidSnapshot, info.Version)
+	}
+
+	prevHeight := info.FirstHeight - 1
+
+	for {
+		record := dec.readByte()
+		if dec.err != nil {
+			return nil, dec.err
+		}
+
+		if record == snapshotEndRecord {
+			break
+		}
+
+		if record != snapshotBlockRecord {
+			return nil, fmt.Errorf("%w: unknown record 0x%02x", ErrInvalidSnapshot, record)
+		}
+
+		header, taprootFilter, scalars := dec.readBlock()
+		if dec.err != nil {
+			return nil, dec.err
+		}
+
+		if header.Height <= prevHeight || header.Height > info.LastHeight {
+			return nil, fmt.Errorf("%w: block %d out of order", ErrInvalidSnapshot, header.Height)
+		}
+		prevHeight = header.Height
+
+		for _, scalar := range scalars {
+			for _, out := range scalar.TaprootOutputs {
+				if out.Spent && (out.SpentHeight < header.Height || out.SpentHeight > info.LastHeight) {
+					return nil, fmt.Errorf("%w: output %s:%d of block %d spent at height %d", ErrInvalidSnapshot, scalar.TxHash, out.Index, header.Height, out.SpentHeight)
+				}
+			}
+		}
+
+		info.Blocks++
+
+		// the blocks are passed before the checksum is verified, ImportSnapshot reads the file twice
+		if onBlock != nil {
+			if err := onBlock(header, taprootFilter, scalars); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var expected [sha256.Size]byte
+	if _, err := io.ReadFull(br, expected[:]); err != nil {
+		return nil, fmt.Errorf("%w: missing checksum", ErrInvalidSnapshot)
+	}
+
+	if !bytes.Equal(expected[:], checksum.Sum(nil)) {
+		return nil, ErrSnapshotChecksum
+	}
+
+	if _, err := br.ReadByte(); err != io.EOF {
+		return nil, fmt.Errorf("%w: unexpected data after checksum", ErrInvalidSnapshot)
+	}
+
+	return info, nil
+}
+
+// snapshotEncoder keeps the first write error, it is checked once per block.
+type snapshotEncoder struct {
+	w   *bufio.Writer
+	err error
+}
+
+func (e *snapshotEncoder) writeHeader(info *SnapshotInfo) {
+	e.write([]byte(snapshotMagic))
+	e.writeUint16(info.Version)
+	e.write(info.GenesisHash[:])
+	e.writeUint32(uint32(info.FirstHeight))
+	e.writeUint32(uint32(info.LastHeight))
+}
+
+// writeBlock sorts the transactions by hash and the outputs by index so a snapshot doesn't depend on the backend.
+func (e *snapshotEncoder) writeBlock(header domain.BlockHeader, taprootFilter []byte, scalars []*domain.SilentScalar) {
+	sort.Slice(scalars, func(i, j int) bool {
+		return bytes.Compare(scalars[i].TxHash[:], scalars[j].TxHash[:]) < 0
+	})
+
+	e.writeByte(snapshotBlockRecord)
+	e.writeUint32(uint32(header.Height))
+	e.write(header.Hash[:])
+	e.write(header.PrevHash[:])
+	e.writeBytes(taprootFilter)
+	e.writeUint32(uint32(len(scalars)))
+
+	for _, scalar := range scalars {
+		outputs := scalar.TaprootOutputs
+		sort.Slice(outputs, func(i, j int) bool {
+			return outputs[i].Index < outputs[j].Index
+		})
+
+		e.write(scalar.TxHash[:])
+		e.writeBytes(scalar.Scalar)
+		e.writeUint32(uint32(len(outputs)))
+
+		for _, out := range outputs {
+			spentHeight := out.SpentHeight
+			if !out.Spent {
+				spentHeight = 0
+			}
+
+			e.writeUint32(out.Index)
+			e.writeBytes(out.Key)
+			e.writeUint64(uint64(out.Value))
+			e.writeUint32(uint32(spentHeight))
+		}
+	}
+}
+
+func (e *snapshotEncoder) write(b []byte) {
+	if e.err == nil {
+		_, e.err = e.w.Write(b)
+	}
+}
+
+func (e *snapshotEncoder) writeByte(b byte) {
+	e.write([]byte{b})
+}
+
+func (e *snapshotEncoder) writeUint16(v uint16) {
+	e.write(binary.BigEndian.AppendUint16(nil, v))
+}
+
+func (e *snapshotEncoder) writeUint32(v uint32) {
+	e.write(binary.BigEndian.AppendUint32(nil, v))
+}
+
+func (e *snapshotEncoder) writeUint64(v uint64) {
+	e.write(binary.BigEndian.AppendUint64(nil, v))
+}
+
+func (e *snapshotEncoder) writeBytes(b []byte) {
+	e.writeUint32(uint32(len(b)))
+	e.write(b)
+}
+
+func (e *snapshotEncoder) flush() error {
+	if e.err != nil {
+		return e.err
+	}
+	return e.w.Flush()
+}
+
+// snapshotDecoder keeps the first read error, truncated snapshots are reported as invalid.
+type snapshotDecoder struct {
+	r   io.Reader
+	err error
+}
+
+func (d *snapshotDecoder) readHeader() *SnapshotInfo {
+	if magic := d.read(len(snapshotMagic)); d.err == nil && string(magic) != snapshotMagic {
+		d.err = fmt.Errorf("%w: not a snapshot file", ErrInvalidSnapshot)
+	}
+
+	info := &SnapshotInfo{Version: d.readUint16()}
+	copy(info.GenesisHash[:], d.read(chainhash.HashSize))
+	info.FirstHeight = int32(d.readUint32())
+	info.LastHeight = int32(d.readUint32())
+
+	return info
+}
+
+func (d *snapshotDecoder) readBlock() (domain.BlockHeader, []byte, []*domain.SilentScalar) {
+	var header domain.BlockHeader
+	header.Height = int32(d.readUint32())
+	copy(header.Hash[:], d.read(chainhash.HashSize))
+	copy(header.PrevHash[:], d.read(chainhash.HashSize))
+
+	taprootFilter := d.readBytes()
+	if len(taprootFilter) == 0 {
+		taprootFilter = nil
+	}
+
+	numOfTxs := d.readUint32()
+	scalars := make([]*domain.SilentScalar, 0)
+
+	for i := uint32(0); i < numOfTxs && d.err == nil; i++ {
+		var txHash chainhash.Hash
+		copy(txHash[:], d.read(chainhash.HashSize))
+
+		scalar := &domain.SilentScalar{
+			TxHash: &txHash,
+			Scalar: d.readBytes(),
+		}
+
+		numOfOutputs := d.readUint32()
+		for j := uint32(0); j < numOfOutputs && d.err == nil; j++ {
+			out := domain.TaprootOutput{
+				Index: d.readUint32(),
+				Key:   d.readBytes(),
+				Value: int64(d.readUint64()),
+			}
+
+			if len(out.Key) == 0 {
+				out.Key = nil
+			}
+
+			out.SpentHeight = int32(d.readUint32())
+			out.Spent = out.SpentHeight != 0
+
+			scalar.TaprootOutputs = append(scalar.TaprootOutputs, out)
+		}
+
+		scalars = append(scalars, scalar)
+	}
+
+	return header, taprootFilter, scalars
+}
+
+func (d *snapshotDecoder) read(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.err = fmt.Errorf("%w: %s", ErrInvalidSnapshot, err)
+		return nil
+	}
+
+	return b
+}
+
+func (d *snapshotDecoder) readByte() byte {
+	if b := d.read(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (d *snapshotDecoder) readUint16() uint16 {
+	if b := d.read(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (d *snapshotDecoder) readUint32() uint32 {
+	if b := d.read(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (d *snapshotDecoder) readUint64() uint64 {
+	if b := d.read(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (d *snapshotDecoder) readBytes() []byte {
+	n := d.readUint32()
+	if n > maxSnapshotFieldSize {
+		if d.err == nil {
+			d.err = fmt.Errorf("%w: field of %d bytes", ErrInvalidSnapshot, n)
+		}
+		return nil
+	}
+
+	return d.read(int(n))
+}
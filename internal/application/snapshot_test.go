@@ -0,0 +1,203 @@
+package application
+
+import (
+	"bytes"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	badgerdb "github.com/louisinger/silentiumd/internal/infrastructure/db/badger"
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/sqlite"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	chain := newFakeChain(t, 10)
+	s, store := newTestSyncer(t, chain)
+
+	require.NoError(t, s.syncBlocks(1, 10))
+
+	block, err := chain.GetBlockByHeight(4)
+	require.NoError(t, err)
+	spent := wire.OutPoint{Hash: *block.Transactions()[1].Hash(), Index: 0}
+	require.NoError(t, store.MarkSpent([]wire.OutPoint{spent}, 7))
+
+	var snapshot bytes.Buffer
+	info, err := ExportSnapshot(store, chaincfg.RegressionNetParams, &snapshot)
+	require.NoError(t, err)
+	require.Equal(t, int32(10), info.LastHeight)
+	require.Equal(t, 10, info.Blocks)
+
+	// a badger snapshot seeds a sqlite repository
+	replica, err := sqlite.New(filepath.Join(t.TempDir(), "replica.db"))
+	require.NoError(t, err)
+
+	imported, err := ImportSnapshot(replica, chaincfg.RegressionNetParams, bytes.NewReader(snapshot.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, info, imported)
+
+	requireSameBlocks(t, store, replica, 1, 10)
+
+	// the snapshot doesn't depend on the backend
+	var replicaSnapshot bytes.Buffer
+	_, err = ExportSnapshot(replica, chaincfg.RegressionNetParams, &replicaSnapshot)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Bytes(), replicaSnapshot.Bytes())
+
+	// the spends are imported with their height, rolling back the spending block unspends the output
+	require.NoError(t, replica.Rollback(6))
+	scalars, err := replica.GetScalars(4, ports.UnspentScalars, 0)
+	require.NoError(t, err)
+	require.Len(t, scalars, 1)
+
+	t.Run("not empty", func(t *testing.T) {
+		_, err := ImportSnapshot(replica, chaincfg.RegressionNetParams, bytes.NewReader(snapshot.Bytes()))
+		require.ErrorIs(t, err, ErrNotEmptyStore)
+	})
+
+	t.Run("other network", func(t *testing.T) {
+		_, err := ImportSnapshot(newEmptyStore(t), chaincfg.TestNet3Params, bytes.NewReader(snapshot.Bytes()))
+		require.ErrorIs(t, err, ErrInvalidSnapshot)
+	})
+
+	t.Run("corrupted", func(t *testing.T) {
+		corrupted := bytes.Clone(snapshot.Bytes())
+		corrupted[len(corrupted)/2] ^= 0x01
+
+		empty := newEmptyStore(t)
+		_, err := ImportSnapshot(empty, chaincfg.RegressionNetParams, bytes.NewReader(corrupted))
+		require.Error(t, err)
+
+		// nothing is written before the checksum is verified
+		tip, err := empty.GetLatestBlockHeight()
+		require.NoError(t, err)
+		require.Zero(t, tip)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := ReadSnapshot(bytes.NewReader(snapshot.Bytes()[:snapshot.Len()-1]), nil)
+		require.ErrorIs(t, err, ErrInvalidSnapshot)
+	})
+}
+
+func TestSnapshotLegacyBlocks(t *testing.T) {
+	chain := newFakeChain(t, 10)
+	s, store := newTestSyncer(t, chain)
+
+	// the blocks 1 to 3 were indexed before the hashes were stored
+	for height := int32(1); height <= 3; height++ {
+		block, err := chain.GetBlockByHeight(height)
+		require.NoError(t, err)
+		require.NoError(t, store.Write(s.computeBlock(block).scalars, domain.BlockHeader{Height: height}, nil))
+	}
+
+	require.NoError(t, s.syncBlocks(4, 10))
+
+	var snapshot bytes.Buffer
+	info, err := ExportSnapshot(store, chaincfg.RegressionNetParams, &snapshot)
+	require.NoError(t, err)
+	require.Equal(t, 10, info.Blocks)
+
+	replica, err := sqlite.New(filepath.Join(t.TempDir(), "replica.db"))
+	require.NoError(t, err)
+
+	_, err = ImportSnapshot(replica, chaincfg.RegressionNetParams, bytes.NewReader(snapshot.Bytes()))
+	require.NoError(t, err)
+
+	firstHashed, err := replica.GetFirstHashedHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(4), firstHashed)
+
+	for height := int32(1); height <= 3; height++ {
+		_, err := replica.GetBlockHeader(height)
+		require.ErrorAs(t, err, &ports.ErrBlockNotFound{})
+
+		expectedScalars, err := store.GetSilentScalars(height, ports.AllScalars, 0)
+		require.NoError(t, err)
+		require.NotEmpty(t, expectedScalars)
+		actualScalars, err := replica.GetSilentScalars(height, ports.AllScalars, 0)
+		require.NoError(t, err)
+		require.Equal(t, sortScalars(expectedScalars), sortScalars(actualScalars), "height %d", height)
+	}
+
+	requireSameBlocks(t, store, replica, 4, 10)
+
+	var replicaSnapshot bytes.Buffer
+	_, err = ExportSnapshot(replica, chaincfg.RegressionNetParams, &replicaSnapshot)
+	require.NoError(t, err)
+	require.Equal(t, snapshot.Bytes(), replicaSnapshot.Bytes())
+}
+
+func TestSnapshotFirstIndexedHeight(t *testing.T) {
+	chain := newFakeChain(t, 250)
+	s, store := newTestSyncer(t, chain)
+
+	// the index was built from a start height above the first chunk
+	require.NoError(t, s.syncBlocks(150, 250))
+
+	var snapshot bytes.Buffer
+	info, err := ExportSnapshot(store, chaincfg.RegressionNetParams, &snapshot)
+	require.NoError(t, err)
+	require.Equal(t, int32(150), info.FirstHeight)
+	require.Equal(t, int32(250), info.LastHeight)
+	require.Equal(t, 101, info.Blocks)
+
+	replica := newEmptyStore(t)
+	_, err = ImportSnapshot(replica, chaincfg.RegressionNetParams, bytes.NewReader(snapshot.Bytes()))
+	require.NoError(t, err)
+
+	requireSameBlocks(t, store, replica, 150, 250)
+
+	t.Run("empty", func(t *testing.T) {
+		info, err := ExportSnapshot(newEmptyStore(t), chaincfg.RegressionNetParams, &bytes.Buffer{})
+		require.NoError(t, err)
+		require.Zero(t, info.Blocks)
+	})
+}
+
+func requireSameBlocks(t *testing.T, expected, actual ports.ScalarRepository, from, to int32) {
+	t.Helper()
+
+	expectedTip, err := expected.GetLatestBlockHeight()
+	require.NoError(t, err)
+	actualTip, err := actual.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, expectedTip, actualTip)
+
+	for height := from; height <= to; height++ {
+		expectedHeader, err := expected.GetBlockHeader(height)
+		require.NoError(t, err)
+		actualHeader, err := actual.GetBlockHeader(height)
+		require.NoError(t, err)
+		require.Equal(t, expectedHeader, actualHeader)
+
+		expectedFilter, err := expected.GetTaprootFilter(height)
+		require.NoError(t, err)
+		actualFilter, err := actual.GetTaprootFilter(height)
+		require.NoError(t, err)
+		require.Equal(t, expectedFilter, actualFilter)
+
+		expectedScalars, err := expected.GetSilentScalars(height, ports.AllScalars, 0)
+		require.NoError(t, err)
+		actualScalars, err := actual.GetSilentScalars(height, ports.AllScalars, 0)
+		require.NoError(t, err)
+		require.Equal(t, sortScalars(expectedScalars), sortScalars(actualScalars), "height %d", height)
+	}
+}
+
+func sortScalars(scalars []*domain.SilentScalar) []*domain.SilentScalar {
+	sort.Slice(scalars, func(i, j int) bool {
+		return bytes.Compare(scalars[i].TxHash[:], scalars[j].TxHash[:]) < 0
+	})
+	return scalars
+}
+
+func newEmptyStore(t *testing.T) ports.ScalarRepository {
+	store, err := badgerdb.New("", nil)
+	require.NoError(t, err)
+	return store
+}
@@ -2,6 +2,11 @@ package application
 
 import (
 	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
@@ -23,21 +28,41 @@ var zeroHash, _ = chainhash.NewHashFromStr(
 type SyncerService interface {
 	Start() error
 	Stop() error
+	// Verify compares the indexed blocks in [from, to] with the chain source, see VerifyReport.
+	Verify(from, to int32, repair bool) (*VerifyReport, error)
 }
 
+const defaultSyncWorkers = 4
+
+var (
+	fetchRetryDelay = 5 * time.Second
+	// maxSubscribeRetryDelay bounds the backoff of the block subscription retries
+	maxSubscribeRetryDelay = 5 * time.Minute
+	errSyncStopped         = errors.New("sync stopped")
+)
+
 type syncer struct {
 	store       ports.ScalarRepository
 	chainsource ports.ChainSource
+	events      EventBus
+
+	// workers is the number of blocks fetched concurrently during the initial sync,
+	// it also bounds the number of scalars computed in parallel.
+	workers      int
+	computeSlots chan struct{}
+
+	stopSyncBlocks   chan struct{}
+	stopBlockWatcher chan struct{}
+	syncTaskDone     chan struct{}
+	startBlock       int32
+	// baseHeight is the configured start height, the blocks above it are indexed
+	baseHeight int32
+}
 
-	computeScalarsCh chan *btcutil.Block
-	updateUnspentsCh chan *btcutil.Block
-
-	stopUpdateUnspents      chan struct{}
-	stopComputeBlockScalars chan struct{}
-	stopSyncBlocks          chan struct{}
-	stopBlockWatcher        chan struct{}
-	syncTaskDone            chan struct{}
-	startBlock              int32
+// computedBlock is a fetched block with the scalars of its eligible transactions.
+type computedBlock struct {
+	block   *btcutil.Block
+	scalars []*domain.SilentScalar
 }
 
 func NewSyncerService(
@@ -45,8 +70,11 @@ func NewSyncerService(
 	chainsrc ports.ChainSource,
 	network chaincfg.Params,
 	startBlock int32,
+	workers int,
+	events EventBus,
 ) (SyncerService, error) {
-	start := startBlock
+	baseHeight := syncBaseHeight(network, startBlock)
+	start := baseHeight
 
 	latest, err := store.GetLatestBlockHeight()
 	if err != nil {
@@ -57,52 +85,40 @@ func NewSyncerService(
 		start = latest
 	}
 
-	// do not sync before taproot activation height
+	if workers <= 0 {
+		workers = defaultSyncWorkers
+	}
+
+	logrus.Infof("start block: %d, sync workers: %d", start, workers)
+
+	return &syncer{
+		store:        store,
+		chainsource:  chainsrc,
+		events:       events,
+		workers:      workers,
+		computeSlots: make(chan struct{}, workers),
+		startBlock:   int32(start),
+		baseHeight:   baseHeight,
+	}, nil
+}
+
+// syncBaseHeight returns the height the initial sync starts after.
+// blocks before taproot activation are never synced.
+func syncBaseHeight(network chaincfg.Params, startBlock int32) int32 {
 	if len(network.Deployments) > chaincfg.DeploymentTaproot {
 		taprootHeight := int32(network.Deployments[chaincfg.DeploymentTaproot].MinActivationHeight)
 
-		if taprootHeight > 0 && start < taprootHeight {
-			start = taprootHeight
+		if taprootHeight > 0 && startBlock < taprootHeight {
+			return taprootHeight
 		}
 	}
 
-	logrus.Infof("start block: %d", start)
-
-	return &syncer{store, chainsrc, nil, nil, nil, nil, nil, nil, nil, int32(start)}, nil
+	return startBlock
 }
 
 func (s *syncer) Start() error {
-	s.computeScalarsCh = make(chan *btcutil.Block)
-	s.updateUnspentsCh = make(chan *btcutil.Block)
-
 	s.stopBlockWatcher = make(chan struct{}, 1)
 	s.stopSyncBlocks = make(chan struct{}, 1)
-	s.stopUpdateUnspents = make(chan struct{}, 1)
-	s.stopComputeBlockScalars = make(chan struct{}, 1)
-
-	go func() {
-		for {
-			select {
-			case block := <-s.computeScalarsCh:
-				s.computeBlockScalars(block)
-			case <-s.stopComputeBlockScalars:
-				logrus.Info("stop compute block scalars")
-				return
-			}
-		}
-	}()
-
-	go func() {
-		for {
-			select {
-			case block := <-s.updateUnspentsCh:
-				s.updateUnspents(block)
-			case <-s.stopUpdateUnspents:
-				logrus.Info("stop update unspents")
-				return
-			}
-		}
-	}()
 
 	s.syncTaskDone = make(chan struct{}, 1)
 	go s.syncMissingBlocks()
@@ -113,18 +129,26 @@ func (s *syncer) Start() error {
 func (s *syncer) Stop() error {
 	s.stopBlockWatcher <- struct{}{}
 	s.stopSyncBlocks <- struct{}{}
-	s.stopComputeBlockScalars <- struct{}{}
-	s.stopUpdateUnspents <- struct{}{}
-
-	close(s.computeScalarsCh)
 	return nil
 }
 
+// syncMissingBlocks indexes the blocks up to the chain tip, then starts the block watcher.
+// the chain source requests and the commits are retried, the watcher is started even if the sync fails.
 func (s *syncer) syncMissingBlocks() {
+	defer func() { s.syncTaskDone <- struct{}{} }()
+
 	tipHeight, err := s.chainsource.GetChainTipHeight()
-	if err != nil {
-		logrus.Error(err)
-		return
+	for err != nil {
+		logrus.Warnf("unable to get chain tip: %s, retrying in %s", err, fetchRetryDelay)
+
+		select {
+		case <-s.stopSyncBlocks:
+			logrus.Info("stop sync blocks")
+			return
+		case <-time.After(fetchRetryDelay):
+		}
+
+		tipHeight, err = s.chainsource.GetChainTipHeight()
 	}
 
 	latestHeight, err := s.store.GetLatestBlockHeight()
@@ -141,30 +165,163 @@ func (s *syncer) syncMissingBlocks() {
 		logrus.Infof("latest block height: %d, tip height: %d", latestHeight, tipHeight)
 		logrus.Debugf("syncing %d blocks", tipHeight-latestHeight)
 
-		for i := int32(latestHeight + 1); i <= tipHeight; i++ {
-			block, err := s.chainsource.GetBlockByHeight(i)
-			if err != nil {
+		if err := s.syncBlocks(latestHeight+1, tipHeight); err != nil {
+			if errors.Is(err, errSyncStopped) {
+				logrus.Info("stop sync blocks")
+			} else {
 				logrus.Error(err)
-				continue
+			}
+		}
+	}
+}
+
+// syncBlocks indexes the blocks in [from, to] through a pipeline:
+// the workers fetch the blocks and compute their scalars concurrently,
+// while the results are committed to the store one by one in height order.
+// at most 2 * workers blocks are in flight at the same time.
+// the store tip only moves forward with the commits,
+// so a sync interrupted by a crash resumes after the last committed block.
+func (s *syncer) syncBlocks(from, to int32) error {
+	quit := make(chan struct{})
+	defer close(quit)
+
+	window := make(chan struct{}, 2*s.workers)
+	heights := make(chan int32)
+	results := make(chan *computedBlock)
+
+	go func() {
+		defer close(heights)
+
+		for height := from; height <= to; height++ {
+			select {
+			case window <- struct{}{}:
+			case <-quit:
+				return
 			}
 
 			select {
-			case <-s.stopSyncBlocks:
-				logrus.Info("stop sync blocks")
+			case heights <- height:
+			case <-quit:
 				return
-			default:
-				s.computeScalarsCh <- block
 			}
 		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for height := range heights {
+				computed, ok := s.fetchBlock(height, quit)
+				if !ok {
+					return
+				}
+
+				select {
+				case results <- computed:
+				case <-quit:
+					return
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	pending := make(map[int32]*computedBlock)
+	next := from
+
+	for next <= to {
+		select {
+		case <-s.stopSyncBlocks:
+			return errSyncStopped
+		case computed, ok := <-results:
+			if !ok {
+				return fmt.Errorf("sync pipeline closed before block %d", next)
+			}
+
+			pending[computed.block.Height()] = computed
+		}
+
+		for computed, ok := pending[next]; ok; computed, ok = pending[next] {
+			if err := s.commitWithRetry(computed); err != nil {
+				return err
+			}
+
+			delete(pending, next)
+			<-window
+
+			if next%1000 == 0 {
+				logrus.Infof("synced block %d/%d", next, to)
+			}
+
+			next++
+		}
 	}
 
-	s.syncTaskDone <- struct{}{}
+	return nil
 }
 
+// fetchBlock gets the block at the given height and computes its scalars,
+// the chain source request is retried until it succeeds or quit is closed.
+func (s *syncer) fetchBlock(height int32, quit <-chan struct{}) (*computedBlock, bool) {
+	for {
+		block, err := s.chainsource.GetBlockByHeight(height)
+		if err == nil {
+			return s.computeBlock(block), true
+		}
+
+		logrus.Warnf("[%d] unable to fetch block: %s, retrying in %s", height, err, fetchRetryDelay)
+
+		select {
+		case <-quit:
+			return nil, false
+		case <-time.After(fetchRetryDelay):
+		}
+	}
+}
+
+// commitWithRetry commits the block, the commit is retried until it succeeds or the sync is stopped.
+func (s *syncer) commitWithRetry(computed *computedBlock) error {
+	for {
+		err := s.commitBlock(computed)
+		if err == nil {
+			return nil
+		}
+
+		logrus.Warnf("[%d] unable to commit block: %s, retrying in %s", computed.block.Height(), err, fetchRetryDelay)
+
+		select {
+		case <-s.stopSyncBlocks:
+			return errSyncStopped
+		case <-time.After(fetchRetryDelay):
+		}
+	}
+}
+
+// blockWatcher indexes the new blocks sent by the chain source once the initial sync is done,
+// the subscription is retried with an exponential backoff until it succeeds or the watcher is stopped.
 func (s *syncer) blockWatcher() {
+	delay := fetchRetryDelay
+
 	blocksch, cancel, err := s.chainsource.SubscribeBlocks()
-	if err != nil {
-		return
+	for err != nil {
+		logrus.Warnf("unable to subscribe to blocks: %s, retrying in %s", err, delay)
+
+		select {
+		case <-s.stopBlockWatcher:
+			logrus.Info("stop block watcher")
+			return
+		case <-time.After(delay):
+		}
+
+		delay = min(2*delay, maxSubscribeRetryDelay)
+		blocksch, cancel, err = s.chainsource.SubscribeBlocks()
 	}
 
 	<-s.syncTaskDone
@@ -178,50 +335,193 @@ func (s *syncer) blockWatcher() {
 			return
 		case block := <-blocksch:
 			logrus.Infof("new block %d", block.Height())
-			s.computeScalarsCh <- block
+			if err := s.indexBlock(block); err != nil {
+				logrus.Error(err)
+			}
 		}
 	}
 }
 
-func (s *syncer) computeBlockScalars(block *btcutil.Block) {
+func (s *syncer) indexBlock(block *btcutil.Block) error {
+	return s.commitBlock(s.computeBlock(block))
+}
+
+// computeBlock computes the scalars of the eligible transactions in parallel,
+// the transactions whose scalar can't be computed are logged and skipped.
+func (s *syncer) computeBlock(block *btcutil.Block) *computedBlock {
+	computed, _ := s.computeBlockScalars(block, false)
+	return computed
+}
+
+// computeBlockScalars computes the scalars of the eligible transactions in parallel.
+// if failFast is set, it returns the first error instead of skipping the transaction.
+func (s *syncer) computeBlockScalars(block *btcutil.Block, failFast bool) (*computedBlock, error) {
 	txs := block.Transactions()
 
-	scalars := make([]*domain.SilentScalar, 0)
+	// resolve the prevouts of the whole block at once,
+	// fallback to one chain source request per input if not supported
+	prevoutGetter := s.chainsource.GetPrevoutScript
 
-	for _, tx := range txs {
-		if s.isSilentPaymentElligibleTx(tx) {
-			scalar, err := domain.NewSilentScalar(tx)
-			if err != nil {
-				logrus.Error(err)
-				continue
-			}
+	prevouts, err := s.chainsource.GetBlockPrevouts(block)
+	if err != nil {
+		if !errors.Is(err, ports.ErrPrevoutsNotSupported) {
+			logrus.Warnf("[%d] unable to fetch block prevouts: %s", block.Height(), err)
+		}
+	} else {
+		prevoutGetter = prevouts.GetPrevoutScript
+	}
 
-			if scalar == nil {
-				continue
-			}
+	computed := make([]*domain.SilentScalar, len(txs))
+	errs := make([]error, len(txs))
 
-			if err := scalar.ComputeScalar(s.chainsource.GetPrevoutScript); err != nil {
-				logrus.Error(err)
-				continue
+	var wg sync.WaitGroup
+	for i, tx := range txs {
+		if !isSilentPaymentElligibleTx(tx) {
+			continue
+		}
+
+		wg.Add(1)
+		s.computeSlots <- struct{}{}
+
+		go func(i int, tx *btcutil.Tx) {
+			defer func() {
+				<-s.computeSlots
+				wg.Done()
+			}()
+
+			computed[i], errs[i] = computeTxScalar(tx, prevoutGetter)
+		}(i, tx)
+	}
+	wg.Wait()
+
+	scalars := make([]*domain.SilentScalar, 0)
+	for i, scalar := range computed {
+		if errs[i] != nil {
+			if failFast {
+				return nil, fmt.Errorf("[%d] unable to compute scalar of %s: %w", block.Height(), txs[i].Hash(), errs[i])
 			}
-			if scalar.Scalar != nil {
-				scalars = append(scalars, scalar)
+
+			logrus.Errorf("unable to compute scalar of %s: %s", txs[i].Hash(), errs[i])
+			continue
+		}
+
+		if scalar != nil {
+			scalars = append(scalars, scalar)
+		}
+	}
+
+	logrus.Debugf("[%d] compute scalars done", block.Height())
+	return &computedBlock{block, scalars}, nil
+}
+
+// computeTxScalar returns nil if the transaction can't be used for silent payments.
+func computeTxScalar(
+	tx *btcutil.Tx,
+	prevoutGetter func(wire.OutPoint) ([]byte, error),
+) (*domain.SilentScalar, error) {
+	scalar, err := domain.NewSilentScalar(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if scalar == nil {
+		return nil, nil
+	}
+
+	if err := scalar.ComputeScalar(prevoutGetter); err != nil {
+		// the transactions without eligible inputs can't be used for silent payments
+		if errors.Is(err, domain.ErrNoEligibleInputs) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if scalar.Scalar == nil {
+		return nil, nil
+	}
+
+	return scalar, nil
+}
+
+// commitBlock writes the block scalars, the outputs it spends and the new tip with a single ApplyBlock,
+// if the process stops before, nothing is stored and the block is processed again on restart.
+func (s *syncer) commitBlock(computed *computedBlock) error {
+	block, scalars := computed.block, computed.scalars
+
+	alreadyIndexed, err := s.rollbackIfReorg(block)
+	if err != nil {
+		return err
+	}
+
+	if alreadyIndexed {
+		logrus.Debugf("[%d] block already indexed", block.Height())
+		return nil
+	}
+
+	spentOutpoints := getSpentOutpoints(block)
+
+	// the outputs created and spent in the same block are not stored yet
+	spentInBlock := make(map[wire.OutPoint]struct{}, len(spentOutpoints))
+	for _, outpoint := range spentOutpoints {
+		spentInBlock[outpoint] = struct{}{}
+	}
+
+	for _, scalar := range scalars {
+		for i, out := range scalar.TaprootOutputs {
+			if _, spent := spentInBlock[wire.OutPoint{Hash: *scalar.TxHash, Index: out.Index}]; spent {
+				scalar.TaprootOutputs[i].Spent = true
+				scalar.TaprootOutputs[i].SpentHeight = block.Height()
 			}
 		}
 	}
 
-	if err := s.store.Write(scalars, block.Height()); err != nil {
-		logrus.Error(err)
+	header := domain.BlockHeader{
+		Height:   block.Height(),
+		Hash:     *block.Hash(),
+		PrevHash: block.MsgBlock().Header.PrevBlock,
 	}
 
-	go func() {
-		s.updateUnspentsCh <- block
-	}()
+	taprootFilter, err := domain.NewTaprootFilter(header.Hash, scalars)
+	if err != nil {
+		return err
+	}
 
-	logrus.Debugf("[%d] compute scalars done", block.Height())
+	// the scalars, the spends and the new tip are committed at once so a crash can't leave a partial block
+	if err := s.store.ApplyBlock(scalars, header, taprootFilter, spentOutpoints); err != nil {
+		return err
+	}
+
+	s.events.Publish(ChainEvent{
+		Type: BlockIndexed,
+		Block: domain.BlockScalars{
+			Height:  header.Height,
+			Hash:    header.Hash,
+			Scalars: unspentScalars(scalars),
+		},
+		Filter: hex.EncodeToString(taprootFilter),
+	})
+
+	logrus.Debugf("[%d] block committed", block.Height())
+	return nil
+}
+
+// unspentScalars returns the hex-encoded scalars of the transactions with unspent taproot outputs.
+func unspentScalars(scalars []*domain.SilentScalar) []string {
+	result := make([]string, 0, len(scalars))
+
+	for _, scalar := range scalars {
+		for _, out := range scalar.TaprootOutputs {
+			if !out.Spent {
+				result = append(result, hex.EncodeToString(scalar.Scalar))
+				break
+			}
+		}
+	}
+
+	return result
 }
 
-func (s *syncer) updateUnspents(block *btcutil.Block) {
+func getSpentOutpoints(block *btcutil.Block) []wire.OutPoint {
 	spentOutpoints := make([]wire.OutPoint, 0)
 
 	for _, tx := range block.Transactions() {
@@ -234,18 +534,144 @@ func (s *syncer) updateUnspents(block *btcutil.Block) {
 		}
 	}
 
-	if len(spentOutpoints) > 0 {
-		if err := s.store.MarkSpent(spentOutpoints); err != nil {
-			logrus.Error(err)
+	return spentOutpoints
+}
+
+// rollbackIfReorg checks that the block extends the indexed chain.
+// if not, the chain has been reorganized: the store is rolled back down to the fork point
+// and the blocks of the new branch are indexed up to the block's parent.
+// it returns true if the block must not be indexed: it is already part of the indexed chain,
+// or it has been replaced by the block of the new branch at the same height.
+func (s *syncer) rollbackIfReorg(block *btcutil.Block) (bool, error) {
+	tip, err := s.store.GetLatestBlockHeight()
+	if err != nil {
+		return false, err
+	}
+
+	// an empty store or a block extending the tip only needs its parent to be checked,
+	// otherwise the indexed chain is verified and the missing blocks are indexed first
+	if tip == 0 || block.Height() == tip+1 {
+		parent, err := s.store.GetBlockHeader(block.Height() - 1)
+		if err != nil {
+			if !errors.As(err, &ports.ErrBlockNotFound{}) {
+				return false, err
+			}
+
+			// a missing parent above the legacy blocks is a hole left in the index
+			legacy, err := isLegacyBlock(s.store, block.Height()-1)
+			if err != nil {
+				return false, err
+			}
+
+			if legacy {
+				return false, nil
+			}
+		} else if parent.Hash.IsEqual(&block.MsgBlock().Header.PrevBlock) {
+			return false, nil
 		}
 	}
 
-	logrus.Debugf("[%d] update done", block.Height())
+	forkHeight, err := s.findForkHeight(tip)
+	if err != nil {
+		return false, err
+	}
+
+	if forkHeight >= block.Height() {
+		return true, nil
+	}
+
+	if forkHeight < tip {
+		logrus.Warnf("reorg detected at height %d, rolling back to %d", block.Height(), forkHeight)
+
+		if err := s.store.Rollback(forkHeight); err != nil {
+			return false, err
+		}
+
+		s.events.Publish(ChainEvent{Type: ChainReorg, ForkHeight: forkHeight})
+	}
+
+	for height := forkHeight + 1; height < block.Height(); height++ {
+		newBranchBlock, err := s.chainsource.GetBlockByHeight(height)
+		if err != nil {
+			return false, err
+		}
+
+		if err := s.indexBlock(newBranchBlock); err != nil {
+			return false, err
+		}
+	}
+
+	// the block itself may belong to the stale branch
+	hash, err := s.chainsource.GetBlockHash(block.Height())
+	if err != nil {
+		return false, err
+	}
+
+	if !hash.IsEqual(block.Hash()) {
+		newBranchBlock, err := s.chainsource.GetBlockByHeight(block.Height())
+		if err != nil {
+			return false, err
+		}
+
+		return true, s.indexBlock(newBranchBlock)
+	}
+
+	return false, nil
+}
+
+// findForkHeight walks down the indexed chain from the given height
+// and returns the first height where the stored block hash matches the chain source one.
+// a missing block is a mismatch unless it was indexed before the hashes were stored.
+func (s *syncer) findForkHeight(from int32) (int32, error) {
+	firstHashed, err := s.store.GetFirstHashedHeight()
+	if err != nil {
+		return 0, err
+	}
+
+	height := from
+
+	for ; height > 0; height-- {
+		// blocks indexed without hash can't be checked
+		if firstHashed == 0 || height < firstHashed {
+			return height, nil
+		}
+
+		stored, err := s.store.GetBlockHeader(height)
+		if err != nil {
+			if errors.As(err, &ports.ErrBlockNotFound{}) {
+				continue
+			}
+
+			return 0, err
+		}
+
+		hash, err := s.chainsource.GetBlockHash(height)
+		if err != nil {
+			return 0, err
+		}
+
+		if stored.Hash.IsEqual(hash) {
+			return height, nil
+		}
+	}
+
+	return height, nil
+}
+
+// isLegacyBlock returns true if the block at height is below the first block indexed with its hash,
+// it was indexed before the hashes were stored and can't be checked against the chain.
+func isLegacyBlock(store ports.ScalarRepository, height int32) (bool, error) {
+	firstHashed, err := store.GetFirstHashedHeight()
+	if err != nil {
+		return false, err
+	}
+
+	return firstHashed == 0 || height < firstHashed, nil
 }
 
 // isSilentPaymentElligibleTx checks if a transaction is eligible for silent payments.
 // it means that it must have at least 1 taproot output
-func (s *syncer) isSilentPaymentElligibleTx(tx *btcutil.Tx) bool {
+func isSilentPaymentElligibleTx(tx *btcutil.Tx) bool {
 	for _, txIn := range tx.MsgTx().TxIn {
 		// skip coinbase
 		if txIn.PreviousOutPoint.Hash.IsEqual(zeroHash) {
@@ -0,0 +1,481 @@
+package application
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	badgerdb "github.com/louisinger/silentiumd/internal/infrastructure/db/badger"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/stretchr/testify/require"
+)
+
+var taprootScript = append([]byte{0x51, 0x20}, make([]byte, 32)...)
+
+func TestSyncBlocks(t *testing.T) {
+	chain := newFakeChain(t, 50)
+	s, store := newTestSyncer(t, chain)
+
+	require.NoError(t, s.syncBlocks(1, 50))
+
+	tip, err := store.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(50), tip)
+
+	requireIndexed(t, store, chain, 1, 50)
+}
+
+func TestSyncBlocksResume(t *testing.T) {
+	chain := newFakeChain(t, 40)
+	s, store := newTestSyncer(t, chain)
+
+	// the chain source fails after block 20, the sync must be stopped
+	chain.failAbove(20)
+	go func() {
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); {
+			if tip, err := store.GetLatestBlockHeight(); err == nil && tip == 20 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		s.stopSyncBlocks <- struct{}{}
+	}()
+	require.ErrorIs(t, s.syncBlocks(1, 40), errSyncStopped)
+
+	tip, err := store.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(20), tip)
+
+	chain.failAbove(-1)
+	require.NoError(t, s.syncBlocks(tip+1, 40))
+	requireIndexed(t, store, chain, 1, 40)
+}
+
+func TestSyncMissingBlocksCommitRetry(t *testing.T) {
+	defaultDelay := fetchRetryDelay
+	fetchRetryDelay = 10 * time.Millisecond
+	defer func() { fetchRetryDelay = defaultDelay }()
+
+	chain := newFakeChain(t, 20)
+	s, store := newTestSyncer(t, chain)
+
+	// the commit of block 10 fails once, the sync must go on
+	s.store = &failingStore{ScalarRepository: store, failAt: 10}
+	s.syncTaskDone = make(chan struct{}, 1)
+
+	go s.syncMissingBlocks()
+
+	select {
+	case <-s.syncTaskDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("sync not done")
+	}
+
+	requireIndexed(t, store, chain, 1, 20)
+}
+
+func TestBlockWatcherSubscribeRetry(t *testing.T) {
+	defaultDelay := fetchRetryDelay
+	fetchRetryDelay = 10 * time.Millisecond
+	defer func() { fetchRetryDelay = defaultDelay }()
+
+	chain := newFakeChain(t, 10)
+	s, store := newTestSyncer(t, chain)
+	require.NoError(t, s.syncBlocks(1, 10))
+
+	// the subscription fails twice, the watcher must retry
+	chain.subscribeFailures = 2
+	chain.newBlocks = make(chan *btcutil.Block)
+
+	s.stopBlockWatcher = make(chan struct{}, 1)
+	s.syncTaskDone = make(chan struct{}, 1)
+	s.syncTaskDone <- struct{}{}
+
+	done := make(chan struct{})
+	go func() {
+		s.blockWatcher()
+		close(done)
+	}()
+
+	chain.fork(t, 10, 11)
+	newTip, err := chain.GetBlockByHeight(11)
+	require.NoError(t, err)
+
+	select {
+	case chain.newBlocks <- newTip:
+	case <-time.After(5 * time.Second):
+		t.Fatal("block watcher not subscribed")
+	}
+
+	s.stopBlockWatcher <- struct{}{}
+	<-done
+
+	requireIndexed(t, store, chain, 1, 11)
+	require.Equal(t, 3, chain.subscribeCalls)
+}
+
+func TestReorg(t *testing.T) {
+	chain := newFakeChain(t, 30)
+	s, store := newTestSyncer(t, chain)
+
+	require.NoError(t, s.syncBlocks(1, 30))
+
+	orphan, err := chain.GetBlockByHeight(28)
+	require.NoError(t, err)
+
+	// replace the last 5 blocks by a longer branch
+	chain.fork(t, 26, 33)
+
+	newTip, err := chain.GetBlockByHeight(33)
+	require.NoError(t, err)
+	require.NoError(t, s.indexBlock(newTip))
+
+	tip, err := store.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(33), tip)
+
+	requireIndexed(t, store, chain, 1, 33)
+
+	// the orphan block must not be indexed again
+	require.NoError(t, s.indexBlock(orphan))
+	requireIndexed(t, store, chain, 1, 33)
+}
+
+func TestReorgOverHole(t *testing.T) {
+	chain := newFakeChain(t, 30)
+	s, store := newTestSyncer(t, chain)
+
+	require.NoError(t, s.syncBlocks(1, 25))
+
+	// an interrupted rollback of older versions: the stale tip stays above deleted blocks
+	stale, err := chain.GetBlockByHeight(30)
+	require.NoError(t, err)
+	require.NoError(t, store.Write(nil, domain.BlockHeader{
+		Height:   stale.Height(),
+		Hash:     *stale.Hash(),
+		PrevHash: stale.MsgBlock().Header.PrevBlock,
+	}, nil))
+
+	chain.fork(t, 25, 31)
+
+	newTip, err := chain.GetBlockByHeight(31)
+	require.NoError(t, err)
+	require.NoError(t, s.indexBlock(newTip))
+
+	requireIndexed(t, store, chain, 1, 31)
+}
+
+func requireIndexed(t *testing.T, store ports.ScalarRepository, chain *fakeChain, from, to int32) {
+	t.Helper()
+
+	for height := from; height <= to; height++ {
+		block, err := chain.GetBlockByHeight(height)
+		require.NoError(t, err)
+
+		header, err := store.GetBlockHeader(height)
+		require.NoError(t, err)
+		require.Equal(t, *block.Hash(), header.Hash, "height %d", height)
+
+		scalars, err := store.GetScalars(height, ports.UnspentScalars, 0)
+		require.NoError(t, err)
+		require.Len(t, scalars, 1, "height %d", height)
+	}
+}
+
+func newTestSyncer(t *testing.T, chain *fakeChain) (*syncer, ports.ScalarRepository) {
+	store, err := badgerdb.New("", nil)
+	require.NoError(t, err)
+
+	svc, err := NewSyncerService(store, chain, chaincfg.RegressionNetParams, 0, 4, NewEventBus())
+	require.NoError(t, err)
+
+	s := svc.(*syncer)
+	s.stopSyncBlocks = make(chan struct{}, 1)
+	return s, store
+}
+
+// failingStore fails the first ApplyBlock of the block at failAt.
+type failingStore struct {
+	ports.ScalarRepository
+
+	mu     sync.Mutex
+	failAt int32
+}
+
+func (s *failingStore) ApplyBlock(scalars []*domain.SilentScalar, header domain.BlockHeader, taprootFilter []byte, spentOutpoints []wire.OutPoint) error {
+	s.mu.Lock()
+	fail := header.Height == s.failAt
+	if fail {
+		s.failAt = -1
+	}
+	s.mu.Unlock()
+
+	if fail {
+		return errors.New("apply block failed")
+	}
+
+	return s.ScalarRepository.ApplyBlock(scalars, header, taprootFilter, spentOutpoints)
+}
+
+// fakeChain is a ports.ChainSource serving generated blocks,
+// each block contains a coinbase and a transaction eligible to silent payments.
+type fakeChain struct {
+	mu      sync.Mutex
+	blocks  []*btcutil.Block
+	failAt  int32
+	mempool map[chainhash.Hash]*btcutil.Tx
+	spent   map[wire.OutPoint]struct{}
+
+	// prevoutsErr is returned by the block prevouts requests if set
+	prevoutsErr error
+	// subscribeFailures is the number of SubscribeBlocks calls failing before newBlocks is returned
+	subscribeFailures int
+	subscribeCalls    int
+	newBlocks         chan *btcutil.Block
+}
+
+func newFakeChain(t *testing.T, tip int32) *fakeChain {
+	chain := &fakeChain{
+		failAt:  -1,
+		mempool: make(map[chainhash.Hash]*btcutil.Tx),
+		spent:   make(map[wire.OutPoint]struct{}),
+	}
+	genesis := btcutil.NewBlock(chaincfg.RegressionNetParams.GenesisBlock)
+	genesis.SetHeight(0)
+	chain.blocks = []*btcutil.Block{genesis}
+
+	for height := int32(1); height <= tip; height++ {
+		chain.blocks = append(chain.blocks, newFakeBlock(t, chain.blocks[height-1], height))
+	}
+
+	return chain
+}
+
+func (c *fakeChain) fork(t *testing.T, forkHeight, tip int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.blocks = c.blocks[:forkHeight+1]
+	for height := forkHeight + 1; height <= tip; height++ {
+		c.blocks = append(c.blocks, newFakeBlock(t, c.blocks[height-1], height))
+	}
+}
+
+func (c *fakeChain) failAbove(height int32) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failAt = height
+}
+
+// spend removes the outpoint from the utxo set.
+func (c *fakeChain) spend(outpoint wire.OutPoint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spent[outpoint] = struct{}{}
+}
+
+func (c *fakeChain) setMempool(txs ...*wire.MsgTx) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.mempool = make(map[chainhash.Hash]*btcutil.Tx, len(txs))
+	for _, tx := range txs {
+		c.mempool[tx.TxHash()] = btcutil.NewTx(tx)
+	}
+}
+
+func (c *fakeChain) GetMempoolTxids() ([]chainhash.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	txids := make([]chainhash.Hash, 0, len(c.mempool))
+	for txid := range c.mempool {
+		txids = append(txids, txid)
+	}
+
+	return txids, nil
+}
+
+func (c *fakeChain) GetMempoolTx(txid chainhash.Hash) (*btcutil.Tx, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx, ok := c.mempool[txid]
+	if !ok {
+		return nil, errors.New("tx not found")
+	}
+
+	return tx, nil
+}
+
+func (c *fakeChain) GetMempoolPrevouts(tx *btcutil.Tx) (domain.PrevoutScripts, error) {
+	return p2wpkhPrevouts(tx), nil
+}
+
+func (c *fakeChain) GetBlockByHeight(height int32) (*btcutil.Block, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if height < 0 || int(height) >= len(c.blocks) || (c.failAt >= 0 && height > c.failAt) {
+		return nil, errors.New("block not found")
+	}
+
+	return c.blocks[height], nil
+}
+
+func (c *fakeChain) GetBlockHash(height int32) (*chainhash.Hash, error) {
+	block, err := c.GetBlockByHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return block.Hash(), nil
+}
+
+func (c *fakeChain) GetChainTipHeight() (int32, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return int32(len(c.blocks) - 1), nil
+}
+
+func (c *fakeChain) GetBlockPrevouts(block *btcutil.Block) (domain.PrevoutScripts, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.prevoutsErr != nil {
+		return nil, c.prevoutsErr
+	}
+
+	return p2wpkhPrevouts(block.Transactions()[1:]...), nil
+}
+
+func (c *fakeChain) GetPrevoutScript(wire.OutPoint) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeChain) SubscribeBlocks() (<-chan *btcutil.Block, func(), error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.subscribeCalls++
+	if c.newBlocks == nil || c.subscribeCalls <= c.subscribeFailures {
+		return nil, nil, errors.New("subscription failed")
+	}
+
+	return c.newBlocks, func() {}, nil
+}
+
+func (c *fakeChain) GetBlockFilterByHeight(height int32) (string, string, error) {
+	hash, err := c.GetBlockHash(height)
+	if err != nil {
+		return "", "", err
+	}
+
+	filter, err := c.GetBlockFilter(*hash)
+	if err != nil {
+		return "", "", err
+	}
+
+	return filter, hash.String(), nil
+}
+
+func (c *fakeChain) GetBlockFilter(hash chainhash.Hash) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, block := range c.blocks {
+		if block.Hash().IsEqual(&hash) {
+			filter, err := domain.NewBasicFilter(block.MsgBlock(), p2wpkhPrevouts(block.Transactions()[1:]...))
+			if err != nil {
+				return "", err
+			}
+
+			return hex.EncodeToString(filter), nil
+		}
+	}
+
+	return "", errors.New("block not found")
+}
+
+func (c *fakeChain) IsUtxo(outpoint wire.OutPoint) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, spent := c.spent[outpoint]
+	return !spent, nil
+}
+
+func newFakeBlock(t *testing.T, parent *btcutil.Block, height int32) *btcutil.Block {
+	coinbase := wire.NewMsgTx(2)
+	coinbase.AddTxIn(wire.NewTxIn(
+		&wire.OutPoint{Index: wire.MaxPrevOutIndex},
+		big.NewInt(int64(height)).Bytes(),
+		nil,
+	))
+	coinbase.AddTxOut(wire.NewTxOut(50, taprootScript))
+
+	block := wire.NewMsgBlock(wire.NewBlockHeader(1, parent.Hash(), &chainhash.Hash{}, 0, 0))
+	block.Header.Nonce = randomUint32(t)
+	require.NoError(t, block.AddTransaction(coinbase))
+	require.NoError(t, block.AddTransaction(newP2WPKHSpend(t)))
+
+	b := btcutil.NewBlock(block)
+	b.SetHeight(height)
+	return b
+}
+
+// p2wpkhPrevouts returns the P2WPKH scripts spent by the transactions, the key is the last witness item.
+func p2wpkhPrevouts(txs ...*btcutil.Tx) domain.PrevoutScripts {
+	prevouts := make(domain.PrevoutScripts)
+	for _, tx := range txs {
+		for _, txIn := range tx.MsgTx().TxIn {
+			if len(txIn.Witness) == 0 {
+				continue
+			}
+
+			pubkeyHash := btcutil.Hash160(txIn.Witness[len(txIn.Witness)-1])
+			prevouts[txIn.PreviousOutPoint] = append([]byte{txscript.OP_0, txscript.OP_DATA_20}, pubkeyHash...)
+		}
+	}
+
+	return prevouts
+}
+
+// newP2WPKHSpend returns a transaction spending a random P2WPKH outpoint to a taproot output.
+func newP2WPKHSpend(t *testing.T) *wire.MsgTx {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var prevTxid chainhash.Hash
+	_, err = rand.Read(prevTxid[:])
+	require.NoError(t, err)
+
+	signature := ecdsa.Sign(privKey, prevTxid[:]).Serialize()
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(
+		&wire.OutPoint{Hash: prevTxid, Index: 0},
+		nil,
+		wire.TxWitness{append(signature, 0x01), privKey.PubKey().SerializeCompressed()},
+	))
+	tx.AddTxOut(wire.NewTxOut(1000, taprootScript))
+	return tx
+}
+
+func randomUint32(t *testing.T) uint32 {
+	n, err := rand.Int(rand.Reader, big.NewInt(1<<32-1))
+	require.NoError(t, err)
+	return uint32(n.Int64())
+}
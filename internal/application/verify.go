@@ -0,0 +1,458 @@
+package application
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/sirupsen/logrus"
+)
+
+// MismatchType classifies the differences found between the index and the chain.
+type MismatchType int
+
+const (
+	// MissingBlock is a block of the range not indexed.
+	MissingBlock MismatchType = iota
+	// BlockHashMismatch is an indexed block replaced on chain, its content is not compared.
+	BlockHashMismatch
+	// MissingScalar is an eligible transaction of the block not indexed.
+	MissingScalar
+	// UnexpectedScalar is an indexed transaction not eligible or not included in the block.
+	UnexpectedScalar
+	// ScalarMismatch is an indexed scalar different from the recomputed one.
+	ScalarMismatch
+	// TaprootOutputsMismatch is a transaction indexed with other taproot outputs.
+	TaprootOutputsMismatch
+	// SpentMismatch is a taproot output with a spent flag different from the chain utxo set.
+	SpentMismatch
+)
+
+func (t MismatchType) String() string {
+	switch t {
+	case MissingBlock:
+		return "missing block"
+	case BlockHashMismatch:
+		return "block hash mismatch"
+	case MissingScalar:
+		return "missing scalar"
+	case UnexpectedScalar:
+		return "unexpected scalar"
+	case ScalarMismatch:
+		return "scalar mismatch"
+	case TaprootOutputsMismatch:
+		return "taproot outputs mismatch"
+	case SpentMismatch:
+		return "spent mismatch"
+	default:
+		return fmt.Sprintf("unknown mismatch (%d)", int(t))
+	}
+}
+
+// Mismatch is a difference between the index and the chain at Height.
+type Mismatch struct {
+	Type    MismatchType
+	Height  int32
+	TxHash  *chainhash.Hash
+	Details string
+}
+
+func (m Mismatch) String() string {
+	s := fmt.Sprintf("[%d] %s", m.Height, m.Type)
+	if m.TxHash != nil {
+		s += fmt.Sprintf(" %s", m.TxHash)
+	}
+	if m.Details != "" {
+		s += ": " + m.Details
+	}
+	return s
+}
+
+// VerifyReport is the result of Verify.
+//
+// the blocks are fetched from the chain source and their scalars recomputed,
+// verify fails if a scalar can't be computed, a chain source error isn't reported as a mismatch.
+// the blocks indexed before the hashes were stored are compared by their scalars only,
+// their transactions and outputs spent on chain may be missing: older versions pruned them.
+// the spent flags of the indexed taproot outputs are checked with ChainSource.IsUtxo.
+// if the index is behind the chain tip, the outputs spent by the blocks not indexed yet can't be told apart
+// from missed spends, so only the outputs wrongly flagged as spent are reported.
+//
+// the repair rolls the index back below the first block with a content mismatch,
+// the blocks above are indexed again by the syncer. then the spent flags of the remaining blocks are fixed:
+// the spending block is unknown, the outputs spent on chain are marked as spent by the index tip.
+type VerifyReport struct {
+	From       int32
+	To         int32
+	Blocks     int
+	Outputs    int
+	Mismatches []Mismatch
+	// Repaired is true if the mismatches have been repaired
+	Repaired bool
+	// RollbackHeight is the height the index has been rolled back to by the repair, -1 if not rolled back
+	RollbackHeight int32
+}
+
+func (s *syncer) Verify(from, to int32, repair bool) (*VerifyReport, error) {
+	storeTip, err := s.store.GetLatestBlockHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	chainTip, err := s.chainsource.GetChainTipHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	if from <= s.baseHeight {
+		from = s.baseHeight + 1
+	}
+
+	if to <= 0 || to > storeTip {
+		to = storeTip
+	}
+
+	if from > to {
+		return nil, fmt.Errorf("invalid range [%d, %d], index tip is %d", from, to, storeTip)
+	}
+
+	checkMissedSpends := storeTip >= chainTip
+	if !checkMissedSpends {
+		logrus.Warnf("index tip %d is behind chain tip %d, missed spends are not checked", storeTip, chainTip)
+	}
+
+	firstHashed, err := s.store.GetFirstHashedHeight()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VerifyReport{From: from, To: to, RollbackHeight: -1}
+
+	// the outputs to repair, by the height of the block including them
+	spentOnChain := make(map[int32][]wire.OutPoint)
+	unspentOnChain := make(map[int32][]wire.OutPoint)
+
+	for height := from; height <= to; height++ {
+		legacy := firstHashed == 0 || height < firstHashed
+
+		mismatches, outputs, err := s.verifyBlock(height, legacy, checkMissedSpends)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range mismatches {
+			report.Mismatches = append(report.Mismatches, m.Mismatch)
+
+			if m.outpoint != nil {
+				if m.spentOnChain {
+					spentOnChain[height] = append(spentOnChain[height], *m.outpoint)
+				} else {
+					unspentOnChain[height] = append(unspentOnChain[height], *m.outpoint)
+				}
+			}
+		}
+
+		report.Blocks++
+		report.Outputs += outputs
+
+		if report.Blocks%1000 == 0 {
+			logrus.Infof("[%d] %d blocks verified, %d mismatches", height, report.Blocks, len(report.Mismatches))
+		}
+	}
+
+	if !repair || len(report.Mismatches) == 0 {
+		return report, nil
+	}
+
+	if err := s.repair(report, spentOnChain, unspentOnChain); err != nil {
+		return report, err
+	}
+
+	report.Repaired = true
+	return report, nil
+}
+
+// outputMismatch is a SpentMismatch with the outpoint to repair.
+type outputMismatch struct {
+	Mismatch
+	outpoint     *wire.OutPoint
+	spentOnChain bool
+}
+
+// verifyBlock compares the indexed block with the chain source one,
+// it returns the mismatches and the number of checked taproot outputs.
+// the hash of a legacy block, indexed before the hashes were stored, is not checked.
+func (s *syncer) verifyBlock(height int32, legacy, checkMissedSpends bool) ([]outputMismatch, int, error) {
+	mismatches := make([]outputMismatch, 0)
+	addMismatch := func(t MismatchType, txHash *chainhash.Hash, details string, args ...interface{}) {
+		mismatches = append(mismatches, outputMismatch{
+			Mismatch: Mismatch{Type: t, Height: height, TxHash: txHash, Details: fmt.Sprintf(details, args...)},
+		})
+	}
+
+	header, err := s.store.GetBlockHeader(height)
+	if err != nil {
+		if !errors.As(err, &ports.ErrBlockNotFound{}) {
+			return nil, 0, err
+		}
+
+		if !legacy {
+			addMismatch(MissingBlock, nil, "")
+			return mismatches, 0, nil
+		}
+	}
+
+	block, err := s.chainsource.GetBlockByHeight(height)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if header != nil && header.Hash != *block.Hash() {
+		addMismatch(BlockHashMismatch, nil, "indexed %s, chain %s", header.Hash, block.Hash())
+		return mismatches, 0, nil
+	}
+
+	stored, err := s.store.GetSilentScalars(height, ports.AllScalars, 0)
+	if err != nil {
+		if !errors.As(err, &ports.ErrBlockNotFound{}) {
+			return nil, 0, err
+		}
+
+		// a legacy block may have been pruned entirely, its transactions are checked below
+		if !legacy {
+			addMismatch(MissingBlock, nil, "")
+			return mismatches, 0, nil
+		}
+	}
+
+	computed, err := s.computeBlockScalars(block, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	expected := make(map[chainhash.Hash]*domain.SilentScalar)
+	for _, scalar := range computed.scalars {
+		expected[*scalar.TxHash] = scalar
+	}
+
+	checkedOutputs := 0
+
+	for _, scalar := range stored {
+		txHash := scalar.TxHash
+
+		computed, ok := expected[*txHash]
+		if !ok {
+			addMismatch(UnexpectedScalar, txHash, "")
+			continue
+		}
+		delete(expected, *txHash)
+
+		if !bytes.Equal(scalar.Scalar, computed.Scalar) {
+			addMismatch(ScalarMismatch, txHash, "indexed %x, computed %x", scalar.Scalar, computed.Scalar)
+		}
+
+		computedOutputs := computed.TaprootOutputs
+		if legacy {
+			computedOutputs, err = s.withoutPrunedOutputs(*txHash, scalar.TaprootOutputs, computedOutputs)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+
+		if !sameTaprootOutputs(scalar.TaprootOutputs, computedOutputs) {
+			indexed, recomputed := outputIndexes(scalar.TaprootOutputs), outputIndexes(computedOutputs)
+			if fmt.Sprint(indexed) == fmt.Sprint(recomputed) {
+				addMismatch(TaprootOutputsMismatch, txHash, "different keys or values")
+			} else {
+				addMismatch(TaprootOutputsMismatch, txHash, "indexed %v, computed %v", indexed, recomputed)
+			}
+			continue
+		}
+
+		for _, out := range scalar.TaprootOutputs {
+			outpoint := wire.OutPoint{Hash: *txHash, Index: out.Index}
+
+			isUtxo, err := s.chainsource.IsUtxo(outpoint)
+			if err != nil {
+				return nil, 0, err
+			}
+			checkedOutputs++
+
+			if out.Spent == !isUtxo || (!isUtxo && !checkMissedSpends) {
+				continue
+			}
+
+			details := fmt.Sprintf("output %d spent at height %d, unspent on chain", out.Index, out.SpentHeight)
+			if !isUtxo {
+				details = fmt.Sprintf("output %d unspent, spent on chain", out.Index)
+			}
+
+			mismatches = append(mismatches, outputMismatch{
+				Mismatch:     Mismatch{Type: SpentMismatch, Height: height, TxHash: txHash, Details: details},
+				outpoint:     &outpoint,
+				spentOnChain: !isUtxo,
+			})
+		}
+	}
+
+	missing := make([]*domain.SilentScalar, 0, len(expected))
+	for _, scalar := range expected {
+		missing = append(missing, scalar)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return bytes.Compare(missing[i].TxHash[:], missing[j].TxHash[:]) < 0
+	})
+
+	for _, scalar := range missing {
+		if legacy {
+			pruned, err := s.isPruned(scalar)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			if pruned {
+				continue
+			}
+		}
+
+		addMismatch(MissingScalar, scalar.TxHash, "")
+	}
+
+	return mismatches, checkedOutputs, nil
+}
+
+// isPruned returns true if all the taproot outputs of the transaction are spent on chain,
+// older versions deleted such transactions from the legacy blocks (badger).
+func (s *syncer) isPruned(scalar *domain.SilentScalar) (bool, error) {
+	for _, out := range scalar.TaprootOutputs {
+		isUtxo, err := s.chainsource.IsUtxo(wire.OutPoint{Hash: *scalar.TxHash, Index: out.Index})
+		if err != nil {
+			return false, err
+		}
+
+		if isUtxo {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// withoutPrunedOutputs drops the computed outputs missing from the index and spent on chain,
+// older versions deleted the spent outputs of the legacy blocks (postgres).
+func (s *syncer) withoutPrunedOutputs(txHash chainhash.Hash, indexed, computed []domain.TaprootOutput) ([]domain.TaprootOutput, error) {
+	indexes := make(map[uint32]bool, len(indexed))
+	for _, out := range indexed {
+		indexes[out.Index] = true
+	}
+
+	kept := make([]domain.TaprootOutput, 0, len(computed))
+	for _, out := range computed {
+		if !indexes[out.Index] {
+			isUtxo, err := s.chainsource.IsUtxo(wire.OutPoint{Hash: txHash, Index: out.Index})
+			if err != nil {
+				return nil, err
+			}
+
+			if !isUtxo {
+				continue
+			}
+		}
+
+		kept = append(kept, out)
+	}
+
+	return kept, nil
+}
+
+func (s *syncer) repair(report *VerifyReport, spentOnChain, unspentOnChain map[int32][]wire.OutPoint) error {
+	rollbackHeight := int32(-1)
+	for _, m := range report.Mismatches {
+		if m.Type != SpentMismatch && (rollbackHeight < 0 || m.Height-1 < rollbackHeight) {
+			rollbackHeight = m.Height - 1
+		}
+	}
+
+	if rollbackHeight >= 0 {
+		logrus.Warnf("rolling back the index to height %d, the blocks above are indexed again on start", rollbackHeight)
+
+		if err := s.store.Rollback(rollbackHeight); err != nil {
+			return err
+		}
+
+		report.RollbackHeight = rollbackHeight
+	}
+
+	tip, err := s.store.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	var toSpend, toUnspend []wire.OutPoint
+	for height := report.From; height <= report.To; height++ {
+		if rollbackHeight >= 0 && height > rollbackHeight {
+			break
+		}
+
+		toSpend = append(toSpend, spentOnChain[height]...)
+		toUnspend = append(toUnspend, unspentOnChain[height]...)
+	}
+
+	if len(toUnspend) > 0 {
+		if err := s.store.MarkUnspent(toUnspend); err != nil {
+			return err
+		}
+	}
+
+	if len(toSpend) > 0 {
+		if err := s.store.MarkSpent(toSpend, tip); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sameTaprootOutputs compares the indexes, keys and values of the outputs.
+// keys and values are not compared for the outputs indexed before they were stored.
+func sameTaprootOutputs(indexed, computed []domain.TaprootOutput) bool {
+	if len(indexed) != len(computed) {
+		return false
+	}
+
+	byIndex := make(map[uint32]domain.TaprootOutput, len(computed))
+	for _, out := range computed {
+		byIndex[out.Index] = out
+	}
+
+	for _, out := range indexed {
+		expected, ok := byIndex[out.Index]
+		if !ok {
+			return false
+		}
+
+		if len(out.Key) == 0 {
+			continue
+		}
+
+		if !bytes.Equal(out.Key, expected.Key) || out.Value != expected.Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func outputIndexes(outputs []domain.TaprootOutput) []uint32 {
+	indexes := make([]uint32, 0, len(outputs))
+	for _, out := range outputs {
+		indexes = append(indexes, out.Index)
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i] < indexes[j] })
+	return indexes
+}
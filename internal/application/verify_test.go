@@ -0,0 +1,195 @@
+package application
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerify(t *testing.T) {
+	chain := newFakeChain(t, 10)
+	s, _ := newTestSyncer(t, chain)
+
+	require.NoError(t, s.syncBlocks(1, 10))
+
+	report, err := s.Verify(0, 0, false)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), report.From)
+	require.Equal(t, int32(10), report.To)
+	require.Equal(t, 10, report.Blocks)
+	require.Equal(t, 10, report.Outputs)
+	require.Empty(t, report.Mismatches)
+
+	t.Run("spent flags", func(t *testing.T) {
+		chain := newFakeChain(t, 10)
+		s, store := newTestSyncer(t, chain)
+		require.NoError(t, s.syncBlocks(1, 10))
+
+		// spent on chain but not in the index
+		chain.spend(wire.OutPoint{Hash: *chain.blocks[3].Transactions()[1].Hash(), Index: 0})
+		// spent in the index but not on chain
+		require.NoError(t, store.MarkSpent([]wire.OutPoint{{Hash: *chain.blocks[5].Transactions()[1].Hash(), Index: 0}}, 6))
+
+		report, err := s.Verify(1, 10, false)
+		require.NoError(t, err)
+		require.Len(t, report.Mismatches, 2)
+		for _, m := range report.Mismatches {
+			require.Equal(t, SpentMismatch, m.Type)
+		}
+		require.Equal(t, int32(3), report.Mismatches[0].Height)
+		require.Equal(t, int32(5), report.Mismatches[1].Height)
+		require.False(t, report.Repaired)
+
+		report, err = s.Verify(1, 10, true)
+		require.NoError(t, err)
+		require.True(t, report.Repaired)
+		require.Equal(t, int32(-1), report.RollbackHeight)
+
+		report, err = s.Verify(1, 10, false)
+		require.NoError(t, err)
+		require.Empty(t, report.Mismatches)
+	})
+
+	t.Run("blocks", func(t *testing.T) {
+		chain := newFakeChain(t, 10)
+		s, _ := newTestSyncer(t, chain)
+		require.NoError(t, s.syncBlocks(1, 10))
+
+		chain.fork(t, 7, 10)
+
+		report, err := s.Verify(1, 10, true)
+		require.NoError(t, err)
+		require.Len(t, report.Mismatches, 3)
+		for _, m := range report.Mismatches {
+			require.Equal(t, BlockHashMismatch, m.Type)
+		}
+		require.True(t, report.Repaired)
+		require.Equal(t, int32(7), report.RollbackHeight)
+
+		require.NoError(t, s.syncBlocks(8, 10))
+
+		report, err = s.Verify(1, 10, false)
+		require.NoError(t, err)
+		require.Empty(t, report.Mismatches)
+	})
+
+	t.Run("index behind the chain", func(t *testing.T) {
+		chain := newFakeChain(t, 10)
+		s, _ := newTestSyncer(t, chain)
+		require.NoError(t, s.syncBlocks(1, 8))
+
+		// may be spent by the blocks not indexed yet
+		chain.spend(wire.OutPoint{Hash: *chain.blocks[3].Transactions()[1].Hash(), Index: 0})
+
+		report, err := s.Verify(1, 0, false)
+		require.NoError(t, err)
+		require.Equal(t, int32(8), report.To)
+		require.Empty(t, report.Mismatches)
+	})
+	t.Run("legacy blocks", func(t *testing.T) {
+		chain := newFakeChain(t, 10)
+		s, store := newTestSyncer(t, chain)
+
+		// the blocks 1 to 3 were indexed before the hashes were stored, the block 2 misses the scalar of an unspent output
+		for height := int32(1); height <= 3; height++ {
+			block, err := chain.GetBlockByHeight(height)
+			require.NoError(t, err)
+
+			scalars := s.computeBlock(block).scalars
+			if height == 2 {
+				scalars = nil
+			}
+			require.NoError(t, store.Write(scalars, domain.BlockHeader{Height: height}, nil))
+		}
+		require.NoError(t, s.syncBlocks(4, 10))
+
+		report, err := s.Verify(1, 10, false)
+		require.NoError(t, err)
+		require.Equal(t, 10, report.Blocks)
+		require.Len(t, report.Mismatches, 1)
+		require.Equal(t, MissingScalar, report.Mismatches[0].Type)
+		require.Equal(t, int32(2), report.Mismatches[0].Height)
+	})
+
+	t.Run("pruned legacy blocks", func(t *testing.T) {
+		chain := newFakeChain(t, 10)
+		s, store := newTestSyncer(t, chain)
+
+		// the transaction of block 2 has a second taproot output
+		block2, err := chain.GetBlockByHeight(2)
+		require.NoError(t, err)
+		block2.MsgBlock().Transactions[1].AddTxOut(wire.NewTxOut(100, taprootScript))
+		chain.blocks[2] = btcutil.NewBlock(block2.MsgBlock())
+		chain.blocks[2].SetHeight(2)
+
+		// older versions pruned the spent outputs of the blocks 1 to 3, indexed before the hashes were stored:
+		// the transaction of block 1 is deleted, the block 2 one misses its spent output
+		// and the block 3 one is kept without outputs
+		for height := int32(1); height <= 3; height++ {
+			block, err := chain.GetBlockByHeight(height)
+			require.NoError(t, err)
+
+			txHash := block.Transactions()[1].Hash()
+			scalars := s.computeBlock(block).scalars
+			require.Len(t, scalars, 1)
+
+			switch height {
+			case 1:
+				chain.spend(wire.OutPoint{Hash: *txHash, Index: 0})
+				scalars = nil
+			case 2:
+				chain.spend(wire.OutPoint{Hash: *txHash, Index: 1})
+				scalars[0].TaprootOutputs = scalars[0].TaprootOutputs[:1]
+			case 3:
+				chain.spend(wire.OutPoint{Hash: *txHash, Index: 0})
+				scalars[0].TaprootOutputs = nil
+			}
+
+			require.NoError(t, store.Write(scalars, domain.BlockHeader{Height: height}, nil))
+		}
+		require.NoError(t, s.syncBlocks(4, 10))
+
+		report, err := s.Verify(1, 10, true)
+		require.NoError(t, err)
+		require.Equal(t, 10, report.Blocks)
+		require.Empty(t, report.Mismatches)
+		require.Equal(t, int32(-1), report.RollbackHeight)
+
+		tip, err := store.GetLatestBlockHeight()
+		require.NoError(t, err)
+		require.Equal(t, int32(10), tip)
+
+		// an unspent output missing from a legacy block is still reported
+		block2Tx := chain.blocks[2].Transactions()[1].Hash()
+		chain.mu.Lock()
+		delete(chain.spent, wire.OutPoint{Hash: *block2Tx, Index: 1})
+		chain.mu.Unlock()
+
+		report, err = s.Verify(1, 10, false)
+		require.NoError(t, err)
+		require.Len(t, report.Mismatches, 1)
+		require.Equal(t, TaprootOutputsMismatch, report.Mismatches[0].Type)
+		require.Equal(t, int32(2), report.Mismatches[0].Height)
+	})
+
+	t.Run("chain source error", func(t *testing.T) {
+		chain := newFakeChain(t, 10)
+		s, store := newTestSyncer(t, chain)
+		require.NoError(t, s.syncBlocks(1, 10))
+
+		// the fallback prevout requests fail too
+		chain.prevoutsErr = errors.New("prevouts not available")
+
+		_, err := s.Verify(1, 10, true)
+		require.Error(t, err)
+
+		// nothing is rolled back
+		tip, err := store.GetLatestBlockHeight()
+		require.NoError(t, err)
+		require.Equal(t, int32(10), tip)
+	})
+}
@@ -2,11 +2,16 @@ package config
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg"
 	badgerdb "github.com/louisinger/silentiumd/internal/infrastructure/db/badger"
 	"github.com/louisinger/silentiumd/internal/infrastructure/db/postgres"
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/sqlite"
+	"github.com/louisinger/silentiumd/internal/infrastructure/esplora"
 	"github.com/louisinger/silentiumd/internal/infrastructure/jsonrpc"
 	"github.com/louisinger/silentiumd/internal/ports"
 	"github.com/sirupsen/logrus"
@@ -17,26 +22,42 @@ const (
 	LogLevelKey    = "LOG_LEVEL"
 	NetworkKey     = "NETWORK"
 	StartHeightKey = "START_HEIGHT"
+	ChainSourceKey = "CHAIN_SOURCE"
+	EsploraURLKey  = "ESPLORA_URL"
+	SyncWorkersKey = "SYNC_WORKERS"
+	MempoolKey     = "MEMPOOL"
+	ScanServiceKey = "SCAN_SERVICE"
+	ScanKeyTTLKey  = "SCAN_KEY_TTL"
 	RpcCookiePath  = "RPC_COOKIE_PATH"
 	RpcUserKey     = "RPC_USER"
 	RpcPassKey     = "RPC_PASS"
 	RpcHostKey     = "RPC_HOST"
+	ZmqEndpointKey = "ZMQ_ENDPOINT"
 	PortKey        = "PORT"
 	NoTLSKey       = "NO_TLS"
 	CertFileKey    = "CERT_FILE"
 	KeyFileKey     = "KEY_FILE"
+	// WSAllowedOriginsKey is a comma separated list of origins
+	WSAllowedOriginsKey = "WS_ALLOWED_ORIGINS"
 
 	// db
 	DbTypeKey        = "DB_TYPE"
 	BadgerDatadirKey = "BADGER_DATADIR"
 	PostgresDSNKey   = "POSTGRES_DSN"
+	SqlitePathKey    = "SQLITE_PATH"
 )
 
 var (
 	defaultLogLevel    = 4 // logrus.InfoLevel
 	defaultDatadir     = btcutil.AppDataDir("silentiumd", false)
+	defaultSqlitePath  = filepath.Join(defaultDatadir, "silentium.db")
 	defaultNetwork     = "mainnet"
 	defaultStartHeight = int32(0)
+	defaultChainSource = "bitcoind"
+	defaultSyncWorkers = 4
+	defaultMempool     = false
+	defaultScanService = false
+	defaultScanKeyTTL  = 24 * time.Hour
 	defaultRpcHost     = "localhost:8332"
 	defaultPort        = uint32(9000)
 	defaultNoTLS       = false
@@ -45,19 +66,29 @@ var (
 type Config struct {
 	StartHeight   int32
 	ChainParams   chaincfg.Params
+	ChainSource   string
+	EsploraURL    string
+	SyncWorkers   int
+	Mempool       bool
+	ScanService   bool
+	ScanKeyTTL    time.Duration
 	RpcCookiePath string
 	RpcUser       string
 	RpcPass       string
 	RpcHost       string
+	ZmqEndpoint   string
 	LogLevel      logrus.Level
 	Port          uint32
 	NoTLS         bool
 	CertFileTLS   string
 	KeyFileTLS    string
+	// WSAllowedOrigins are the browser origins allowed on the websocket besides the same origin.
+	WSAllowedOrigins []string
 
 	DBType        string
 	BadgerDatadir string
 	PostgresDSN   string
+	SqlitePath    string
 }
 
 func Load() (*Config, error) {
@@ -66,9 +97,15 @@ func Load() (*Config, error) {
 
 	viper.SetDefault(LogLevelKey, defaultLogLevel)
 	viper.SetDefault(BadgerDatadirKey, defaultDatadir)
+	viper.SetDefault(SqlitePathKey, defaultSqlitePath)
 	viper.SetDefault(DbTypeKey, "badger")
 	viper.SetDefault(StartHeightKey, defaultStartHeight)
 	viper.SetDefault(NetworkKey, defaultNetwork)
+	viper.SetDefault(ChainSourceKey, defaultChainSource)
+	viper.SetDefault(SyncWorkersKey, defaultSyncWorkers)
+	viper.SetDefault(MempoolKey, defaultMempool)
+	viper.SetDefault(ScanServiceKey, defaultScanService)
+	viper.SetDefault(ScanKeyTTLKey, defaultScanKeyTTL)
 	viper.SetDefault(RpcHostKey, defaultRpcHost)
 	viper.SetDefault(PortKey, defaultPort)
 	viper.SetDefault(NoTLSKey, defaultNoTLS)
@@ -80,20 +117,29 @@ func Load() (*Config, error) {
 	}
 
 	cfg := &Config{
-		StartHeight:   viper.GetInt32(StartHeightKey),
-		RpcCookiePath: viper.GetString(RpcCookiePath),
-		RpcUser:       viper.GetString(RpcUserKey),
-		RpcPass:       viper.GetString(RpcPassKey),
-		LogLevel:      logrus.Level(viper.GetUint32(LogLevelKey)),
-		ChainParams:   chainParams,
-		RpcHost:       viper.GetString(RpcHostKey),
-		Port:          viper.GetUint32(PortKey),
-		DBType:        viper.GetString(DbTypeKey),
-		BadgerDatadir: viper.GetString(BadgerDatadirKey),
-		PostgresDSN:   viper.GetString(PostgresDSNKey),
-		NoTLS:         viper.GetBool(NoTLSKey),
-		CertFileTLS:   viper.GetString(CertFileKey),
-		KeyFileTLS:    viper.GetString(KeyFileKey),
+		StartHeight:      viper.GetInt32(StartHeightKey),
+		ChainSource:      viper.GetString(ChainSourceKey),
+		EsploraURL:       viper.GetString(EsploraURLKey),
+		SyncWorkers:      viper.GetInt(SyncWorkersKey),
+		Mempool:          viper.GetBool(MempoolKey),
+		ScanService:      viper.GetBool(ScanServiceKey),
+		ScanKeyTTL:       viper.GetDuration(ScanKeyTTLKey),
+		RpcCookiePath:    viper.GetString(RpcCookiePath),
+		RpcUser:          viper.GetString(RpcUserKey),
+		RpcPass:          viper.GetString(RpcPassKey),
+		LogLevel:         logrus.Level(viper.GetUint32(LogLevelKey)),
+		ChainParams:      chainParams,
+		RpcHost:          viper.GetString(RpcHostKey),
+		ZmqEndpoint:      viper.GetString(ZmqEndpointKey),
+		Port:             viper.GetUint32(PortKey),
+		DBType:           viper.GetString(DbTypeKey),
+		BadgerDatadir:    viper.GetString(BadgerDatadirKey),
+		PostgresDSN:      viper.GetString(PostgresDSNKey),
+		SqlitePath:       viper.GetString(SqlitePathKey),
+		NoTLS:            viper.GetBool(NoTLSKey),
+		CertFileTLS:      viper.GetString(CertFileKey),
+		KeyFileTLS:       viper.GetString(KeyFileKey),
+		WSAllowedOrigins: parseList(viper.GetString(WSAllowedOriginsKey)),
 	}
 
 	logrus.SetLevel(cfg.LogLevel)
@@ -110,15 +156,37 @@ func (c *Config) validate() error {
 		return fmt.Errorf("tls cert and key must be set")
 	}
 
-	if c.RpcCookiePath == "" {
-		if c.RpcUser == "" || c.RpcPass == "" {
-			return fmt.Errorf("rpc user and pass or cookie path must be set")
-		}
+	if c.SyncWorkers <= 0 {
+		return fmt.Errorf("sync workers must be greater than 0")
+	}
+
+	if c.ScanService && c.ScanKeyTTL <= 0 {
+		return fmt.Errorf("scan key ttl must be greater than 0")
+	}
+
+	// the clients of the scan service send their scan private key
+	if c.ScanService && c.NoTLS {
+		return fmt.Errorf("scan service requires tls")
+	}
+
+	switch c.ChainSource {
+	case "bitcoind":
+		if c.RpcCookiePath == "" {
+			if c.RpcUser == "" || c.RpcPass == "" {
+				return fmt.Errorf("rpc user and pass or cookie path must be set")
+			}
 
-		logrus.Warn("you're using rpc user and pass, consider using cookie file instead")
+			logrus.Warn("you're using rpc user and pass, consider using cookie file instead")
+		}
+	case "esplora":
+		if c.EsploraURL == "" {
+			return fmt.Errorf("esplora url must be set")
+		}
+	default:
+		return fmt.Errorf("unknown chain source: %s", c.ChainSource)
 	}
 
-	if c.DBType != "badger" && c.DBType != "postgres" {
+	if c.DBType != "badger" && c.DBType != "postgres" && c.DBType != "sqlite" {
 		return fmt.Errorf("unknown db type: %s", c.DBType)
 	}
 
@@ -130,6 +198,10 @@ func (c *Config) validate() error {
 		return fmt.Errorf("postgres dsn must be set")
 	}
 
+	if c.DBType == "sqlite" && c.SqlitePath == "" {
+		return fmt.Errorf("sqlite path must be set")
+	}
+
 	return nil
 }
 
@@ -139,17 +211,45 @@ func (c *Config) GetRepository() (ports.ScalarRepository, error) {
 		return badgerdb.New(c.BadgerDatadir, logrus.StandardLogger())
 	case "postgres":
 		return postgres.New(postgres.PostreSQLConfig{Dsn: c.PostgresDSN})
+	case "sqlite":
+		return sqlite.New(c.SqlitePath)
 	default:
 		return nil, fmt.Errorf("unknown db type: %s", c.DBType)
 	}
 }
 
+// Migrate applies the pending migrations of the database and returns the schema version,
+// only postgres databases are versioned.
+func (c *Config) Migrate() (int, error) {
+	if c.DBType != "postgres" {
+		return 0, fmt.Errorf("migrations are not supported by db type: %s", c.DBType)
+	}
+
+	return postgres.Migrate(postgres.PostreSQLConfig{Dsn: c.PostgresDSN})
+}
+
 func (c *Config) GetChainsource() (ports.ChainSource, error) {
+	if c.ChainSource == "esplora" {
+		return esplora.New(c.EsploraURL)
+	}
+
 	if c.RpcCookiePath == "" {
-		return jsonrpc.NewUnsafe(c.RpcHost, c.RpcUser, c.RpcPass)
+		return jsonrpc.NewUnsafe(c.RpcHost, c.RpcUser, c.RpcPass, c.ZmqEndpoint)
+	}
+
+	return jsonrpc.New(c.RpcHost, c.RpcCookiePath, c.ZmqEndpoint)
+}
+
+// parseList splits a comma separated list, the empty items are dropped.
+func parseList(list string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
 
-	return jsonrpc.New(c.RpcHost, c.RpcCookiePath)
+	return items
 }
 
 func toChainParams(network string) (chaincfg.Params, error) {
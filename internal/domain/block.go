@@ -0,0 +1,18 @@
+package domain
+
+import "github.com/btcsuite/btcd/chaincfg/chainhash"
+
+// BlockHeader identifies an indexed block and links it to its parent.
+// it is used to detect chain reorganizations.
+type BlockHeader struct {
+	Height   int32
+	Hash     chainhash.Hash
+	PrevHash chainhash.Hash
+}
+
+// BlockScalars groups the hex-encoded scalars of an indexed block.
+type BlockScalars struct {
+	Height  int32
+	Hash    chainhash.Hash
+	Scalars []string
+}
@@ -8,4 +8,9 @@ var (
 	ErrInvalidTaprootWitness         = errors.New("invalid taproot witness")
 	ErrInternalTaprootKeyIsBasePoint = errors.New("internal taproot key is unspendable")
 	ErrUnableToComputeScalar         = errors.New("unable to compute scalar")
+	ErrUncompressedPublicKey         = errors.New("uncompressed public key")
+	ErrInvalidControlBlock           = errors.New("invalid taproot control block")
+	ErrWitnessV2Plus                 = errors.New("input spends an output of segwit version 2 or above")
+	ErrNoEligibleInputs              = errors.New("no eligible inputs")
+	ErrPrevoutNotFound               = errors.New("prevout not found")
 )
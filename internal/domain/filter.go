@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"github.com/btcsuite/btcd/btcutil/gcs"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// NewTaprootFilter builds a GCS filter with the BIP158 parameters (P = 19, M = 784931)
+// over the x-only keys of the unspent taproot outputs.
+// like BIP158, the siphash key is the first 16 bytes of the block hash.
+func NewTaprootFilter(blockHash chainhash.Hash, scalars []*SilentScalar) ([]byte, error) {
+	keys := make([][]byte, 0, len(scalars))
+
+	for _, scalar := range scalars {
+		for _, out := range scalar.TaprootOutputs {
+			if out.Spent || len(out.Key) == 0 {
+				continue
+			}
+
+			keys = append(keys, out.Key)
+		}
+	}
+
+	filter, err := gcs.BuildGCSFilter(builder.DefaultP, builder.DefaultM, builder.DeriveKey(&blockHash), keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter.NBytes()
+}
+
+// NewBasicFilter builds the BIP158 basic filter of the block from the scripts of the outputs it spends.
+func NewBasicFilter(block *wire.MsgBlock, prevouts PrevoutScripts) ([]byte, error) {
+	prevOutScripts := make([][]byte, 0, len(prevouts))
+	for _, script := range prevouts {
+		prevOutScripts = append(prevOutScripts, script)
+	}
+
+	filter, err := builder.BuildBasicFilter(block, prevOutScripts)
+	if err != nil {
+		return nil, err
+	}
+
+	return filter.NBytes()
+}
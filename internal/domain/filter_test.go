@@ -0,0 +1,51 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/gcs"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTaprootFilter(t *testing.T) {
+	blockHash := chainhash.Hash{0x01}
+
+	unspentKey := chainhash.HashB([]byte("unspent"))
+	spentKey := chainhash.HashB([]byte("spent"))
+
+	filterBytes, err := domain.NewTaprootFilter(blockHash, []*domain.SilentScalar{
+		{
+			TaprootOutputs: []domain.TaprootOutput{
+				{Index: 0, Key: unspentKey},
+				{Index: 1, Key: spentKey, Spent: true},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	filter, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM, filterBytes)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), filter.N())
+
+	key := builder.DeriveKey(&blockHash)
+
+	match, err := filter.Match(key, unspentKey)
+	require.NoError(t, err)
+	require.True(t, match)
+
+	match, err = filter.Match(key, spentKey)
+	require.NoError(t, err)
+	require.False(t, match)
+
+	t.Run("empty", func(t *testing.T) {
+		filterBytes, err := domain.NewTaprootFilter(blockHash, nil)
+		require.NoError(t, err)
+
+		filter, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM, filterBytes)
+		require.NoError(t, err)
+		require.Equal(t, uint32(0), filter.N())
+	})
+}
@@ -0,0 +1,200 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+const uncompressedPubKeyLen = 65
+
+// num_h is the NUMS point used as unspendable taproot internal key, the script path spends using it are skipped.
+var num_h, _ = hex.DecodeString("50929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0")
+
+// InputType classifies the inputs by the type of the output they spend.
+type InputType int
+
+const (
+	// InputUnknown is returned if the prevout script can't be fetched.
+	InputUnknown InputType = iota
+	// InputNonStandard spends an output type not eligible to silent payments (P2PK, multisig, P2WSH...).
+	InputNonStandard
+	InputP2PKH
+	// InputP2SH is only eligible if it spends a P2SH-P2WPKH output.
+	InputP2SH
+	InputP2WPKH
+	InputP2TR
+	// InputWitnessV2Plus spends an output of segwit version 2 or above, its transaction can't be used for silent payments.
+	InputWitnessV2Plus
+)
+
+func (t InputType) String() string {
+	switch t {
+	case InputNonStandard:
+		return "non-standard"
+	case InputP2PKH:
+		return "p2pkh"
+	case InputP2SH:
+		return "p2sh"
+	case InputP2WPKH:
+		return "p2wpkh"
+	case InputP2TR:
+		return "p2tr"
+	case InputWitnessV2Plus:
+		return "witness-v2+"
+	default:
+		return "unknown"
+	}
+}
+
+// ClassifyInput returns the type of the output spent by the input and its public key if it is eligible to silent payments.
+// the public key is nil for the inputs not eligible, the error tells why:
+//   - ErrNonStandardScript for the output types not eligible and the P2SH not wrapping P2WPKH
+//   - ErrUncompressedPublicKey for the P2PKH, P2SH-P2WPKH and P2WPKH inputs revealing an uncompressed key
+//   - ErrInvalidTaprootWitness or ErrInvalidControlBlock for the malformed taproot witnesses
+//   - ErrInternalTaprootKeyIsBasePoint for the script path spends using the NUMS point as internal key
+//   - ErrWitnessV2Plus, the whole transaction is not eligible
+//
+// the error of getPrevout is returned with InputUnknown.
+func ClassifyInput(txIn *wire.TxIn, getPrevout func(wire.OutPoint) ([]byte, error)) (InputType, *btcec.PublicKey, error) {
+	prevoutScript, err := getPrevout(txIn.PreviousOutPoint)
+	if err != nil {
+		return InputUnknown, nil, err
+	}
+
+	switch {
+	case txscript.IsPayToPubKeyHash(prevoutScript):
+		pubkey, err := p2pkhPublicKey(txIn.SignatureScript, prevoutScript[3:23])
+		return InputP2PKH, pubkey, err
+	case txscript.IsPayToScriptHash(prevoutScript):
+		pubkey, err := p2shP2wpkhPublicKey(txIn)
+		return InputP2SH, pubkey, err
+	case txscript.IsPayToWitnessPubKeyHash(prevoutScript):
+		pubkey, err := witnessPublicKey(txIn.Witness)
+		return InputP2WPKH, pubkey, err
+	case txscript.IsPayToTaproot(prevoutScript):
+		pubkey, err := taprootPublicKey(txIn.Witness, prevoutScript[2:])
+		return InputP2TR, pubkey, err
+	case txscript.IsWitnessProgram(prevoutScript):
+		version, _, err := txscript.ExtractWitnessProgramInfo(prevoutScript)
+		if err == nil && version >= 2 {
+			return InputWitnessV2Plus, nil, ErrWitnessV2Plus
+		}
+	}
+
+	return InputNonStandard, nil, ErrNonStandardScript
+}
+
+// InputPublicKey returns the public key of an input eligible to silent payments,
+// an error if the input is not eligible, see ClassifyInput.
+func InputPublicKey(txIn *wire.TxIn, getPrevout func(wire.OutPoint) ([]byte, error)) (*btcec.PublicKey, error) {
+	_, pubkey, err := ClassifyInput(txIn, getPrevout)
+	return pubkey, err
+}
+
+// getInputPublicKeys returns the public keys of the eligible inputs.
+// it fails if a prevout is missing or if an input spends a segwit v2+ output.
+func getInputPublicKeys(
+	txIn []*wire.TxIn,
+	getPrevoutScript func(wire.OutPoint) ([]byte, error),
+) ([]*btcec.PublicKey, error) {
+	publicKeys := make([]*btcec.PublicKey, 0, len(txIn))
+
+	for _, in := range txIn {
+		inputType, pubkey, err := ClassifyInput(in, getPrevoutScript)
+		switch inputType {
+		case InputUnknown:
+			return nil, err
+		case InputWitnessV2Plus:
+			return nil, fmt.Errorf("%w: %w", ErrNoEligibleInputs, err)
+		}
+
+		if pubkey != nil {
+			publicKeys = append(publicKeys, pubkey)
+		}
+	}
+
+	return publicKeys, nil
+}
+
+// p2pkhPublicKey looks for the compressed key hashing to pubkeyHash in the script sig,
+// from the end as the script sig may be malleated.
+func p2pkhPublicKey(sigScript, pubkeyHash []byte) (*btcec.PublicKey, error) {
+	for i := len(sigScript); i >= btcec.PubKeyBytesLenCompressed; i-- {
+		pubkey := sigScript[i-btcec.PubKeyBytesLenCompressed : i]
+		if bytes.Equal(pubkeyHash, btcutil.Hash160(pubkey)) {
+			return btcec.ParsePubKey(pubkey)
+		}
+	}
+
+	for i := len(sigScript); i >= uncompressedPubKeyLen; i-- {
+		pubkey := sigScript[i-uncompressedPubKeyLen : i]
+		if bytes.Equal(pubkeyHash, btcutil.Hash160(pubkey)) {
+			return nil, ErrUncompressedPublicKey
+		}
+	}
+
+	return nil, ErrNonStandardScript
+}
+
+// p2shP2wpkhPublicKey returns the key of a P2SH-P2WPKH input,
+// its script sig is a single push of the P2WPKH redeem script.
+func p2shP2wpkhPublicKey(txIn *wire.TxIn) (*btcec.PublicKey, error) {
+	sigScript := txIn.SignatureScript
+	if len(sigScript) != 23 || sigScript[0] != txscript.OP_DATA_22 || !txscript.IsPayToWitnessPubKeyHash(sigScript[1:]) {
+		return nil, ErrNonStandardScript
+	}
+
+	return witnessPublicKey(txIn.Witness)
+}
+
+// witnessPublicKey returns the key of a P2WPKH witness, its last item.
+func witnessPublicKey(witness wire.TxWitness) (*btcec.PublicKey, error) {
+	if len(witness) == 0 {
+		return nil, ErrNonStandardScript
+	}
+
+	pubkey := witness[len(witness)-1]
+	switch len(pubkey) {
+	case btcec.PubKeyBytesLenCompressed:
+		return btcec.ParsePubKey(pubkey)
+	case uncompressedPubKeyLen:
+		return nil, ErrUncompressedPublicKey
+	default:
+		return nil, ErrNonStandardScript
+	}
+}
+
+// taprootPublicKey returns the output key of a taproot input,
+// unless it is a script path spend with the NUMS point as internal key.
+func taprootPublicKey(witness wire.TxWitness, outputKey []byte) (*btcec.PublicKey, error) {
+	if len(witness) == 0 {
+		return nil, ErrInvalidTaprootWitness
+	}
+
+	// remove the annex
+	if len(witness) > 1 && len(witness[len(witness)-1]) > 0 && witness[len(witness)-1][0] == txscript.TaprootAnnexTag {
+		witness = witness[:len(witness)-1]
+	}
+
+	// script path spend
+	if len(witness) > 1 {
+		controlBlock := witness[len(witness)-1]
+		if len(controlBlock) < txscript.ControlBlockBaseSize ||
+			(len(controlBlock)-txscript.ControlBlockBaseSize)%txscript.ControlBlockNodeSize != 0 {
+			return nil, ErrInvalidControlBlock
+		}
+
+		if bytes.Equal(controlBlock[1:txscript.ControlBlockBaseSize], num_h) {
+			return nil, ErrInternalTaprootKeyIsBasePoint
+		}
+	}
+
+	return schnorr.ParsePubKey(outputKey)
+}
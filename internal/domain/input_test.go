@@ -0,0 +1,317 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+var nums = []byte{
+	0x50, 0x92, 0x9b, 0x74, 0xc1, 0xa0, 0x49, 0x54, 0xb7, 0x8b, 0x4b, 0x60, 0x35, 0xe9, 0x7a, 0x5e,
+	0x07, 0x8a, 0x5a, 0x0f, 0x28, 0xec, 0x96, 0xd5, 0x47, 0xbf, 0xee, 0x9a, 0xce, 0x80, 0x3a, 0xc0,
+}
+
+func TestClassifyInput(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	pubKey := privKey.PubKey()
+	compressed := pubKey.SerializeCompressed()
+	uncompressed := pubKey.SerializeUncompressed()
+	signature := make([]byte, 72)
+
+	p2pkh := func(pubkey []byte) []byte {
+		return buildScript(t, txscript.NewScriptBuilder().
+			AddOp(txscript.OP_DUP).AddOp(txscript.OP_HASH160).AddData(btcutil.Hash160(pubkey)).
+			AddOp(txscript.OP_EQUALVERIFY).AddOp(txscript.OP_CHECKSIG))
+	}
+	p2wpkh := func(pubkey []byte) []byte {
+		return buildScript(t, txscript.NewScriptBuilder().AddOp(txscript.OP_0).AddData(btcutil.Hash160(pubkey)))
+	}
+	p2sh := func(redeemScript []byte) []byte {
+		return buildScript(t, txscript.NewScriptBuilder().
+			AddOp(txscript.OP_HASH160).AddData(btcutil.Hash160(redeemScript)).AddOp(txscript.OP_EQUAL))
+	}
+	pushes := func(data ...[]byte) []byte {
+		builder := txscript.NewScriptBuilder()
+		for _, d := range data {
+			builder.AddData(d)
+		}
+		return buildScript(t, builder)
+	}
+
+	p2tr, err := txscript.PayToTaprootScript(pubKey)
+	require.NoError(t, err)
+	outputKey, err := schnorr.ParsePubKey(p2tr[2:])
+	require.NoError(t, err)
+
+	multisig := buildScript(t, txscript.NewScriptBuilder().
+		AddOp(txscript.OP_1).AddData(compressed).AddOp(txscript.OP_1).AddOp(txscript.OP_CHECKMULTISIG))
+	witnessV2 := append([]byte{txscript.OP_2, txscript.OP_DATA_32}, make([]byte, 32)...)
+	p2wsh := append([]byte{txscript.OP_0, txscript.OP_DATA_32}, make([]byte, 32)...)
+	tapscript := []byte{txscript.OP_TRUE}
+	controlBlock := append([]byte{0xc0}, schnorr.SerializePubKey(pubKey)...)
+
+	testCases := []struct {
+		name      string
+		prevout   []byte
+		sigScript []byte
+		witness   wire.TxWitness
+		inputType domain.InputType
+		pubKey    *btcec.PublicKey
+		err       error
+	}{
+		{
+			name:      "p2pkh",
+			prevout:   p2pkh(compressed),
+			sigScript: pushes(signature, compressed),
+			inputType: domain.InputP2PKH,
+			pubKey:    pubKey,
+		},
+		{
+			name:      "p2pkh uncompressed",
+			prevout:   p2pkh(uncompressed),
+			sigScript: pushes(signature, uncompressed),
+			inputType: domain.InputP2PKH,
+			err:       domain.ErrUncompressedPublicKey,
+		},
+		{
+			name:      "p2pkh without key",
+			prevout:   p2pkh(compressed),
+			sigScript: pushes(signature),
+			inputType: domain.InputP2PKH,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "p2pkh with p2sh-p2wpkh script sig",
+			prevout:   p2pkh(compressed),
+			sigScript: pushes(p2wpkh(compressed)),
+			witness:   wire.TxWitness{signature, compressed},
+			inputType: domain.InputP2PKH,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "p2sh-p2wpkh",
+			prevout:   p2sh(p2wpkh(compressed)),
+			sigScript: pushes(p2wpkh(compressed)),
+			witness:   wire.TxWitness{signature, compressed},
+			inputType: domain.InputP2SH,
+			pubKey:    pubKey,
+		},
+		{
+			name:      "p2sh-p2wpkh uncompressed",
+			prevout:   p2sh(p2wpkh(uncompressed)),
+			sigScript: pushes(p2wpkh(uncompressed)),
+			witness:   wire.TxWitness{signature, uncompressed},
+			inputType: domain.InputP2SH,
+			err:       domain.ErrUncompressedPublicKey,
+		},
+		{
+			name:      "p2sh-p2wpkh with extra push",
+			prevout:   p2sh(p2wpkh(compressed)),
+			sigScript: pushes(signature, p2wpkh(compressed)),
+			witness:   wire.TxWitness{signature, compressed},
+			inputType: domain.InputP2SH,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "p2sh multisig",
+			prevout:   p2sh(multisig),
+			sigScript: append([]byte{txscript.OP_0}, pushes(signature, multisig)...),
+			inputType: domain.InputP2SH,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "p2sh empty script sig",
+			prevout:   p2sh(p2wpkh(compressed)),
+			witness:   wire.TxWitness{signature, compressed},
+			inputType: domain.InputP2SH,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "p2wpkh",
+			prevout:   p2wpkh(compressed),
+			witness:   wire.TxWitness{signature, compressed},
+			inputType: domain.InputP2WPKH,
+			pubKey:    pubKey,
+		},
+		{
+			name:      "p2wpkh uncompressed",
+			prevout:   p2wpkh(uncompressed),
+			witness:   wire.TxWitness{signature, uncompressed},
+			inputType: domain.InputP2WPKH,
+			err:       domain.ErrUncompressedPublicKey,
+		},
+		{
+			name:      "p2wpkh empty witness",
+			prevout:   p2wpkh(compressed),
+			inputType: domain.InputP2WPKH,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "p2tr key path",
+			prevout:   p2tr,
+			witness:   wire.TxWitness{make([]byte, 64)},
+			inputType: domain.InputP2TR,
+			pubKey:    outputKey,
+		},
+		{
+			name:      "p2tr key path with annex",
+			prevout:   p2tr,
+			witness:   wire.TxWitness{make([]byte, 64), {txscript.TaprootAnnexTag, 0x01}},
+			inputType: domain.InputP2TR,
+			pubKey:    outputKey,
+		},
+		{
+			name:      "p2tr script path",
+			prevout:   p2tr,
+			witness:   wire.TxWitness{tapscript, controlBlock},
+			inputType: domain.InputP2TR,
+			pubKey:    outputKey,
+		},
+		{
+			name:      "p2tr script path with nums internal key",
+			prevout:   p2tr,
+			witness:   wire.TxWitness{tapscript, append([]byte{0xc0}, nums...)},
+			inputType: domain.InputP2TR,
+			err:       domain.ErrInternalTaprootKeyIsBasePoint,
+		},
+		{
+			name:      "p2tr short control block",
+			prevout:   p2tr,
+			witness:   wire.TxWitness{tapscript, {0xc0, 0x01}},
+			inputType: domain.InputP2TR,
+			err:       domain.ErrInvalidControlBlock,
+		},
+		{
+			name:      "p2tr invalid control block length",
+			prevout:   p2tr,
+			witness:   wire.TxWitness{tapscript, append(controlBlock, 0x01)},
+			inputType: domain.InputP2TR,
+			err:       domain.ErrInvalidControlBlock,
+		},
+		{
+			name:      "p2tr empty witness",
+			prevout:   p2tr,
+			inputType: domain.InputP2TR,
+			err:       domain.ErrInvalidTaprootWitness,
+		},
+		{
+			name:      "p2wsh",
+			prevout:   p2wsh,
+			witness:   wire.TxWitness{signature, multisig},
+			inputType: domain.InputNonStandard,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "p2pk",
+			prevout:   buildScript(t, txscript.NewScriptBuilder().AddData(compressed).AddOp(txscript.OP_CHECKSIG)),
+			sigScript: pushes(signature),
+			inputType: domain.InputNonStandard,
+			err:       domain.ErrNonStandardScript,
+		},
+		{
+			name:      "witness v2",
+			prevout:   witnessV2,
+			witness:   wire.TxWitness{make([]byte, 64)},
+			inputType: domain.InputWitnessV2Plus,
+			err:       domain.ErrWitnessV2Plus,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			txIn := &wire.TxIn{SignatureScript: tc.sigScript, Witness: tc.witness}
+
+			inputType, pubkey, err := domain.ClassifyInput(txIn, func(wire.OutPoint) ([]byte, error) {
+				return tc.prevout, nil
+			})
+			require.Equal(t, tc.inputType, inputType, inputType.String())
+
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Nil(t, pubkey)
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, tc.pubKey.IsEqual(pubkey))
+		})
+	}
+
+	t.Run("missing prevout", func(t *testing.T) {
+		errNotFound := errors.New("not found")
+
+		inputType, pubkey, err := domain.ClassifyInput(&wire.TxIn{}, func(wire.OutPoint) ([]byte, error) {
+			return nil, errNotFound
+		})
+		require.Equal(t, domain.InputUnknown, inputType)
+		require.Nil(t, pubkey)
+		require.ErrorIs(t, err, errNotFound)
+	})
+}
+
+func TestComputeScalarNotEligible(t *testing.T) {
+	privKey, err := btcec.NewPrivateKey()
+	require.NoError(t, err)
+
+	compressed := privKey.PubKey().SerializeCompressed()
+	negated := append([]byte{compressed[0] ^ 0x01}, compressed[1:]...)
+
+	p2wpkhInput := func(index uint32, pubkey []byte) (*wire.TxIn, []byte) {
+		prevout := append([]byte{txscript.OP_0, txscript.OP_DATA_20}, btcutil.Hash160(pubkey)...)
+		return &wire.TxIn{
+			PreviousOutPoint: wire.OutPoint{Index: index},
+			Witness:          wire.TxWitness{make([]byte, 72), pubkey},
+		}, prevout
+	}
+
+	computeScalar := func(txIn []*wire.TxIn, prevouts [][]byte) error {
+		silentScalar := &domain.SilentScalar{TxIn: txIn}
+		return silentScalar.ComputeScalar(func(outpoint wire.OutPoint) ([]byte, error) {
+			return prevouts[outpoint.Index], nil
+		})
+	}
+
+	t.Run("witness v2 input", func(t *testing.T) {
+		txIn, prevout := p2wpkhInput(0, compressed)
+		v2 := &wire.TxIn{PreviousOutPoint: wire.OutPoint{Index: 1}, Witness: wire.TxWitness{make([]byte, 64)}}
+		v2Prevout := append([]byte{txscript.OP_2, txscript.OP_DATA_32}, make([]byte, 32)...)
+
+		err := computeScalar([]*wire.TxIn{txIn, v2}, [][]byte{prevout, v2Prevout})
+		require.ErrorIs(t, err, domain.ErrNoEligibleInputs)
+		require.ErrorIs(t, err, domain.ErrWitnessV2Plus)
+	})
+
+	t.Run("keys sum up to infinity", func(t *testing.T) {
+		txIn, prevout := p2wpkhInput(0, compressed)
+		negatedTxIn, negatedPrevout := p2wpkhInput(1, negated)
+
+		err := computeScalar([]*wire.TxIn{txIn, negatedTxIn}, [][]byte{prevout, negatedPrevout})
+		require.ErrorIs(t, err, domain.ErrNoEligibleInputs)
+	})
+
+	t.Run("no eligible input", func(t *testing.T) {
+		txIn, prevout := p2wpkhInput(0, privKey.PubKey().SerializeUncompressed())
+
+		err := computeScalar([]*wire.TxIn{txIn}, [][]byte{prevout})
+		require.ErrorIs(t, err, domain.ErrNoEligibleInputs)
+	})
+}
+
+func buildScript(t *testing.T, builder *txscript.ScriptBuilder) []byte {
+	t.Helper()
+
+	script, err := builder.Script()
+	require.NoError(t, err)
+	return script
+}
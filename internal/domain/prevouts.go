@@ -0,0 +1,17 @@
+package domain
+
+import "github.com/btcsuite/btcd/wire"
+
+// PrevoutScripts maps the outpoints spent by a block to their script pubkeys.
+// it resolves the prevouts in memory while computing the block scalars.
+type PrevoutScripts map[wire.OutPoint][]byte
+
+// GetPrevoutScript can be passed to SilentScalar.ComputeScalar.
+func (p PrevoutScripts) GetPrevoutScript(outpoint wire.OutPoint) ([]byte, error) {
+	script, ok := p[outpoint]
+	if !ok {
+		return nil, ErrPrevoutNotFound
+	}
+
+	return script, nil
+}
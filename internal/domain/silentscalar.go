@@ -3,28 +3,32 @@ package domain
 import (
 	"bytes"
 	"encoding/binary"
-	"encoding/hex"
-	"math/big"
+	"fmt"
 	"sort"
 
 	"github.com/btcsuite/btcd/btcec/v2"
-	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
-	"github.com/btcsuite/btcd/btcec/v2/schnorr"
 	"github.com/btcsuite/btcd/btcutil"
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
-	"github.com/sirupsen/logrus"
 )
 
-var (
-	inputHashTag = []byte("BIP0352/Inputs")
-	num_h, _     = hex.DecodeString("50929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0")
-)
+var inputHashTag = []byte("BIP0352/Inputs")
 
 type TaprootOutput struct {
 	Index uint32
 	Spent bool
+	// SpentHeight is the height of the block spending the output, 0 if unspent.
+	SpentHeight int32
+	// Key is the x-only output key, empty for outputs indexed before keys were stored.
+	Key   []byte
+	Value int64
+}
+
+// IsDust returns true if the output is worth less than dustLimit sats.
+// outputs indexed before keys and values were stored are never dust.
+func (o TaprootOutput) IsDust(dustLimit int64) bool {
+	return len(o.Key) > 0 && o.Value < dustLimit
 }
 
 type SilentScalar struct {
@@ -45,6 +49,8 @@ func NewSilentScalar(
 			taprootOuts = append(taprootOuts, TaprootOutput{
 				Index: uint32(i),
 				Spent: false,
+				Key:   out.PkScript[2:],
+				Value: out.Value,
 			})
 		}
 	}
@@ -60,6 +66,8 @@ func NewSilentScalar(
 	}, nil
 }
 
+// ComputeScalar computes input_hash * A, the sum A of the eligible inputs public keys.
+// it returns ErrNoEligibleInputs if the transaction can't be used for silent payments.
 func (s *SilentScalar) ComputeScalar(
 	prevoutGetter func(wire.OutPoint) ([]byte, error),
 ) error {
@@ -71,41 +79,47 @@ func (s *SilentScalar) ComputeScalar(
 		return ErrUnableToComputeScalar
 	}
 
-	s.Scalar = computeScalar(s.TxIn, prevoutGetter)
+	scalar, err := computeScalar(s.TxIn, prevoutGetter)
+	if err != nil {
+		return err
+	}
+
+	s.Scalar = scalar
 	return nil
 }
 
 func computeScalar(
 	txIn []*wire.TxIn,
 	prevoutGetter func(wire.OutPoint) ([]byte, error),
-) []byte {
-	publicKeys := getInputPublicKeys(txIn, prevoutGetter)
-	sumInputPublicKeys := sumPublicKeys(publicKeys)
-	inputHash := getInputHash(txIn, sumInputPublicKeys)
-	scalarInputHash := new(big.Int).SetBytes(inputHash.CloneBytes())
-
-	if sumInputPublicKeys == nil {
-		// scalar = inputHash * G
-		x, y := btcec.S256().ScalarBaseMult(scalarInputHash.Bytes())
+) ([]byte, error) {
+	publicKeys, err := getInputPublicKeys(txIn, prevoutGetter)
+	if err != nil {
+		return nil, err
+	}
 
-		var xFieldVal, yFieldVal btcec.FieldVal
-		xFieldVal.SetByteSlice(x.Bytes())
-		yFieldVal.SetByteSlice(y.Bytes())
+	if len(publicKeys) == 0 {
+		return nil, ErrNoEligibleInputs
+	}
 
-		resultPubKey := btcec.NewPublicKey(&xFieldVal, &yFieldVal)
-		return resultPubKey.SerializeCompressed()
+	sumInputPublicKeys := sumPublicKeys(publicKeys)
+	if sumInputPublicKeys == nil {
+		return nil, fmt.Errorf("%w: input public keys sum up to the point at infinity", ErrNoEligibleInputs)
 	}
 
+	inputHash := InputHash(txIn, sumInputPublicKeys)
+
 	x, y := btcec.S256().ScalarMult(sumInputPublicKeys.X(), sumInputPublicKeys.Y(), inputHash[:])
 
 	var xFieldVal, yFieldVal btcec.FieldVal
 	xFieldVal.SetByteSlice(x.Bytes())
 	yFieldVal.SetByteSlice(y.Bytes())
 
-	return btcec.NewPublicKey(&xFieldVal, &yFieldVal).SerializeCompressed()
+	return btcec.NewPublicKey(&xFieldVal, &yFieldVal).SerializeCompressed(), nil
 }
 
-func getInputHash(
+// InputHash returns the BIP352 input hash of the transaction inputs,
+// sumPublicKeys is the sum of the eligible inputs public keys.
+func InputHash(
 	txIn []*wire.TxIn,
 	sumPublicKeys *btcec.PublicKey,
 ) *chainhash.Hash {
@@ -142,117 +156,22 @@ func serializeOutpoint(outpoint wire.OutPoint) []byte {
 	return buf.Bytes()
 }
 
+// sumPublicKeys returns the sum of the public keys, nil if they sum up to the point at infinity.
 func sumPublicKeys(publicKeys []*btcec.PublicKey) *btcec.PublicKey {
-	if len(publicKeys) == 0 {
-		return nil
-	}
+	var sum btcec.JacobianPoint
+	for _, pubkey := range publicKeys {
+		var point btcec.JacobianPoint
+		pubkey.AsJacobian(&point)
 
-	if len(publicKeys) == 1 {
-		return publicKeys[0]
+		var result btcec.JacobianPoint
+		btcec.AddNonConst(&sum, &point, &result)
+		sum = result
 	}
 
-	x, y := publicKeys[0].X(), publicKeys[0].Y()
-
-	for _, pubkey := range publicKeys[1:] {
-		x, y = btcec.S256().Add(x, y, pubkey.X(), pubkey.Y())
-	}
-
-	var xFieldVal, yFieldVal btcec.FieldVal
-	xFieldVal.SetByteSlice(x.Bytes())
-	yFieldVal.SetByteSlice(y.Bytes())
-
-	return btcec.NewPublicKey(&xFieldVal, &yFieldVal)
-}
-
-func getInputPublicKeys(
-	txIn []*wire.TxIn,
-	getPrevoutScript func(wire.OutPoint) ([]byte, error),
-) []*btcec.PublicKey {
-	publicKeys := make([]*btcec.PublicKey, 0)
-
-	for _, txIn := range txIn {
-		pubkey, err := extractPublicKeyFromInput(txIn, getPrevoutScript)
-		if err != nil {
-			if err != ErrNonStandardScript {
-				logrus.Warnf("error extracting public key from input: %v", err)
-				continue
-			}
-		}
-
-		if pubkey != nil {
-			publicKeys = append(publicKeys, pubkey)
-		}
-	}
-
-	return publicKeys
-}
-
-func extractPublicKeyFromInput(txIn *wire.TxIn, getPrevout func(wire.OutPoint) ([]byte, error)) (*btcec.PublicKey, error) {
-	// P2SH
-	if len(txIn.SignatureScript) > 0 && txscript.IsPayToWitnessPubKeyHash(txIn.SignatureScript[1:]) {
-		if len(txIn.Witness) == 0 {
-			return nil, ErrNonStandardScript
-		}
-
-		pubKeyBytes := txIn.Witness[len(txIn.Witness)-1]
-		if len(pubKeyBytes) != 33 {
-			return nil, ErrNonStandardScript
-		}
-		return btcec.ParsePubKey(pubKeyBytes)
-	}
-
-	// P2WPKH
-	if len(txIn.Witness) == 2 {
-		_, err := ecdsa.ParseSignature(txIn.Witness[0])
-		if err == nil {
-			if len(txIn.Witness[1]) != 33 {
-				return nil, ErrNonStandardScript
-			}
-
-			return btcec.ParsePubKey(txIn.Witness[1])
-		}
-	}
-
-	prevoutScript, err := getPrevout(txIn.PreviousOutPoint)
-	if err != nil {
-		return nil, err
-	}
-	// P2TR
-	if txscript.IsPayToTaproot(prevoutScript) {
-		witness := append(wire.TxWitness{}, txIn.Witness...)
-		if len(witness) < 1 {
-			return nil, ErrInvalidTaprootWitness
-		}
-
-		if len(witness) > 1 && len(witness[len(witness)-1]) > 0 && witness[len(witness)-1][0] == 0x50 {
-			witness = witness[:len(witness)-1] // remove annex
-		}
-
-		if len(witness) > 1 {
-			controlBlock := witness[len(witness)-1]
-			internalKey := controlBlock[1:33]
-			if bytes.Equal(internalKey, num_h) {
-				return nil, ErrNonStandardScript
-			}
-		}
-
-		taprootKey := prevoutScript[2:]
-		return schnorr.ParsePubKey(taprootKey)
-	}
-
-	// P2PKH
-	if txscript.IsPayToPubKeyHash(prevoutScript) {
-		pubkeyHash := prevoutScript[3:23]
-		for i := len(txIn.SignatureScript); i >= 0; i-- {
-			if i-33 >= 0 {
-				pubkey := txIn.SignatureScript[i-33 : i]
-
-				if bytes.Equal(pubkeyHash, btcutil.Hash160(pubkey)) {
-					return btcec.ParsePubKey(pubkey)
-				}
-			}
-		}
+	if (sum.X.IsZero() && sum.Y.IsZero()) || sum.Z.IsZero() {
+		return nil
 	}
+	sum.ToAffine()
 
-	return nil, ErrNonStandardScript
+	return btcec.NewPublicKey(&sum.X, &sum.Y)
 }
@@ -0,0 +1,175 @@
+package badgerdb
+
+import (
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/sirupsen/logrus"
+	"github.com/timshannon/badgerhold/v4"
+)
+
+// migrations upgrade the databases written by older versions, they are applied in order at startup.
+// a new migration must be appended.
+var migrations = []struct {
+	description string
+	up          func(s *scalarRepository) error
+}{
+	{"index the transactions by hash", (*scalarRepository).buildTxIndex},
+	{"move the spent flags out of the blocks", (*scalarRepository).moveSpentFlags},
+	{"store the first block indexed with its hash", (*scalarRepository).setFirstHashedHeight},
+}
+
+func (s *scalarRepository) migrate() error {
+	var version storageVersion
+	if err := s.store.Get(versionKey, &version); err != nil && err != badgerhold.ErrNotFound {
+		return err
+	}
+
+	for i := version.Version; i < len(migrations); i++ {
+		if err := migrations[i].up(s); err != nil {
+			return fmt.Errorf("migration %d (%s) failed: %w", i+1, migrations[i].description, err)
+		}
+
+		if err := s.store.Upsert(versionKey, storageVersion{i + 1}); err != nil {
+			return err
+		}
+
+		logrus.Debugf("badger storage migrated to version %d: %s", i+1, migrations[i].description)
+	}
+
+	return nil
+}
+
+// buildTxIndex indexes the transactions of the blocks written before the index existed.
+func (s *scalarRepository) buildTxIndex() error {
+	maxHeight, err := s.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	if maxHeight > 0 {
+		logrus.Infof("indexing the transactions of %d blocks, this may take a while", maxHeight)
+	}
+
+	for height := int32(0); height <= maxHeight; height++ {
+		var block blockScalarsDTO
+		if err := s.store.Get(height, &block); err != nil {
+			if err == badgerhold.ErrNotFound {
+				continue
+			}
+
+			return err
+		}
+
+		if err := s.store.Badger().Update(func(tx *badger.Txn) error {
+			return s.indexTxs(tx, &block)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveSpentFlags moves the spent flags stored in the blocks by older versions to the spent keys.
+// the outputs spent by later blocks are listed in the journals,
+// the ones spent in the same block stay flagged in the block.
+func (s *scalarRepository) moveSpentFlags() error {
+	if err := s.store.ForEach(nil, func(journal *spentOutpointsDTO) error {
+		return s.store.Badger().Update(func(tx *badger.Txn) error {
+			for _, outpoint := range journal.Outpoints {
+				var txHeight txHeightDTO
+				if err := s.store.TxGet(tx, outpoint.Hash, &txHeight); err != nil {
+					if err == badgerhold.ErrNotFound {
+						continue
+					}
+
+					return err
+				}
+
+				if _, err := setSpent(tx, txHeight.Height, outpoint, journal.Height); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	}); err != nil {
+		return err
+	}
+
+	maxHeight, err := s.GetLatestBlockHeight()
+	if err != nil {
+		return err
+	}
+
+	for height := int32(0); height <= maxHeight; height++ {
+		var block blockScalarsDTO
+		if err := s.store.Get(height, &block); err != nil {
+			if err == badgerhold.ErrNotFound {
+				continue
+			}
+
+			return err
+		}
+
+		var spent map[wire.OutPoint]int32
+		if err := s.store.Badger().View(func(tx *badger.Txn) (err error) {
+			spent, err = getSpent(tx, height)
+			return err
+		}); err != nil {
+			return err
+		}
+
+		updated := false
+		for txHash, scalarData := range block.ScalarsData {
+			for i, out := range scalarData.TaprootOutputs {
+				if _, ok := spent[wire.OutPoint{Hash: txHash, Index: out.Index}]; ok && out.Spent {
+					scalarData.TaprootOutputs[i].Spent = false
+					updated = true
+				}
+			}
+		}
+
+		if updated {
+			if err := s.store.Update(height, &block); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setFirstHashedHeight looks for the lowest block written with its hash,
+// the blocks indexed by older versions are below it.
+func (s *scalarRepository) setFirstHashedHeight() error {
+	var tip global
+	if err := s.store.Get(globalKey, &tip); err != nil {
+		if err == badgerhold.ErrNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	for height := int32(0); height <= tip.MaxHeight; height++ {
+		var block blockScalarsDTO
+		if err := s.store.Get(height, &block); err != nil {
+			if err == badgerhold.ErrNotFound {
+				continue
+			}
+
+			return err
+		}
+
+		if block.Hash != (chainhash.Hash{}) {
+			tip.FirstHashedHeight = height
+			return s.store.Upsert(globalKey, tip)
+		}
+	}
+
+	return nil
+}
@@ -16,7 +16,8 @@ import (
 )
 
 const (
-	globalKey = "global"
+	globalKey  = "global"
+	versionKey = "version"
 )
 
 type scalarRepository struct {
@@ -39,96 +40,264 @@ func New(
 		return nil, err
 	}
 
-	return &scalarRepository{db}, nil
+	repo := &scalarRepository{db}
+	if err := repo.migrate(); err != nil {
+		return nil, err
+	}
+
+	return repo, nil
 }
 
-func (s *scalarRepository) GetScalars(height int32) ([]string, error) {
-	var result blockScalarsDTO
-	if err := s.store.Get(height, &result); err != nil {
+func (s *scalarRepository) GetScalars(height int32, filter ports.ScalarsFilter, dustLimit int64) ([]string, error) {
+	result, err := s.getBlock(height)
+	if err != nil {
+		if err == badgerhold.ErrNotFound {
+			return nil, ports.ErrBlockNotFound{Height: height}
+		}
+
 		return nil, err
 	}
 
 	scalars := make([]string, 0, len(result.ScalarsData))
 	for _, scalar := range result.ScalarsData {
+		if !scalar.matches(filter, dustLimit) {
+			continue
+		}
+
 		scalars = append(scalars, hex.EncodeToString(scalar.Scalar))
 	}
 
 	return scalars, nil
 }
 
-func (s *scalarRepository) MarkSpent(outpoints []wire.OutPoint) error {
-	for _, outpoint := range outpoints {
-		if err := s.markOutpointSpent(outpoint); err != nil {
-			return err
+func (s *scalarRepository) GetSilentScalars(height int32, filter ports.ScalarsFilter, dustLimit int64) ([]*domain.SilentScalar, error) {
+	result, err := s.getBlock(height)
+	if err != nil {
+		if err == badgerhold.ErrNotFound {
+			return nil, ports.ErrBlockNotFound{Height: height}
 		}
+
+		return nil, err
 	}
 
-	return nil
-}
+	silentScalars := make([]*domain.SilentScalar, 0, len(result.ScalarsData))
+	for txHash, scalar := range result.ScalarsData {
+		if !scalar.matches(filter, dustLimit) {
+			continue
+		}
 
-func (s *scalarRepository) markOutpointSpent(outpoint wire.OutPoint) error {
-	silentScalar, err := s.getByTxHash(&outpoint.Hash)
-	if err != nil {
-		return err
+		txHash := txHash
+		silentScalars = append(silentScalars, &domain.SilentScalar{
+			TxHash:         &txHash,
+			Scalar:         scalar.Scalar,
+			TaprootOutputs: scalar.TaprootOutputs,
+		})
 	}
 
-	atLeastOneUnspent := false
+	return silentScalars, nil
+}
+
+func (s *scalarRepository) GetScalarsRange(from, to int32, filter ports.ScalarsFilter, dustLimit int64) ([]domain.BlockScalars, error) {
+	blocks := make([]domain.BlockScalars, 0)
 
-	for i, out := range silentScalar.TaprootOutputs {
-		if out.Index == outpoint.Index {
-			silentScalar.TaprootOutputs[i].Spent = true
-			continue
+	for height := from; height <= to; height++ {
+		result, err := s.getBlock(height)
+		if err != nil {
+			if err == badgerhold.ErrNotFound {
+				continue
+			}
+
+			return nil, err
 		}
 
-		if !out.Spent {
-			atLeastOneUnspent = true
+		scalars := make([]string, 0, len(result.ScalarsData))
+		for _, scalar := range result.ScalarsData {
+			if !scalar.matches(filter, dustLimit) {
+				continue
+			}
+
+			scalars = append(scalars, hex.EncodeToString(scalar.Scalar))
 		}
-	}
 
-	if atLeastOneUnspent {
-		return s.update(silentScalar)
+		blocks = append(blocks, domain.BlockScalars{
+			Height:  height,
+			Hash:    result.Hash,
+			Scalars: scalars,
+		})
 	}
 
-	return s.delete(&outpoint.Hash)
+	return blocks, nil
 }
 
-func (s *scalarRepository) delete(txHash *chainhash.Hash) error {
+func (s *scalarRepository) GetBlockHeader(height int32) (*domain.BlockHeader, error) {
 	var result blockScalarsDTO
-
-	if err := s.store.FindOne(&result, badgerhold.Where("ScalarsData").HasKey(*txHash)); err != nil {
+	if err := s.store.Get(height, &result); err != nil {
 		if err == badgerhold.ErrNotFound {
-			return ports.ErrScalarNotFound{MethodName: "Delete"}
+			return nil, ports.ErrBlockNotFound{Height: height}
 		}
 
-		return err
+		return nil, err
 	}
 
-	delete(result.ScalarsData, *txHash)
+	// blocks indexed before the hashes were stored can't be checked
+	if result.Hash == (chainhash.Hash{}) {
+		return nil, ports.ErrBlockNotFound{Height: height}
+	}
 
-	return s.store.Update(result.Height, &result)
+	return &domain.BlockHeader{
+		Height:   height,
+		Hash:     result.Hash,
+		PrevHash: result.PrevHash,
+	}, nil
 }
 
-func (s *scalarRepository) getByTxHash(txHash *chainhash.Hash) (*domain.SilentScalar, error) {
+func (s *scalarRepository) GetTaprootFilter(height int32) ([]byte, error) {
 	var result blockScalarsDTO
-
-	if err := s.store.FindOne(&result, badgerhold.Where("ScalarsData").HasKey(*txHash)); err != nil {
+	if err := s.store.Get(height, &result); err != nil {
 		if err == badgerhold.ErrNotFound {
-			return nil, ports.ErrScalarNotFound{MethodName: "GetByTxHash"}
+			return nil, ports.ErrBlockNotFound{Height: height}
 		}
 
 		return nil, err
 	}
 
-	scalarData, ok := result.ScalarsData[*txHash]
-	if !ok {
-		return nil, ports.ErrScalarNotFound{MethodName: "GetByTxHash"}
+	if len(result.TaprootFilter) == 0 {
+		return nil, ports.ErrBlockNotFound{Height: height}
 	}
 
-	return &domain.SilentScalar{
-		TxHash:         txHash,
-		TaprootOutputs: scalarData.TaprootOutputs,
-		Scalar:         scalarData.Scalar,
-	}, nil
+	return result.TaprootFilter, nil
+}
+
+func (s *scalarRepository) MarkSpent(outpoints []wire.OutPoint, spentHeight int32) error {
+	return s.store.Badger().Update(func(tx *badger.Txn) error {
+		return s.markSpent(tx, outpoints, spentHeight)
+	})
+}
+
+// MarkUnspent removes the spent keys of the outputs and the flags of the ones spent in their own block.
+func (s *scalarRepository) MarkUnspent(outpoints []wire.OutPoint) error {
+	return s.store.Badger().Update(func(tx *badger.Txn) error {
+		if err := s.unspend(tx, outpoints); err != nil {
+			return err
+		}
+
+		blocks := make(map[int32]*blockScalarsDTO)
+		updated := make(map[int32]struct{})
+
+		for _, outpoint := range outpoints {
+			var txHeight txHeightDTO
+			if err := s.store.TxGet(tx, outpoint.Hash, &txHeight); err != nil {
+				if err == badgerhold.ErrNotFound {
+					continue
+				}
+
+				return err
+			}
+
+			block, ok := blocks[txHeight.Height]
+			if !ok {
+				block = &blockScalarsDTO{}
+				if err := s.store.TxGet(tx, txHeight.Height, block); err != nil {
+					return err
+				}
+				blocks[txHeight.Height] = block
+			}
+
+			outputs := block.ScalarsData[outpoint.Hash].TaprootOutputs
+			for i, out := range outputs {
+				if out.Index == outpoint.Index && out.Spent {
+					outputs[i].Spent = false
+					outputs[i].SpentHeight = 0
+					updated[txHeight.Height] = struct{}{}
+				}
+			}
+		}
+
+		for height := range updated {
+			if err := s.store.TxUpdate(tx, height, blocks[height]); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// ApplyBlock writes the block, its spent outpoints and the new tip in a single badger transaction.
+func (s *scalarRepository) ApplyBlock(
+	scalars []*domain.SilentScalar,
+	header domain.BlockHeader,
+	taprootFilter []byte,
+	spentOutpoints []wire.OutPoint,
+) error {
+	return s.store.Badger().Update(func(tx *badger.Txn) error {
+		// the outputs created by the block are not indexed yet, they are flagged by the caller
+		if err := s.markSpent(tx, spentOutpoints, header.Height); err != nil {
+			return err
+		}
+
+		return s.write(tx, header, scalars, taprootFilter)
+	})
+}
+
+// Rollback deletes the blocks above forkHeight and unspends their spends in a single badger transaction,
+// a crash can't leave the tip above deleted blocks.
+func (s *scalarRepository) Rollback(forkHeight int32) error {
+	aboveFork := badgerhold.Where(badgerhold.Key).Gt(forkHeight)
+
+	return s.store.Badger().Update(func(tx *badger.Txn) error {
+		var journals []spentOutpointsDTO
+		if err := s.store.TxFind(tx, &journals, aboveFork); err != nil {
+			return err
+		}
+
+		for _, journal := range journals {
+			if err := s.unspend(tx, journal.Outpoints); err != nil {
+				return err
+			}
+
+			if err := s.store.TxDelete(tx, journal.Height, spentOutpointsDTO{}); err != nil {
+				return err
+			}
+		}
+
+		var orphans []blockScalarsDTO
+		if err := s.store.TxFind(tx, &orphans, aboveFork); err != nil {
+			return err
+		}
+
+		for _, orphan := range orphans {
+			for txHash := range orphan.ScalarsData {
+				if err := s.store.TxDelete(tx, txHash, txHeightDTO{}); err != nil && err != badgerhold.ErrNotFound {
+					return err
+				}
+			}
+
+			if err := s.store.TxDelete(tx, orphan.Height, blockScalarsDTO{}); err != nil {
+				return err
+			}
+		}
+
+		tip, err := getGlobal(s.store, tx)
+		if err != nil {
+			return err
+		}
+
+		updated := tip
+		if updated.MaxHeight > forkHeight {
+			updated.MaxHeight = forkHeight
+		}
+
+		if updated.FirstHashedHeight > forkHeight {
+			updated.FirstHashedHeight = 0
+		}
+
+		if updated == tip {
+			return nil
+		}
+
+		return s.store.TxUpsert(tx, globalKey, updated)
+	})
 }
 
 func (s *scalarRepository) GetLatestBlockHeight() (int32, error) {
@@ -145,42 +314,160 @@ func (s *scalarRepository) GetLatestBlockHeight() (int32, error) {
 	return result.MaxHeight, nil
 }
 
-func (s *scalarRepository) Write(scalars []*domain.SilentScalar, blockHeight int32) error {
-	if err := s.store.Upsert(blockHeight, newDTO(blockHeight, scalars)); err != nil {
+func (s *scalarRepository) GetFirstHashedHeight() (int32, error) {
+	var result global
+
+	if err := s.store.Get(globalKey, &result); err != nil {
+		if err == badgerhold.ErrNotFound {
+			return 0, nil
+		}
+
+		return 0, err
+	}
+
+	return result.FirstHashedHeight, nil
+}
+
+func (s *scalarRepository) Write(scalars []*domain.SilentScalar, header domain.BlockHeader, taprootFilter []byte) error {
+	return s.store.Badger().Update(func(tx *badger.Txn) error {
+		return s.write(tx, header, scalars, taprootFilter)
+	})
+}
+
+// getBlock reads the block with the spent flags of its taproot outputs.
+func (s *scalarRepository) getBlock(height int32) (*blockScalarsDTO, error) {
+	var block blockScalarsDTO
+
+	if err := s.store.Badger().View(func(tx *badger.Txn) error {
+		if err := s.store.TxGet(tx, height, &block); err != nil {
+			return err
+		}
+
+		return loadSpent(tx, &block)
+	}); err != nil {
+		return nil, err
+	}
+
+	return &block, nil
+}
+
+// write stores the block with its transactions index and moves the tip if the block is above,
+// the first hashed height if the block is below.
+func (s *scalarRepository) write(tx *badger.Txn, header domain.BlockHeader, scalars []*domain.SilentScalar, taprootFilter []byte) error {
+	block := newDTO(header, scalars, taprootFilter)
+
+	if err := s.store.TxUpsert(tx, header.Height, block); err != nil {
 		return err
 	}
 
-	maxHeight, err := s.GetLatestBlockHeight()
+	if err := s.indexTxs(tx, block); err != nil {
+		return err
+	}
+
+	tip, err := getGlobal(s.store, tx)
 	if err != nil {
 		return err
 	}
 
-	if blockHeight > maxHeight {
-		if err := s.store.Upsert(globalKey, global{blockHeight}); err != nil {
+	updated := tip
+	if header.Height > tip.MaxHeight {
+		updated.MaxHeight = header.Height
+	}
+
+	if header.Hash != (chainhash.Hash{}) && (tip.FirstHashedHeight == 0 || header.Height < tip.FirstHashedHeight) {
+		updated.FirstHashedHeight = header.Height
+	}
+
+	if updated == tip {
+		return nil
+	}
+
+	return s.store.TxUpsert(tx, globalKey, updated)
+}
+
+// getGlobal reads the tip record, zero if the store is empty.
+func getGlobal(store *badgerhold.Store, tx *badger.Txn) (global, error) {
+	var result global
+	if err := store.TxGet(tx, globalKey, &result); err != nil && err != badgerhold.ErrNotFound {
+		return global{}, err
+	}
+
+	return result, nil
+}
+
+// markSpent flags the indexed taproot outputs as spent by the block at spentHeight
+// and journals them to unspend them on rollback.
+// each outpoint takes a single lookup in the transactions index.
+func (s *scalarRepository) markSpent(tx *badger.Txn, outpoints []wire.OutPoint, spentHeight int32) error {
+	marked := make([]wire.OutPoint, 0, len(outpoints))
+
+	for _, outpoint := range outpoints {
+		var txHeight txHeightDTO
+		if err := s.store.TxGet(tx, outpoint.Hash, &txHeight); err != nil {
+			if err == badgerhold.ErrNotFound {
+				continue
+			}
+
 			return err
 		}
+
+		if !txHeight.hasTaprootOutput(outpoint.Index) {
+			continue
+		}
+
+		ok, err := setSpent(tx, txHeight.Height, outpoint, spentHeight)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			marked = append(marked, outpoint)
+		}
 	}
 
-	return nil
+	if len(marked) == 0 {
+		return nil
+	}
+
+	var journal spentOutpointsDTO
+	if err := s.store.TxGet(tx, spentHeight, &journal); err != nil && err != badgerhold.ErrNotFound {
+		return err
+	}
+
+	journal.Height = spentHeight
+	journal.Outpoints = append(journal.Outpoints, marked...)
+
+	return s.store.TxUpsert(tx, spentHeight, &journal)
 }
 
-func (s *scalarRepository) update(updated *domain.SilentScalar) error {
-	var result blockScalarsDTO
+// unspend removes the spent flags of the outpoints.
+func (s *scalarRepository) unspend(tx *badger.Txn, outpoints []wire.OutPoint) error {
+	for _, outpoint := range outpoints {
+		var txHeight txHeightDTO
+		if err := s.store.TxGet(tx, outpoint.Hash, &txHeight); err != nil {
+			if err == badgerhold.ErrNotFound {
+				continue
+			}
 
-	if err := s.store.FindOne(&result, badgerhold.Where("ScalarsData").HasKey(*updated.TxHash)); err != nil {
-		if err == badgerhold.ErrNotFound {
-			return ports.ErrScalarNotFound{MethodName: "Update"}
+			return err
 		}
 
-		return err
+		if err := tx.Delete(spentKey(txHeight.Height, outpoint)); err != nil {
+			return err
+		}
 	}
 
-	result.ScalarsData[*updated.TxHash] = scalar{
-		TaprootOutputs: updated.TaprootOutputs,
-		Scalar:         updated.Scalar,
+	return nil
+}
+
+func (s *scalarRepository) indexTxs(tx *badger.Txn, block *blockScalarsDTO) error {
+	for txHash, scalar := range block.ScalarsData {
+		if err := s.store.TxUpsert(tx, txHash, newTxHeightDTO(txHash, block.Height, scalar)); err != nil {
+			return err
+		}
 	}
 
-	return s.store.Update(result.Height, &result)
+	return nil
 }
 
 func createDb(dbDir string, logger badger.Logger) (*badgerhold.Store, error) {
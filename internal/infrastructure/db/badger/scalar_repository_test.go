@@ -0,0 +1,264 @@
+package badgerdb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/dgraph-io/badger/v4"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/stretchr/testify/require"
+	"github.com/timshannon/badgerhold/v4"
+)
+
+const txsPerBlock = 20
+
+func TestMigrate(t *testing.T) {
+	repo := newTestRepository(t)
+
+	// blocks written by older versions: no txid index and the spent flags in the blocks,
+	// the block 3 is the first one written with its hash
+	for height := int32(1); height <= 3; height++ {
+		header := domain.BlockHeader{Height: height}
+		if height == 3 {
+			header.Hash = chainhash.Hash{3}
+		}
+
+		block := newDTO(header, newTestScalars(height), nil)
+		require.NoError(t, repo.store.Upsert(height, block))
+		require.NoError(t, repo.store.Upsert(globalKey, global{MaxHeight: height}))
+	}
+
+	spentByBlock3 := wire.OutPoint{Hash: testTxHash(2, 0), Index: 0}
+	spentInBlock3 := wire.OutPoint{Hash: testTxHash(3, 1), Index: 1}
+
+	for _, outpoint := range []wire.OutPoint{spentByBlock3, spentInBlock3} {
+		var block blockScalarsDTO
+		require.NoError(t, repo.store.Get(height(outpoint), &block))
+		block.ScalarsData[outpoint.Hash].TaprootOutputs[outpoint.Index].Spent = true
+		require.NoError(t, repo.store.Update(height(outpoint), &block))
+	}
+
+	require.NoError(t, repo.store.Upsert(int32(3), &spentOutpointsDTO{Height: 3, Outpoints: []wire.OutPoint{spentByBlock3}}))
+	require.NoError(t, repo.store.Upsert(versionKey, storageVersion{0}))
+
+	require.NoError(t, repo.migrate())
+
+	firstHashed, err := repo.GetFirstHashedHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(3), firstHashed)
+
+	requireSpent(t, repo, spentByBlock3, true)
+	requireSpent(t, repo, spentInBlock3, true)
+
+	// the flag of the output spent by a later block is moved out of the block
+	var block blockScalarsDTO
+	require.NoError(t, repo.store.Get(int32(2), &block))
+	require.False(t, block.ScalarsData[spentByBlock3.Hash].TaprootOutputs[0].Spent)
+
+	// the spends are indexed, non taproot outputs are ignored
+	spentByBlock4 := wire.OutPoint{Hash: testTxHash(1, 0), Index: 1}
+	require.NoError(t, repo.MarkSpent([]wire.OutPoint{spentByBlock4, {Hash: testTxHash(1, 1), Index: 5}}, 4))
+	requireSpent(t, repo, spentByBlock4, true)
+
+	var journal spentOutpointsDTO
+	require.NoError(t, repo.store.Get(int32(4), &journal))
+	require.Equal(t, []wire.OutPoint{spentByBlock4}, journal.Outpoints)
+
+	require.NoError(t, repo.Rollback(2))
+	requireSpent(t, repo, spentByBlock3, false)
+	requireSpent(t, repo, spentByBlock4, false)
+
+	tip, err := repo.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(2), tip)
+
+	// the hashed block is rolled back
+	firstHashed, err = repo.GetFirstHashedHeight()
+	require.NoError(t, err)
+	require.Zero(t, firstHashed)
+}
+
+func TestApplyBlock(t *testing.T) {
+	// the tip is the last record written by ApplyBlock, failing its encoding aborts the transaction halfway
+	failTip := false
+	store, err := badgerhold.Open(badgerhold.Options{
+		Encoder: func(value interface{}) ([]byte, error) {
+			if _, ok := value.(global); ok && failTip {
+				return nil, errors.New("tip write failed")
+			}
+			return badgerhold.DefaultEncode(value)
+		},
+		Decoder: badgerhold.DefaultDecode,
+		Options: badger.DefaultOptions("").WithInMemory(true).WithLogger(nil),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	repo := &scalarRepository{store}
+	require.NoError(t, repo.migrate())
+
+	require.NoError(t, repo.Write(newTestScalars(1), domain.BlockHeader{Height: 1}, nil))
+
+	spent := wire.OutPoint{Hash: testTxHash(1, 0), Index: 0}
+	require.NoError(t, repo.ApplyBlock(newTestScalars(2), domain.BlockHeader{Height: 2}, nil, []wire.OutPoint{spent}))
+
+	requireSpent(t, repo, spent, true)
+
+	tip, err := repo.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(2), tip)
+
+	// nothing is written if the transaction fails: neither the spends nor the block
+	failTip = true
+	spentByBlock3 := wire.OutPoint{Hash: testTxHash(2, 0), Index: 1}
+	require.Error(t, repo.ApplyBlock(newTestScalars(3), domain.BlockHeader{Height: 3}, nil, []wire.OutPoint{spentByBlock3}))
+
+	requireSpent(t, repo, spentByBlock3, false)
+
+	tip, err = repo.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(2), tip)
+
+	_, err = repo.GetScalars(3, ports.AllScalars, 0)
+	require.Error(t, err)
+
+	var txHeight txHeightDTO
+	require.ErrorIs(t, repo.store.Get(testTxHash(3, 0), &txHeight), badgerhold.ErrNotFound)
+}
+
+// BenchmarkTxLookup compares the lookup of the block including a transaction
+// by scanning the blocks and by the txid index.
+func BenchmarkTxLookup(b *testing.B) {
+	for _, numOfBlocks := range []int32{100, 1000} {
+		repo := newBenchRepository(b, numOfBlocks)
+		txHash := testTxHash(numOfBlocks/2, txsPerBlock/2)
+
+		b.Run(fmt.Sprintf("scan/blocks=%d", numOfBlocks), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var block blockScalarsDTO
+				if err := repo.store.FindOne(&block, badgerhold.Where("ScalarsData").HasKey(txHash)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("index/blocks=%d", numOfBlocks), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var txHeight txHeightDTO
+				if err := repo.store.Get(txHash, &txHeight); err != nil {
+					b.Fatal(err)
+				}
+
+				var block blockScalarsDTO
+				if err := repo.store.Get(txHeight.Height, &block); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkMarkSpent flags the outputs spent by a block spending 1 output of 50 previous blocks.
+func BenchmarkMarkSpent(b *testing.B) {
+	for _, numOfBlocks := range []int32{100, 1000} {
+		repo := newBenchRepository(b, numOfBlocks)
+
+		outpoints := make([]wire.OutPoint, 0, 50)
+		for i := int32(0); i < 50; i++ {
+			height := 1 + i*numOfBlocks/50
+			outpoints = append(outpoints, wire.OutPoint{Hash: testTxHash(height, 0), Index: 0})
+		}
+
+		b.Run(fmt.Sprintf("blocks=%d", numOfBlocks), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := repo.store.Badger().Update(func(tx *badger.Txn) error {
+					if i%2 == 0 {
+						return repo.markSpent(tx, outpoints, numOfBlocks+1)
+					}
+					return repo.unspend(tx, outpoints)
+				}); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func newTestRepository(t testing.TB) *scalarRepository {
+	repo, err := New("", nil)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		repo.(*scalarRepository).store.Close()
+	})
+
+	return repo.(*scalarRepository)
+}
+
+func newBenchRepository(b *testing.B, numOfBlocks int32) *scalarRepository {
+	repo := newTestRepository(b)
+
+	for height := int32(1); height <= numOfBlocks; height++ {
+		require.NoError(b, repo.Write(newTestScalars(height), domain.BlockHeader{Height: height}, nil))
+	}
+
+	return repo
+}
+
+func newTestScalars(height int32) []*domain.SilentScalar {
+	scalars := make([]*domain.SilentScalar, 0, txsPerBlock)
+
+	for i := 0; i < txsPerBlock; i++ {
+		txHash := testTxHash(height, i)
+		scalars = append(scalars, &domain.SilentScalar{
+			TxHash: &txHash,
+			Scalar: txHash[:],
+			TaprootOutputs: []domain.TaprootOutput{
+				{Index: 0, Key: txHash[:], Value: 1000},
+				{Index: 1, Key: txHash[:], Value: 2000},
+			},
+		})
+	}
+
+	return scalars
+}
+
+func requireSpent(t *testing.T, repo *scalarRepository, outpoint wire.OutPoint, spent bool) {
+	silentScalars, err := repo.GetSilentScalars(height(outpoint), ports.AllScalars, 0)
+	require.NoError(t, err)
+
+	for _, silentScalar := range silentScalars {
+		if *silentScalar.TxHash != outpoint.Hash {
+			continue
+		}
+
+		for _, out := range silentScalar.TaprootOutputs {
+			if out.Index == outpoint.Index {
+				require.Equal(t, spent, out.Spent)
+				return
+			}
+		}
+	}
+
+	t.Fatalf("output %s not found", outpoint)
+}
+
+// height returns the height of the block including the test transaction.
+func height(outpoint wire.OutPoint) int32 {
+	for h := int32(1); h <= 1000; h++ {
+		for i := 0; i < txsPerBlock; i++ {
+			if testTxHash(h, i) == outpoint.Hash {
+				return h
+			}
+		}
+	}
+	return 0
+}
+
+func testTxHash(height int32, i int) chainhash.Hash {
+	return chainhash.HashH([]byte(fmt.Sprintf("%d:%d", height, i)))
+}
@@ -0,0 +1,98 @@
+package badgerdb
+
+import (
+	"encoding/binary"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/dgraph-io/badger/v4"
+)
+
+// the spent flags are stored apart from the blocks so marking a spend doesn't rewrite the block including the output.
+// keys are prefixed by the height of the block including the output, the flags of a block are read with a single prefix scan.
+// values are the height of the spending block.
+var spentPrefix = []byte("spent:")
+
+func spentBlockPrefix(height int32) []byte {
+	prefix := make([]byte, 0, len(spentPrefix)+4)
+	prefix = append(prefix, spentPrefix...)
+	return binary.BigEndian.AppendUint32(prefix, uint32(height))
+}
+
+func spentKey(height int32, outpoint wire.OutPoint) []byte {
+	key := spentBlockPrefix(height)
+	key = append(key, outpoint.Hash[:]...)
+	return binary.BigEndian.AppendUint32(key, outpoint.Index)
+}
+
+// setSpent flags the output created at height as spent by the block at spentHeight,
+// it returns false if the output is already flagged.
+func setSpent(tx *badger.Txn, height int32, outpoint wire.OutPoint, spentHeight int32) (bool, error) {
+	key := spentKey(height, outpoint)
+
+	if _, err := tx.Get(key); err == nil {
+		return false, nil
+	} else if err != badger.ErrKeyNotFound {
+		return false, err
+	}
+
+	return true, tx.Set(key, binary.BigEndian.AppendUint32(nil, uint32(spentHeight)))
+}
+
+// getSpent returns the outputs created at height and flagged as spent, with the height of the spending block.
+func getSpent(tx *badger.Txn, height int32) (map[wire.OutPoint]int32, error) {
+	prefix := spentBlockPrefix(height)
+	spent := make(map[wire.OutPoint]int32)
+
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = prefix
+
+	it := tx.NewIterator(opts)
+	defer it.Close()
+
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		key := it.Item().Key()[len(prefix):]
+		if len(key) != chainhash.HashSize+4 {
+			continue
+		}
+
+		value, err := it.Item().ValueCopy(nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(value) != 4 {
+			continue
+		}
+
+		var outpoint wire.OutPoint
+		copy(outpoint.Hash[:], key[:chainhash.HashSize])
+		outpoint.Index = binary.BigEndian.Uint32(key[chainhash.HashSize:])
+
+		spent[outpoint] = int32(binary.BigEndian.Uint32(value))
+	}
+
+	return spent, nil
+}
+
+// loadSpent sets the spent flags of the block taproot outputs.
+// the outputs flagged in the block are spent by the block itself.
+func loadSpent(tx *badger.Txn, block *blockScalarsDTO) error {
+	spent, err := getSpent(tx, block.Height)
+	if err != nil {
+		return err
+	}
+
+	for txHash, scalarData := range block.ScalarsData {
+		for i, out := range scalarData.TaprootOutputs {
+			if spentHeight, ok := spent[wire.OutPoint{Hash: txHash, Index: out.Index}]; ok {
+				scalarData.TaprootOutputs[i].Spent = true
+				scalarData.TaprootOutputs[i].SpentHeight = spentHeight
+			} else if out.Spent && out.SpentHeight == 0 {
+				scalarData.TaprootOutputs[i].SpentHeight = block.Height
+			}
+		}
+	}
+
+	return nil
+}
@@ -2,11 +2,15 @@ package badgerdb
 
 import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
 )
 
 type global struct {
 	MaxHeight int32
+	// FirstHashedHeight is the lowest block stored with its hash, 0 if there is none
+	FirstHashedHeight int32
 }
 
 type scalar struct {
@@ -14,12 +18,70 @@ type scalar struct {
 	TaprootOutputs []domain.TaprootOutput
 }
 
+// matches returns true if at least 1 taproot output is selected by filter and dustLimit.
+// the block is read at once, so the outputs are filtered in memory.
+func (s scalar) matches(filter ports.ScalarsFilter, dustLimit int64) bool {
+	if filter == ports.AllScalars && dustLimit <= 0 {
+		return true
+	}
+
+	for _, out := range s.TaprootOutputs {
+		if filter.Selects(out, dustLimit) {
+			return true
+		}
+	}
+	return false
+}
+
 type blockScalarsDTO struct {
 	Height      int32 `badgerhold:"key"`
+	Hash        chainhash.Hash
+	PrevHash    chainhash.Hash
 	ScalarsData map[chainhash.Hash]scalar
+	// TaprootFilter is empty for blocks indexed before the filters were stored
+	TaprootFilter []byte
+}
+
+// txHeightDTO indexes the transactions by hash,
+// it points to the block storing the scalar and the taproot outputs.
+type txHeightDTO struct {
+	TxHash chainhash.Hash `badgerhold:"key"`
+	Height int32
+	// TaprootOutputs are the indexes of the transaction taproot outputs
+	TaprootOutputs []uint32
+}
+
+func newTxHeightDTO(txHash chainhash.Hash, height int32, s scalar) txHeightDTO {
+	outputs := make([]uint32, 0, len(s.TaprootOutputs))
+	for _, out := range s.TaprootOutputs {
+		outputs = append(outputs, out.Index)
+	}
+
+	return txHeightDTO{TxHash: txHash, Height: height, TaprootOutputs: outputs}
+}
+
+func (t txHeightDTO) hasTaprootOutput(index uint32) bool {
+	for _, i := range t.TaprootOutputs {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+// storageVersion is the number of migrations applied to the database.
+type storageVersion struct {
+	Version int
+}
+
+// spentOutpointsDTO stores the taproot outputs spent by the block at Height.
+// it is used to mark them as unspent again if the block is rolled back.
+type spentOutpointsDTO struct {
+	Height    int32 `badgerhold:"key"`
+	Outpoints []wire.OutPoint
 }
 
-func newDTO(height int32, scalars []*domain.SilentScalar) *blockScalarsDTO {
+func newDTO(header domain.BlockHeader, scalars []*domain.SilentScalar, taprootFilter []byte) *blockScalarsDTO {
 	scalarsData := make(map[chainhash.Hash]scalar, len(scalars))
 	for _, s := range scalars {
 		scalarsData[*s.TxHash] = scalar{
@@ -28,8 +90,11 @@ func newDTO(height int32, scalars []*domain.SilentScalar) *blockScalarsDTO {
 		}
 	}
 	return &blockScalarsDTO{
-		Height:      height,
-		ScalarsData: scalarsData,
+		Height:        header.Height,
+		Hash:          header.Hash,
+		PrevHash:      header.PrevHash,
+		ScalarsData:   scalarsData,
+		TaprootFilter: taprootFilter,
 	}
 
 }
@@ -0,0 +1,34 @@
+package bundb
+
+import "github.com/uptrace/bun"
+
+type BlockModel struct {
+	bun.BaseModel `bun:"table:blocks,alias:b"`
+
+	Height   int32  `bun:",pk"`
+	Hash     string `bun:",notnull"`
+	PrevHash string `bun:",notnull"`
+	// TaprootFilter is null for blocks indexed before the filters were stored
+	TaprootFilter []byte `bun:",nullzero"`
+}
+
+type ScalarModel struct {
+	bun.BaseModel `bun:"table:scalars,alias:s"`
+
+	TxHash         string                `bun:",pk,unique"`
+	Scalar         string                `bun:",notnull,unique"`
+	BlockHeight    int32                 `bun:",notnull"`
+	TaprootOutputs []*TaprootOutputModel `bun:"rel:has-many,join:tx_hash=tx_hash"`
+}
+
+type TaprootOutputModel struct {
+	bun.BaseModel `bun:"table:taproot_outputs,alias:o"`
+
+	ID          int64  `bun:",pk,autoincrement"`
+	TxHash      string `bun:",notnull"`
+	Index       uint32 `bun:",notnull"`
+	SpentHeight int32  `bun:",nullzero"`
+	// Key is the hex-encoded x-only output key
+	Key   string `bun:",nullzero"`
+	Value int64  `bun:",nullzero"`
+}
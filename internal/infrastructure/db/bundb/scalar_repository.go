@@ -0,0 +1,478 @@
+// Package bundb implements the scalar repository on top of bun,
+// it is shared by the SQL backends which only open the database and manage its schema.
+package bundb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"sort"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/uptrace/bun"
+)
+
+type repository struct {
+	db *bun.DB
+}
+
+// New returns the scalar repository of db, the tables must exist.
+func New(db *bun.DB) ports.ScalarRepository {
+	return &repository{db}
+}
+
+// GetScalars returns the scalars of the block transactions selected by filter and dustLimit, once per transaction.
+func (r *repository) GetScalars(height int32, filter ports.ScalarsFilter, dustLimit int64) ([]string, error) {
+	dest := make([]struct{ Scalar string }, 0)
+
+	query := r.db.NewSelect().Model((*ScalarModel)(nil)).
+		Column("scalar").
+		Where("block_height = ?", height)
+
+	if err := applyScalarsFilter(query, filter, dustLimit).
+		Scan(context.Background(), &dest); err != nil {
+		return nil, err
+	}
+
+	scalars := make([]string, 0, len(dest))
+	for _, d := range dest {
+		scalars = append(scalars, d.Scalar)
+	}
+
+	return scalars, nil
+}
+
+// GetScalarsRange returns the indexed blocks in [from, to] with their scalars.
+// heights indexed before blocks were stored have a zero hash.
+func (r *repository) GetScalarsRange(from, to int32, filter ports.ScalarsFilter, dustLimit int64) ([]domain.BlockScalars, error) {
+	ctx := context.Background()
+
+	var blockModels []BlockModel
+	if err := r.db.NewSelect().Model(&blockModels).
+		Where("height BETWEEN ? AND ?", from, to).
+		Scan(ctx); err != nil {
+		return nil, err
+	}
+
+	dest := make([]struct {
+		Scalar      string
+		BlockHeight int32
+	}, 0)
+
+	query := r.db.NewSelect().Model((*ScalarModel)(nil)).
+		Column("scalar", "block_height").
+		Where("block_height BETWEEN ? AND ?", from, to)
+
+	if err := applyScalarsFilter(query, filter, dustLimit).
+		Scan(ctx, &dest); err != nil {
+		return nil, err
+	}
+
+	blocks := make(map[int32]*domain.BlockScalars)
+
+	for _, block := range blockModels {
+		hash, err := chainhash.NewHashFromStr(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+
+		blocks[block.Height] = &domain.BlockScalars{
+			Height:  block.Height,
+			Hash:    *hash,
+			Scalars: make([]string, 0),
+		}
+	}
+
+	for _, d := range dest {
+		block, ok := blocks[d.BlockHeight]
+		if !ok {
+			block = &domain.BlockScalars{Height: d.BlockHeight, Scalars: make([]string, 0)}
+			blocks[d.BlockHeight] = block
+		}
+
+		block.Scalars = append(block.Scalars, d.Scalar)
+	}
+
+	result := make([]domain.BlockScalars, 0, len(blocks))
+	for _, block := range blocks {
+		result = append(result, *block)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Height < result[j].Height
+	})
+
+	return result, nil
+}
+
+func (r *repository) GetSilentScalars(height int32, filter ports.ScalarsFilter, dustLimit int64) ([]*domain.SilentScalar, error) {
+	var scalarModels []ScalarModel
+
+	if err := r.db.NewSelect().Model(&scalarModels).
+		Relation("TaprootOutputs").
+		Where("block_height = ?", height).
+		Scan(context.Background()); err != nil {
+		return nil, err
+	}
+
+	silentScalars := make([]*domain.SilentScalar, 0, len(scalarModels))
+
+	for _, model := range scalarModels {
+		txHash, err := chainhash.NewHashFromStr(model.TxHash)
+		if err != nil {
+			return nil, err
+		}
+
+		scalar, err := hex.DecodeString(model.Scalar)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs := make([]domain.TaprootOutput, 0, len(model.TaprootOutputs))
+		selected := false
+
+		for _, out := range model.TaprootOutputs {
+			key, err := hex.DecodeString(out.Key)
+			if err != nil {
+				return nil, err
+			}
+
+			output := domain.TaprootOutput{
+				Index:       out.Index,
+				Spent:       out.SpentHeight != 0,
+				SpentHeight: out.SpentHeight,
+				Key:         key,
+				Value:       out.Value,
+			}
+
+			if filter.Selects(output, dustLimit) {
+				selected = true
+			}
+
+			outputs = append(outputs, output)
+		}
+
+		if !selected && (filter != ports.AllScalars || dustLimit > 0) {
+			continue
+		}
+
+		silentScalars = append(silentScalars, &domain.SilentScalar{
+			TxHash:         txHash,
+			Scalar:         scalar,
+			TaprootOutputs: outputs,
+		})
+	}
+
+	return silentScalars, nil
+}
+
+func (r *repository) GetBlockHeader(height int32) (*domain.BlockHeader, error) {
+	var block BlockModel
+
+	if err := r.db.NewSelect().Model(&block).
+		Where("height = ?", height).
+		Scan(context.Background()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrBlockNotFound{Height: height}
+		}
+
+		return nil, err
+	}
+
+	hash, err := chainhash.NewHashFromStr(block.Hash)
+	if err != nil {
+		return nil, err
+	}
+
+	// blocks imported from a snapshot without their hash can't be checked
+	if *hash == (chainhash.Hash{}) {
+		return nil, ports.ErrBlockNotFound{Height: height}
+	}
+
+	prevHash, err := chainhash.NewHashFromStr(block.PrevHash)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.BlockHeader{
+		Height:   block.Height,
+		Hash:     *hash,
+		PrevHash: *prevHash,
+	}, nil
+}
+
+func (r *repository) GetTaprootFilter(height int32) ([]byte, error) {
+	var block BlockModel
+
+	if err := r.db.NewSelect().Model(&block).
+		Where("height = ?", height).
+		Scan(context.Background()); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ports.ErrBlockNotFound{Height: height}
+		}
+
+		return nil, err
+	}
+
+	if len(block.TaprootFilter) == 0 {
+		return nil, ports.ErrBlockNotFound{Height: height}
+	}
+
+	return block.TaprootFilter, nil
+}
+
+func (r *repository) MarkSpent(outpoints []wire.OutPoint, spentHeight int32) error {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := markSpent(ctx, tx, outpoints, spentHeight); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *repository) MarkUnspent(outpoints []wire.OutPoint) error {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, outpoint := range outpoints {
+		if _, err := tx.NewUpdate().Model((*TaprootOutputModel)(nil)).
+			Set("spent_height = NULL").
+			Where("tx_hash = ?", outpoint.Hash.String()).
+			Where("? = ?", bun.Ident("index"), outpoint.Index).
+			Exec(ctx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// ApplyBlock marks the outpoints spent by the block and writes it in a single transaction,
+// the tip is the highest block stored.
+func (r *repository) ApplyBlock(scalars []*domain.SilentScalar, header domain.BlockHeader, taprootFilter []byte, spentOutpoints []wire.OutPoint) error {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := markSpent(ctx, tx, spentOutpoints, header.Height); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := write(ctx, tx, scalars, header, taprootFilter); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Rollback deletes the scalars and blocks above forkHeight
+// and resets the spent height of the outputs spent above forkHeight.
+func (r *repository) Rollback(forkHeight int32) error {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	orphanTxs := tx.NewSelect().Model((*ScalarModel)(nil)).
+		Column("tx_hash").
+		Where("block_height > ?", forkHeight)
+
+	if _, err := tx.NewDelete().Model((*TaprootOutputModel)(nil)).
+		Where("tx_hash IN (?)", orphanTxs).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.NewDelete().Model((*ScalarModel)(nil)).
+		Where("block_height > ?", forkHeight).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.NewUpdate().Model((*TaprootOutputModel)(nil)).
+		Set("spent_height = NULL").
+		Where("spent_height > ?", forkHeight).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.NewDelete().Model((*BlockModel)(nil)).
+		Where("height > ?", forkHeight).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetLatestBlockHeight returns the maximum block height value in the blocks and scalars tables.
+// scalars are checked to support the postgres databases created before blocks were stored.
+func (r *repository) GetLatestBlockHeight() (int32, error) {
+	var maxBlockHeight, maxScalarHeight int32
+
+	if err := r.db.NewSelect().
+		Model((*BlockModel)(nil)).
+		ColumnExpr("COALESCE(MAX(height), 0)").
+		Scan(context.Background(), &maxBlockHeight); err != nil {
+		return 0, err
+	}
+
+	if err := r.db.NewSelect().
+		Model((*ScalarModel)(nil)).
+		ColumnExpr("COALESCE(MAX(block_height), 0)").
+		Scan(context.Background(), &maxScalarHeight); err != nil {
+		return 0, err
+	}
+
+	if maxScalarHeight > maxBlockHeight {
+		return maxScalarHeight, nil
+	}
+
+	return maxBlockHeight, nil
+}
+
+// GetFirstHashedHeight returns the minimum height of the blocks table with a hash.
+// the heights below were indexed before the blocks were stored, or imported without their hash.
+func (r *repository) GetFirstHashedHeight() (int32, error) {
+	var minBlockHeight int32
+
+	if err := r.db.NewSelect().
+		Model((*BlockModel)(nil)).
+		ColumnExpr("COALESCE(MIN(height), 0)").
+		Where("hash <> ?", chainhash.Hash{}.String()).
+		Scan(context.Background(), &minBlockHeight); err != nil {
+		return 0, err
+	}
+
+	return minBlockHeight, nil
+}
+
+func (r *repository) Write(scalars []*domain.SilentScalar, header domain.BlockHeader, taprootFilter []byte) error {
+	ctx := context.Background()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := write(ctx, tx, scalars, header, taprootFilter); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func write(ctx context.Context, tx bun.Tx, scalars []*domain.SilentScalar, header domain.BlockHeader, taprootFilter []byte) error {
+	blockModel := &BlockModel{
+		Height:        header.Height,
+		Hash:          header.Hash.String(),
+		PrevHash:      header.PrevHash.String(),
+		TaprootFilter: taprootFilter,
+	}
+
+	if _, err := tx.NewInsert().Model(blockModel).
+		On("CONFLICT (height) DO UPDATE").
+		Set("hash = EXCLUDED.hash").
+		Set("prev_hash = EXCLUDED.prev_hash").
+		Set("taproot_filter = EXCLUDED.taproot_filter").
+		Exec(ctx); err != nil {
+		return err
+	}
+
+	for _, scalar := range scalars {
+		scalarModel := &ScalarModel{
+			TxHash:      scalar.TxHash.String(),
+			Scalar:      hex.EncodeToString(scalar.Scalar),
+			BlockHeight: header.Height,
+		}
+
+		if _, err := tx.NewInsert().Model(scalarModel).Exec(ctx); err != nil {
+			return err
+		}
+
+		for _, out := range scalar.TaprootOutputs {
+			taprootOutputModel := &TaprootOutputModel{
+				TxHash: scalar.TxHash.String(),
+				Index:  out.Index,
+				Key:    hex.EncodeToString(out.Key),
+				Value:  out.Value,
+			}
+
+			// output spent in the same block
+			if out.Spent {
+				taprootOutputModel.SpentHeight = header.Height
+			}
+
+			if _, err := tx.NewInsert().Model(taprootOutputModel).Exec(ctx); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func markSpent(ctx context.Context, tx bun.Tx, outpoints []wire.OutPoint, spentHeight int32) error {
+	for _, outpoint := range outpoints {
+		if _, err := tx.NewUpdate().Model((*TaprootOutputModel)(nil)).
+			Set("spent_height = ?", spentHeight).
+			Where("tx_hash = ?", outpoint.Hash.String()).
+			Where("? = ?", bun.Ident("index"), outpoint.Index).
+			Where("spent_height IS NULL").
+			Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyScalarsFilter restricts a scalars query to the transactions selected by filter and dustLimit.
+// the outputs are checked with a subquery so each transaction is returned once.
+func applyScalarsFilter(query *bun.SelectQuery, filter ports.ScalarsFilter, dustLimit int64) *bun.SelectQuery {
+	if filter == ports.AllScalars && dustLimit <= 0 {
+		return query
+	}
+
+	outputs := query.NewSelect().Model((*TaprootOutputModel)(nil)).
+		ColumnExpr("1").
+		Where("o.tx_hash = s.tx_hash")
+
+	if filter == ports.UnspentScalars {
+		outputs = outputs.Where("o.spent_height IS NULL")
+	}
+
+	if dustLimit > 0 {
+		// outputs indexed before keys and values were stored are never dust
+		outputs = outputs.Where("(o.value >= ? OR o.key IS NULL)", dustLimit)
+	}
+
+	return query.Where("EXISTS (?)", outputs)
+}
@@ -0,0 +1,128 @@
+package inmemory
+
+import (
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+)
+
+type mempoolEntry struct {
+	scalar *domain.SilentScalar
+	seenAt time.Time
+}
+
+type mempoolRepository struct {
+	lock    sync.RWMutex
+	entries map[chainhash.Hash]mempoolEntry
+	// spenders indexes the stored transactions by spent outpoint to detect replacements
+	spenders map[wire.OutPoint]chainhash.Hash
+}
+
+func NewMempoolRepository() ports.MempoolRepository {
+	return &mempoolRepository{
+		entries:  make(map[chainhash.Hash]mempoolEntry),
+		spenders: make(map[wire.OutPoint]chainhash.Hash),
+	}
+}
+
+func (r *mempoolRepository) Add(scalar *domain.SilentScalar, seenAt time.Time) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, input := range scalar.TxIn {
+		if conflict, ok := r.spenders[input.PreviousOutPoint]; ok && !conflict.IsEqual(scalar.TxHash) {
+			r.remove(conflict)
+		}
+	}
+
+	r.entries[*scalar.TxHash] = mempoolEntry{scalar, seenAt}
+	for _, input := range scalar.TxIn {
+		r.spenders[input.PreviousOutPoint] = *scalar.TxHash
+	}
+
+	return nil
+}
+
+func (r *mempoolRepository) Remove(txids []chainhash.Hash) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, txid := range txids {
+		r.remove(txid)
+	}
+
+	return nil
+}
+
+func (r *mempoolRepository) RemoveExpired(before time.Time) ([]chainhash.Hash, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	expired := make([]chainhash.Hash, 0)
+	for txid, entry := range r.entries {
+		if entry.seenAt.Before(before) {
+			expired = append(expired, txid)
+		}
+	}
+
+	for _, txid := range expired {
+		r.remove(txid)
+	}
+
+	return expired, nil
+}
+
+func (r *mempoolRepository) GetTxids() ([]chainhash.Hash, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	txids := make([]chainhash.Hash, 0, len(r.entries))
+	for txid := range r.entries {
+		txids = append(txids, txid)
+	}
+
+	return txids, nil
+}
+
+// GetScalars returns the scalars ordered by first seen time.
+func (r *mempoolRepository) GetScalars() ([]string, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	entries := make([]mempoolEntry, 0, len(r.entries))
+	for _, entry := range r.entries {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].seenAt.Before(entries[j].seenAt)
+	})
+
+	scalars := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		scalars = append(scalars, hex.EncodeToString(entry.scalar.Scalar))
+	}
+
+	return scalars, nil
+}
+
+func (r *mempoolRepository) remove(txid chainhash.Hash) {
+	entry, ok := r.entries[txid]
+	if !ok {
+		return
+	}
+
+	for _, input := range entry.scalar.TxIn {
+		if spender, ok := r.spenders[input.PreviousOutPoint]; ok && spender.IsEqual(&txid) {
+			delete(r.spenders, input.PreviousOutPoint)
+		}
+	}
+
+	delete(r.entries, txid)
+}
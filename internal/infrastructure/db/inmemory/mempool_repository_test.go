@@ -0,0 +1,71 @@
+package inmemory
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMempoolRepository(t *testing.T) {
+	repo := NewMempoolRepository()
+	now := time.Now()
+
+	spent := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 0}
+
+	first := newScalar(chainhash.Hash{0x0a}, []byte{0x01}, spent)
+	second := newScalar(chainhash.Hash{0x0b}, []byte{0x02}, wire.OutPoint{Hash: chainhash.Hash{0x02}})
+
+	require.NoError(t, repo.Add(second, now.Add(time.Minute)))
+	require.NoError(t, repo.Add(first, now))
+
+	scalars, err := repo.GetScalars()
+	require.NoError(t, err)
+	require.Equal(t, []string{hex.EncodeToString([]byte{0x01}), hex.EncodeToString([]byte{0x02})}, scalars)
+
+	t.Run("replacement", func(t *testing.T) {
+		replacement := newScalar(chainhash.Hash{0x0c}, []byte{0x03}, spent)
+		require.NoError(t, repo.Add(replacement, now.Add(2*time.Minute)))
+
+		txids, err := repo.GetTxids()
+		require.NoError(t, err)
+		require.ElementsMatch(t, []chainhash.Hash{*second.TxHash, *replacement.TxHash}, txids)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		expired, err := repo.RemoveExpired(now.Add(90 * time.Second))
+		require.NoError(t, err)
+		require.Equal(t, []chainhash.Hash{*second.TxHash}, expired)
+
+		scalars, err := repo.GetScalars()
+		require.NoError(t, err)
+		require.Equal(t, []string{hex.EncodeToString([]byte{0x03})}, scalars)
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		require.NoError(t, repo.Remove([]chainhash.Hash{{0x0c}}))
+
+		txids, err := repo.GetTxids()
+		require.NoError(t, err)
+		require.Empty(t, txids)
+
+		// the spent outpoint is released
+		require.NoError(t, repo.Add(first, now))
+
+		txids, err = repo.GetTxids()
+		require.NoError(t, err)
+		require.Equal(t, []chainhash.Hash{*first.TxHash}, txids)
+	})
+}
+
+func newScalar(txid chainhash.Hash, scalar []byte, spent wire.OutPoint) *domain.SilentScalar {
+	return &domain.SilentScalar{
+		TxHash: &txid,
+		Scalar: scalar,
+		TxIn:   []*wire.TxIn{wire.NewTxIn(&spent, nil, nil)},
+	}
+}
@@ -0,0 +1,147 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/bundb"
+	"github.com/sirupsen/logrus"
+	"github.com/uptrace/bun"
+)
+
+type migration struct {
+	version     int
+	description string
+	up          func(ctx context.Context, tx bun.Tx) error
+}
+
+// migrations are applied in order on top of the tables created by the first release,
+// a new migration must be appended with the next version.
+// they must be idempotent: databases created before the migrations may already have some of the changes.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "index scalars by block height and taproot outputs by outpoint",
+		up: func(ctx context.Context, tx bun.Tx) error {
+			if _, err := tx.NewCreateIndex().Model((*bundb.ScalarModel)(nil)).
+				Index("scalars_block_height_idx").
+				Column("block_height").
+				IfNotExists().
+				Exec(ctx); err != nil {
+				return err
+			}
+
+			_, err := tx.NewCreateIndex().Model((*bundb.TaprootOutputModel)(nil)).
+				Index("taproot_outputs_outpoint_idx").
+				Column("tx_hash", "index").
+				IfNotExists().
+				Exec(ctx)
+			return err
+		},
+	},
+	{
+		version:     2,
+		description: "keep spent taproot outputs",
+		up: func(ctx context.Context, tx bun.Tx) error {
+			return addColumns(ctx, tx, (*bundb.TaprootOutputModel)(nil), "spent_height INTEGER")
+		},
+	},
+	{
+		version:     3,
+		description: "store taproot output keys and values",
+		up: func(ctx context.Context, tx bun.Tx) error {
+			return addColumns(ctx, tx, (*bundb.TaprootOutputModel)(nil), "key VARCHAR", "value BIGINT")
+		},
+	},
+	{
+		version:     4,
+		description: "store block taproot filters",
+		up: func(ctx context.Context, tx bun.Tx) error {
+			return addColumns(ctx, tx, (*bundb.BlockModel)(nil), "taproot_filter BYTEA")
+		},
+	},
+	{
+		version:     5,
+		description: "index taproot outputs values for dust limit queries",
+		up: func(ctx context.Context, tx bun.Tx) error {
+			_, err := tx.NewCreateIndex().Model((*bundb.TaprootOutputModel)(nil)).
+				Index("taproot_outputs_tx_hash_value_idx").
+				Column("tx_hash", "value").
+				IfNotExists().
+				Exec(ctx)
+			return err
+		},
+	},
+}
+
+// migrate creates the tables if missing and applies the pending migrations,
+// each one in its own transaction. it returns the resulting schema version.
+func migrate(ctx context.Context, db *bun.DB) (int, error) {
+	for _, model := range []interface{}{
+		(*SchemaVersionModel)(nil),
+		(*bundb.TaprootOutputModel)(nil),
+		(*bundb.ScalarModel)(nil),
+		(*bundb.BlockModel)(nil),
+	} {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	version, err := getSchemaVersion(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, m := range migrations {
+		if m.version <= version {
+			continue
+		}
+
+		if err := db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+			if err := m.up(ctx, tx); err != nil {
+				return err
+			}
+
+			_, err := tx.NewInsert().Model(&SchemaVersionModel{
+				Version:     m.version,
+				Description: m.description,
+				AppliedAt:   time.Now(),
+			}).Exec(ctx)
+			return err
+		}); err != nil {
+			return version, fmt.Errorf("migration %d (%s) failed: %w", m.version, m.description, err)
+		}
+
+		logrus.Infof("postgres schema migrated to version %d: %s", m.version, m.description)
+		version = m.version
+	}
+
+	return version, nil
+}
+
+func getSchemaVersion(ctx context.Context, db bun.IDB) (int, error) {
+	var version int
+
+	if err := db.NewSelect().Model((*SchemaVersionModel)(nil)).
+		ColumnExpr("COALESCE(MAX(version), 0)").
+		Scan(ctx, &version); err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+func addColumns(ctx context.Context, tx bun.Tx, model interface{}, columns ...string) error {
+	for _, column := range columns {
+		if _, err := tx.NewAddColumn().Model(model).
+			ColumnExpr(column).
+			IfNotExists().
+			Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrationsOrder(t *testing.T) {
+	for i, m := range migrations {
+		require.Equal(t, i+1, m.version, "migrations must be ordered by version without gaps")
+		require.NotEmpty(t, m.description)
+		require.NotNil(t, m.up)
+	}
+}
@@ -1,20 +1,16 @@
 package postgres
 
-import "github.com/uptrace/bun"
+import (
+	"time"
 
-type ScalarModel struct {
-	bun.BaseModel `bun:"table:scalars,alias:s"`
+	"github.com/uptrace/bun"
+)
 
-	TxHash         string                `bun:",pk,unique"`
-	Scalar         string                `bun:",notnull,unique"`
-	BlockHeight    int32                 `bun:",notnull"`
-	TaprootOutputs []*TaprootOutputModel `bun:"rel:has-many,join:tx_hash=tx_hash"`
-}
-
-type TaprootOutputModel struct {
-	bun.BaseModel `bun:"table:taproot_outputs,alias:o"`
+// SchemaVersionModel records the applied migrations.
+type SchemaVersionModel struct {
+	bun.BaseModel `bun:"table:schema_version,alias:v"`
 
-	ID     int64  `bun:",pk,autoincrement"`
-	TxHash string `bun:",notnull"`
-	Index  uint32 `bun:",notnull"`
+	Version     int       `bun:",pk"`
+	Description string    `bun:",notnull"`
+	AppliedAt   time.Time `bun:",notnull"`
 }
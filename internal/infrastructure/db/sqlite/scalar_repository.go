@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/bundb"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/sqlitedialect"
+	_ "modernc.org/sqlite"
+)
+
+// InMemory is the path of a database kept in memory, it is lost once closed.
+const InMemory = ":memory:"
+
+// New opens (or creates) the SQLite database file at path.
+func New(path string) (ports.ScalarRepository, error) {
+	// WAL lets the API read while the syncer writes,
+	// immediate transactions wait for the write lock instead of failing on upgrade.
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)&_txlock=immediate"
+
+	if path == InMemory {
+		dsn = "file::memory:?_txlock=immediate"
+	} else if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	sqldb, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// each connection to :memory: opens its own database
+	if path == InMemory {
+		sqldb.SetMaxOpenConns(1)
+	}
+
+	db := bun.NewDB(sqldb, sqlitedialect.New())
+
+	if err := createSchema(context.Background(), db); err != nil {
+		return nil, err
+	}
+
+	return bundb.New(db), nil
+}
+
+// createSchema creates the tables and indexes if missing.
+func createSchema(ctx context.Context, db *bun.DB) error {
+	for _, model := range []interface{}{
+		(*bundb.BlockModel)(nil),
+		(*bundb.ScalarModel)(nil),
+		(*bundb.TaprootOutputModel)(nil),
+	} {
+		if _, err := db.NewCreateTable().Model(model).IfNotExists().Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	indexes := []struct {
+		model   interface{}
+		name    string
+		unique  bool
+		columns []string
+	}{
+		// scalars of a block or a range of blocks
+		{(*bundb.ScalarModel)(nil), "scalars_block_height_idx", false, []string{"block_height"}},
+		// outputs of a transaction and outpoints marked as spent
+		{(*bundb.TaprootOutputModel)(nil), "taproot_outputs_outpoint_idx", true, []string{"tx_hash", "index"}},
+		// outputs unspent on rollback
+		{(*bundb.TaprootOutputModel)(nil), "taproot_outputs_spent_height_idx", false, []string{"spent_height"}},
+	}
+
+	for _, index := range indexes {
+		query := db.NewCreateIndex().Model(index.model).
+			Index(index.name).
+			Column(index.columns...).
+			IfNotExists()
+
+		if index.unique {
+			query = query.Unique()
+		}
+
+		if _, err := query.Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package sqlite
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemory(t *testing.T) {
+	repo, err := New(InMemory)
+	require.NoError(t, err)
+
+	txHash := chainhash.Hash{0x01}
+	outpoint := wire.OutPoint{Hash: txHash, Index: 1}
+
+	require.NoError(t, repo.Write([]*domain.SilentScalar{
+		{
+			TxHash: &txHash,
+			Scalar: []byte{0x02},
+			TaprootOutputs: []domain.TaprootOutput{
+				{Index: 0, Key: []byte{0x03}, Value: 1000},
+				{Index: 1, Key: []byte{0x04}, Value: 2000},
+			},
+		},
+	}, domain.BlockHeader{Height: 1, Hash: chainhash.Hash{0x05}}, []byte{0x06}))
+
+	// the index column is quoted in the spent outputs queries
+	require.NoError(t, repo.ApplyBlock(nil, domain.BlockHeader{Height: 2, Hash: chainhash.Hash{0x07}}, nil, []wire.OutPoint{outpoint}))
+
+	scalars, err := repo.GetSilentScalars(1, ports.AllScalars, 0)
+	require.NoError(t, err)
+	require.Len(t, scalars, 1)
+	require.Equal(t, []domain.TaprootOutput{
+		{Index: 0, Key: []byte{0x03}, Value: 1000},
+		{Index: 1, Key: []byte{0x04}, Value: 2000, Spent: true, SpentHeight: 2},
+	}, scalars[0].TaprootOutputs)
+
+	// the unspent output is above the dust limit
+	unspent, err := repo.GetScalars(1, ports.UnspentScalars, 1500)
+	require.NoError(t, err)
+	require.Empty(t, unspent)
+
+	require.NoError(t, repo.MarkUnspent([]wire.OutPoint{outpoint}))
+
+	unspent, err = repo.GetScalars(1, ports.UnspentScalars, 1500)
+	require.NoError(t, err)
+	require.Equal(t, []string{hex.EncodeToString([]byte{0x02})}, unspent)
+
+	require.NoError(t, repo.Rollback(1))
+
+	tip, err := repo.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(1), tip)
+
+	filter, err := repo.GetTaprootFilter(1)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x06}, filter)
+
+	// another in memory database is empty
+	other, err := New(InMemory)
+	require.NoError(t, err)
+
+	tip, err = other.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(0), tip)
+}
+
+func TestNewCreatesDir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "silentium.db")
+
+	repo, err := New(path)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Write(nil, domain.BlockHeader{Height: 1, Hash: chainhash.Hash{0x01}}, nil))
+	require.FileExists(t, path)
+
+	// the schema creation is idempotent
+	repo, err = New(path)
+	require.NoError(t, err)
+
+	tip, err := repo.GetLatestBlockHeight()
+	require.NoError(t, err)
+	require.Equal(t, int32(1), tip)
+}
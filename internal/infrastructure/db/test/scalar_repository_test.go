@@ -3,6 +3,7 @@ package dbtest
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"path/filepath"
 	"testing"
 
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
@@ -10,6 +11,7 @@ import (
 	"github.com/louisinger/silentiumd/internal/domain"
 	badgerdb "github.com/louisinger/silentiumd/internal/infrastructure/db/badger"
 	"github.com/louisinger/silentiumd/internal/infrastructure/db/postgres"
+	"github.com/louisinger/silentiumd/internal/infrastructure/db/sqlite"
 	"github.com/louisinger/silentiumd/internal/ports"
 	"github.com/stretchr/testify/require"
 )
@@ -37,7 +39,7 @@ func TestGetLatestBlockHeight(t *testing.T) {
 					Scalar: []byte{0x01},
 					TxHash: txhash,
 				},
-			}, initialTip+1))
+			}, newBlockHeader(t, initialTip+1), nil))
 
 			latest, err := repo.GetLatestBlockHeight()
 			require.NoError(t, err)
@@ -55,7 +57,7 @@ func TestGetLatestBlockHeight(t *testing.T) {
 					Scalar: []byte{0x02},
 					TxHash: txhash2,
 				},
-			}, initialTip+2))
+			}, newBlockHeader(t, initialTip+2), nil))
 
 			latest, err = repo.GetLatestBlockHeight()
 			require.NoError(t, err)
@@ -85,9 +87,9 @@ func TestGetScalars(t *testing.T) {
 					Scalar: []byte{0x03},
 					TxHash: txhash,
 				},
-			}, blockHeight))
+			}, newBlockHeader(t, blockHeight), nil))
 
-			scalars, err := repo.GetScalars(blockHeight)
+			scalars, err := repo.GetScalars(blockHeight, ports.UnspentScalars, 0)
 			require.NoError(t, err)
 			require.Len(t, scalars, 1)
 			require.Equal(t, hex.EncodeToString([]byte{0x03}), scalars[0])
@@ -97,10 +99,10 @@ func TestGetScalars(t *testing.T) {
 					Hash:  *txhash,
 					Index: 0,
 				},
-			})
+			}, blockHeight+1)
 			require.NoError(t, err)
 
-			scalars, err = repo.GetScalars(blockHeight)
+			scalars, err = repo.GetScalars(blockHeight, ports.UnspentScalars, 0)
 			require.NoError(t, err)
 			require.Len(t, scalars, 1)
 
@@ -109,12 +111,502 @@ func TestGetScalars(t *testing.T) {
 					Hash:  *txhash,
 					Index: 1,
 				},
-			})
+			}, blockHeight+1)
 			require.NoError(t, err)
 
-			scalars, err = repo.GetScalars(blockHeight)
+			scalars, err = repo.GetScalars(blockHeight, ports.UnspentScalars, 0)
 			require.NoError(t, err)
 			require.Len(t, scalars, 0)
+
+			scalars, err = repo.GetScalars(blockHeight, ports.AllScalars, 0)
+			require.NoError(t, err)
+			require.Equal(t, []string{hex.EncodeToString([]byte{0x03})}, scalars)
+		})
+	}
+}
+
+func TestGetSilentScalars(t *testing.T) {
+	repositories := getRepositories(t)
+	for name, repo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tip, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+
+			header := newBlockHeader(t, tip+1)
+			txhash := generateRandomTxHash(t)
+			spentTxHash := generateRandomTxHash(t)
+
+			outputs := []domain.TaprootOutput{
+				{
+					Index: 0,
+					Spent: false,
+					Key:   generateRandomTxHash(t)[:],
+					Value: 1000,
+				},
+				{
+					Index: 2,
+					Spent: false,
+					Key:   generateRandomTxHash(t)[:],
+					Value: 2000,
+				},
+			}
+
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: outputs,
+					Scalar:         []byte{0x09},
+					TxHash:         txhash,
+				},
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+							Key:   generateRandomTxHash(t)[:],
+							Value: 3000,
+						},
+					},
+					Scalar: []byte{0x0a},
+					TxHash: spentTxHash,
+				},
+			}, header, nil))
+
+			require.NoError(t, repo.MarkSpent([]wire.OutPoint{
+				{
+					Hash:  *txhash,
+					Index: 2,
+				},
+				{
+					Hash:  *spentTxHash,
+					Index: 0,
+				},
+			}, header.Height))
+
+			silentScalars, err := repo.GetSilentScalars(header.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, silentScalars, 1)
+
+			require.Equal(t, *txhash, *silentScalars[0].TxHash)
+			require.Equal(t, []byte{0x09}, silentScalars[0].Scalar)
+
+			outputs[1].Spent = true
+			outputs[1].SpentHeight = header.Height
+			require.ElementsMatch(t, outputs, silentScalars[0].TaprootOutputs)
+
+			silentScalars, err = repo.GetSilentScalars(header.Height, ports.AllScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, silentScalars, 2)
+		})
+	}
+}
+
+func TestDustLimit(t *testing.T) {
+	repositories := getRepositories(t)
+	for name, repo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tip, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+
+			header := newBlockHeader(t, tip+1)
+			txhash := generateRandomTxHash(t)
+			dustLimit := int64(546)
+
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Key:   generateRandomTxHash(t)[:],
+							Value: 330,
+						},
+						{
+							Index: 1,
+							Key:   generateRandomTxHash(t)[:],
+							Value: 10000,
+						},
+					},
+					Scalar: []byte{0x0b},
+					TxHash: txhash,
+				},
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Key:   generateRandomTxHash(t)[:],
+							Value: 330,
+						},
+					},
+					Scalar: []byte{0x0c},
+					TxHash: generateRandomTxHash(t),
+				},
+				// indexed without keys and values, never dust
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+						},
+					},
+					Scalar: []byte{0x0d},
+					TxHash: generateRandomTxHash(t),
+				},
+			}, header, nil))
+
+			scalars, err := repo.GetScalars(header.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, scalars, 3)
+
+			scalars, err = repo.GetScalars(header.Height, ports.UnspentScalars, dustLimit)
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{
+				hex.EncodeToString([]byte{0x0b}),
+				hex.EncodeToString([]byte{0x0d}),
+			}, scalars)
+
+			// the dust output left is not enough to select the transaction
+			require.NoError(t, repo.MarkSpent([]wire.OutPoint{
+				{
+					Hash:  *txhash,
+					Index: 1,
+				},
+			}, header.Height))
+
+			scalars, err = repo.GetScalars(header.Height, ports.UnspentScalars, dustLimit)
+			require.NoError(t, err)
+			require.Equal(t, []string{hex.EncodeToString([]byte{0x0d})}, scalars)
+
+			scalars, err = repo.GetScalars(header.Height, ports.AllScalars, dustLimit)
+			require.NoError(t, err)
+			require.ElementsMatch(t, []string{
+				hex.EncodeToString([]byte{0x0b}),
+				hex.EncodeToString([]byte{0x0d}),
+			}, scalars)
+
+			silentScalars, err := repo.GetSilentScalars(header.Height, ports.AllScalars, dustLimit)
+			require.NoError(t, err)
+			require.Len(t, silentScalars, 2)
+
+			blocks, err := repo.GetScalarsRange(header.Height, header.Height, ports.UnspentScalars, dustLimit)
+			require.NoError(t, err)
+			require.Len(t, blocks, 1)
+			require.Equal(t, []string{hex.EncodeToString([]byte{0x0d})}, blocks[0].Scalars)
+		})
+	}
+}
+
+func TestGetTaprootFilter(t *testing.T) {
+	repositories := getRepositories(t)
+	for name, repo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tip, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+
+			header := newBlockHeader(t, tip+1)
+			filter := []byte{0x01, 0x02, 0x03}
+
+			require.NoError(t, repo.Write([]*domain.SilentScalar{}, header, filter))
+
+			stored, err := repo.GetTaprootFilter(header.Height)
+			require.NoError(t, err)
+			require.Equal(t, filter, stored)
+
+			// block indexed without filter
+			require.NoError(t, repo.Write([]*domain.SilentScalar{}, newBlockHeader(t, tip+2), nil))
+
+			_, err = repo.GetTaprootFilter(tip + 2)
+			require.ErrorAs(t, err, &ports.ErrBlockNotFound{})
+
+			_, err = repo.GetTaprootFilter(tip + 3)
+			require.ErrorAs(t, err, &ports.ErrBlockNotFound{})
+		})
+	}
+}
+
+func TestRollback(t *testing.T) {
+	repositories := getRepositories(t)
+	for name, repo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tip, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+
+			forkHeader := newBlockHeader(t, tip+1)
+			forkTxHash := generateRandomTxHash(t)
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+					},
+					Scalar: []byte{0x04},
+					TxHash: forkTxHash,
+				},
+			}, forkHeader, nil))
+
+			orphanHeader := newBlockHeader(t, tip+2)
+			orphanHeader.PrevHash = forkHeader.Hash
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+					},
+					Scalar: []byte{0x05},
+					TxHash: generateRandomTxHash(t),
+				},
+			}, orphanHeader, nil))
+
+			require.NoError(t, repo.MarkSpent([]wire.OutPoint{
+				{
+					Hash:  *forkTxHash,
+					Index: 0,
+				},
+			}, orphanHeader.Height))
+
+			scalars, err := repo.GetScalars(forkHeader.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, scalars, 0)
+
+			header, err := repo.GetBlockHeader(orphanHeader.Height)
+			require.NoError(t, err)
+			require.Equal(t, orphanHeader, *header)
+
+			require.NoError(t, repo.Rollback(forkHeader.Height))
+
+			latest, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+			require.Equal(t, forkHeader.Height, latest)
+
+			_, err = repo.GetBlockHeader(orphanHeader.Height)
+			require.ErrorAs(t, err, &ports.ErrBlockNotFound{})
+
+			header, err = repo.GetBlockHeader(forkHeader.Height)
+			require.NoError(t, err)
+			require.Equal(t, forkHeader, *header)
+
+			firstHashed, err := repo.GetFirstHashedHeight()
+			require.NoError(t, err)
+			require.NotZero(t, firstHashed)
+			require.LessOrEqual(t, firstHashed, forkHeader.Height)
+
+			scalars, err = repo.GetScalars(forkHeader.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Equal(t, []string{hex.EncodeToString([]byte{0x04})}, scalars)
+		})
+	}
+}
+
+func TestApplyBlock(t *testing.T) {
+	repositories := getRepositories(t)
+	for name, repo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tip, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+
+			prevHeader := newBlockHeader(t, tip+1)
+			prevTxHash := generateRandomTxHash(t)
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+					},
+					Scalar: []byte{0x06},
+					TxHash: prevTxHash,
+				},
+			}, prevHeader, nil))
+
+			header := newBlockHeader(t, tip+2)
+			require.NoError(t, repo.ApplyBlock([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+					},
+					Scalar: []byte{0x07},
+					TxHash: generateRandomTxHash(t),
+				},
+			}, header, []byte{0x01}, []wire.OutPoint{
+				{
+					Hash:  *prevTxHash,
+					Index: 0,
+				},
+			}))
+
+			latest, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+			require.Equal(t, header.Height, latest)
+
+			storedHeader, err := repo.GetBlockHeader(header.Height)
+			require.NoError(t, err)
+			require.Equal(t, header, *storedHeader)
+
+			scalars, err := repo.GetScalars(header.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Equal(t, []string{hex.EncodeToString([]byte{0x07})}, scalars)
+
+			scalars, err = repo.GetScalars(prevHeader.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, scalars, 0)
+
+			filter, err := repo.GetTaprootFilter(header.Height)
+			require.NoError(t, err)
+			require.Equal(t, []byte{0x01}, filter)
+		})
+	}
+}
+
+func TestMarkUnspent(t *testing.T) {
+	repositories := getRepositories(t)
+	for name, repo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tip, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+
+			header := newBlockHeader(t, tip+1)
+			txhash := generateRandomTxHash(t)
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+						{
+							// spent in the same block
+							Index: 1,
+							Spent: true,
+						},
+					},
+					Scalar: []byte{0x08},
+					TxHash: txhash,
+				},
+			}, header, nil))
+
+			require.NoError(t, repo.MarkSpent([]wire.OutPoint{
+				{
+					Hash:  *txhash,
+					Index: 0,
+				},
+			}, header.Height+1))
+
+			scalars, err := repo.GetScalars(header.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, scalars, 0)
+
+			require.NoError(t, repo.MarkUnspent([]wire.OutPoint{
+				{
+					Hash:  *txhash,
+					Index: 0,
+				},
+				{
+					Hash:  *txhash,
+					Index: 1,
+				},
+			}))
+
+			silentScalars, err := repo.GetSilentScalars(header.Height, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, silentScalars, 1)
+			for _, out := range silentScalars[0].TaprootOutputs {
+				require.False(t, out.Spent)
+				require.Zero(t, out.SpentHeight)
+			}
+		})
+	}
+}
+
+func TestGetScalarsRange(t *testing.T) {
+	repositories := getRepositories(t)
+	for name, repo := range repositories {
+		t.Run(name, func(t *testing.T) {
+			tip, err := repo.GetLatestBlockHeight()
+			require.NoError(t, err)
+
+			first := newBlockHeader(t, tip+1)
+			firstTxHash := generateRandomTxHash(t)
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+						{
+							Index: 1,
+							Spent: false,
+						},
+					},
+					Scalar: []byte{0x06},
+					TxHash: firstTxHash,
+				},
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+					},
+					Scalar: []byte{0x07},
+					TxHash: generateRandomTxHash(t),
+				},
+			}, first, nil))
+
+			// no block at tip+2
+			last := newBlockHeader(t, tip+3)
+			require.NoError(t, repo.Write([]*domain.SilentScalar{
+				{
+					TaprootOutputs: []domain.TaprootOutput{
+						{
+							Index: 0,
+							Spent: false,
+						},
+					},
+					Scalar: []byte{0x08},
+					TxHash: generateRandomTxHash(t),
+				},
+			}, last, nil))
+
+			require.NoError(t, repo.MarkSpent([]wire.OutPoint{
+				{
+					Hash:  *firstTxHash,
+					Index: 0,
+				},
+				{
+					Hash:  *firstTxHash,
+					Index: 1,
+				},
+			}, last.Height))
+
+			blocks, err := repo.GetScalarsRange(first.Height, last.Height+10, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, blocks, 2)
+
+			require.Equal(t, first.Height, blocks[0].Height)
+			require.Equal(t, first.Hash, blocks[0].Hash)
+			require.Equal(t, []string{hex.EncodeToString([]byte{0x07})}, blocks[0].Scalars)
+
+			require.Equal(t, last.Height, blocks[1].Height)
+			require.Equal(t, last.Hash, blocks[1].Hash)
+			require.Equal(t, []string{hex.EncodeToString([]byte{0x08})}, blocks[1].Scalars)
+
+			blocks, err = repo.GetScalarsRange(first.Height+1, first.Height+1, ports.UnspentScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, blocks, 0)
+
+			blocks, err = repo.GetScalarsRange(first.Height, first.Height, ports.AllScalars, 0)
+			require.NoError(t, err)
+			require.Len(t, blocks, 1)
+			require.ElementsMatch(t, []string{
+				hex.EncodeToString([]byte{0x06}),
+				hex.EncodeToString([]byte{0x07}),
+			}, blocks[0].Scalars)
 		})
 	}
 }
@@ -126,9 +618,13 @@ func getRepositories(t *testing.T) map[string]ports.ScalarRepository {
 	postresrepo, err := postgres.New(postgres.PostreSQLConfig{Dsn: testDSN})
 	require.NoError(t, err)
 
+	sqliterepo, err := sqlite.New(filepath.Join(t.TempDir(), "silentium.db"))
+	require.NoError(t, err)
+
 	return map[string]ports.ScalarRepository{
 		"badger":   badgerrepo,
 		"postgres": postresrepo,
+		"sqlite":   sqliterepo,
 	}
 }
 
@@ -142,6 +638,14 @@ func generateRandomTxHash(t *testing.T) *chainhash.Hash {
 	return hash
 }
 
+func newBlockHeader(t *testing.T, height int32) domain.BlockHeader {
+	return domain.BlockHeader{
+		Height:   height,
+		Hash:     *generateRandomTxHash(t),
+		PrevHash: *generateRandomTxHash(t),
+	}
+}
+
 func randomBlockHeight(t *testing.T) int32 {
 	random32bytes := make([]byte, 4)
 	_, err := rand.Read(random32bytes)
@@ -0,0 +1,376 @@
+package esplora
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// esplora paginates the transactions of a block by 25
+	blockTxsPageSize = 25
+	requestTimeout   = 30 * time.Second
+)
+
+var pollingInterval = 30 * time.Second
+
+type clientEsplora struct {
+	baseURL string
+	http    *http.Client
+}
+
+// New returns a chain source reading from an Esplora (or electrs) HTTP API.
+func New(baseURL string) (*clientEsplora, error) {
+	if baseURL == "" {
+		return nil, errors.New("esplora url must be set")
+	}
+
+	return &clientEsplora{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+var _ ports.ChainSource = &clientEsplora{}
+
+func (c *clientEsplora) GetChainTipHeight() (int32, error) {
+	body, err := c.get("/blocks/tip/height")
+	if err != nil {
+		return 0, err
+	}
+
+	height, err := strconv.ParseInt(strings.TrimSpace(string(body)), 10, 32)
+	if err != nil {
+		return 0, err
+	}
+
+	return int32(height), nil
+}
+
+func (c *clientEsplora) GetBlockHash(h int32) (*chainhash.Hash, error) {
+	body, err := c.get(fmt.Sprintf("/block-height/%d", h))
+	if err != nil {
+		return nil, err
+	}
+
+	return chainhash.NewHashFromStr(strings.TrimSpace(string(body)))
+}
+
+func (c *clientEsplora) GetBlockByHeight(h int32) (*btcutil.Block, error) {
+	hash, err := c.GetBlockHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := c.getBlock(*hash)
+	if err != nil {
+		return nil, err
+	}
+
+	block.SetHeight(h)
+	return block, nil
+}
+
+func (c *clientEsplora) GetBlockFilterByHeight(h int32) (string, string, error) {
+	hash, err := c.GetBlockHash(h)
+	if err != nil {
+		return "", "", err
+	}
+
+	filter, err := c.GetBlockFilter(*hash)
+	if err != nil {
+		return "", "", err
+	}
+
+	return filter, hash.String(), nil
+}
+
+// GetBlockFilter computes the BIP158 basic filter of the block,
+// esplora does not serve compact block filters.
+func (c *clientEsplora) GetBlockFilter(hash chainhash.Hash) (string, error) {
+	block, err := c.getBlock(hash)
+	if err != nil {
+		return "", err
+	}
+
+	prevouts, err := c.GetBlockPrevouts(block)
+	if err != nil {
+		return "", err
+	}
+
+	filter, err := domain.NewBasicFilter(block.MsgBlock(), prevouts)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(filter), nil
+}
+
+func (c *clientEsplora) getBlock(hash chainhash.Hash) (*btcutil.Block, error) {
+	body, err := c.get(fmt.Sprintf("/block/%s/raw", hash))
+	if err != nil {
+		return nil, err
+	}
+
+	return btcutil.NewBlockFromBytes(body)
+}
+
+func (c *clientEsplora) GetPrevoutScript(outpoint wire.OutPoint) ([]byte, error) {
+	body, err := c.get(fmt.Sprintf("/tx/%s/raw", outpoint.Hash))
+	if err != nil {
+		return nil, err
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(body)); err != nil {
+		return nil, err
+	}
+
+	if int(outpoint.Index) >= len(tx.TxOut) {
+		return nil, errors.New("index out of range")
+	}
+
+	return tx.TxOut[outpoint.Index].PkScript, nil
+}
+
+func (c *clientEsplora) IsUtxo(outpoint wire.OutPoint) (bool, error) {
+	body, err := c.get(fmt.Sprintf("/tx/%s/outspend/%d", outpoint.Hash, outpoint.Index))
+	if err != nil {
+		return false, err
+	}
+
+	var outspend struct {
+		Spent bool `json:"spent"`
+	}
+	if err := json.Unmarshal(body, &outspend); err != nil {
+		return false, err
+	}
+
+	return !outspend.Spent, nil
+}
+
+// SubscribeBlocks polls the chain tip and sends the new blocks.
+// getChainTip returns the height and the hash of the chain tip.
+func (c *clientEsplora) getChainTip() (int32, string, error) {
+	height, err := c.GetChainTipHeight()
+	if err != nil {
+		return 0, "", err
+	}
+
+	body, err := c.get("/blocks/tip/hash")
+	if err != nil {
+		return 0, "", err
+	}
+
+	return height, strings.TrimSpace(string(body)), nil
+}
+
+func (c *clientEsplora) SubscribeBlocks() (<-chan *btcutil.Block, func(), error) {
+	currentHeight, currentHash, err := c.getChainTip()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ticker := time.NewTicker(pollingInterval)
+	quit := make(chan struct{})
+	var stopOnce sync.Once
+
+	blockChan := make(chan *btcutil.Block)
+
+	go func() {
+		defer close(blockChan)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-quit:
+				return
+			case <-ticker.C:
+			}
+
+			newHeight, newHash, err := c.getChainTip()
+			if err != nil {
+				logrus.Error(err)
+				continue
+			}
+
+			if newHash == currentHash {
+				continue
+			}
+
+			// the tip replaced without height change is sent alone
+			from := currentHeight + 1
+			if newHeight < from {
+				from = newHeight
+			}
+
+			// the current tip only moves once the block is sent, a failed fetch is retried on the next tick
+			for h := from; h <= newHeight; h++ {
+				block, err := c.GetBlockByHeight(h)
+				if err != nil {
+					logrus.Error(err)
+					break
+				}
+
+				// the hash is cached by the block, it is read before the receiver owns it
+				hash := block.Hash().String()
+
+				select {
+				case blockChan <- block:
+				case <-quit:
+					return
+				}
+				currentHeight, currentHash = h, hash
+			}
+		}
+	}()
+
+	return blockChan, func() {
+		stopOnce.Do(func() { close(quit) })
+	}, nil
+}
+
+type esploraTx struct {
+	Vin []struct {
+		Txid       string `json:"txid"`
+		Vout       uint32 `json:"vout"`
+		IsCoinbase bool   `json:"is_coinbase"`
+		Prevout    *struct {
+			ScriptPubKey string `json:"scriptpubkey"`
+		} `json:"prevout"`
+	} `json:"vin"`
+}
+
+// GetBlockPrevouts reads the prevouts from the paginated /block/:hash/txs endpoint.
+func (c *clientEsplora) GetBlockPrevouts(block *btcutil.Block) (domain.PrevoutScripts, error) {
+	prevouts := make(domain.PrevoutScripts)
+
+	for start := 0; start < len(block.Transactions()); start += blockTxsPageSize {
+		body, err := c.get(fmt.Sprintf("/block/%s/txs/%d", block.Hash(), start))
+		if err != nil {
+			return nil, err
+		}
+
+		var txs []esploraTx
+		if err := json.Unmarshal(body, &txs); err != nil {
+			return nil, err
+		}
+
+		for _, tx := range txs {
+			if err := addPrevouts(prevouts, tx); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return prevouts, nil
+}
+
+func addPrevouts(prevouts domain.PrevoutScripts, tx esploraTx) error {
+	for _, vin := range tx.Vin {
+		if vin.IsCoinbase || vin.Prevout == nil {
+			continue
+		}
+
+		txid, err := chainhash.NewHashFromStr(vin.Txid)
+		if err != nil {
+			return err
+		}
+
+		script, err := hex.DecodeString(vin.Prevout.ScriptPubKey)
+		if err != nil {
+			return err
+		}
+
+		prevouts[wire.OutPoint{Hash: *txid, Index: vin.Vout}] = script
+	}
+
+	return nil
+}
+
+func (c *clientEsplora) GetMempoolTxids() ([]chainhash.Hash, error) {
+	body, err := c.get("/mempool/txids")
+	if err != nil {
+		return nil, err
+	}
+
+	var txidsStr []string
+	if err := json.Unmarshal(body, &txidsStr); err != nil {
+		return nil, err
+	}
+
+	txids := make([]chainhash.Hash, 0, len(txidsStr))
+	for _, txidStr := range txidsStr {
+		txid, err := chainhash.NewHashFromStr(txidStr)
+		if err != nil {
+			return nil, err
+		}
+
+		txids = append(txids, *txid)
+	}
+
+	return txids, nil
+}
+
+func (c *clientEsplora) GetMempoolTx(txid chainhash.Hash) (*btcutil.Tx, error) {
+	body, err := c.get(fmt.Sprintf("/tx/%s/raw", txid))
+	if err != nil {
+		return nil, err
+	}
+
+	return btcutil.NewTxFromBytes(body)
+}
+
+// GetMempoolPrevouts reads the prevouts from the /tx/:txid endpoint.
+func (c *clientEsplora) GetMempoolPrevouts(tx *btcutil.Tx) (domain.PrevoutScripts, error) {
+	body, err := c.get(fmt.Sprintf("/tx/%s", tx.Hash()))
+	if err != nil {
+		return nil, err
+	}
+
+	var esploraTx esploraTx
+	if err := json.Unmarshal(body, &esploraTx); err != nil {
+		return nil, err
+	}
+
+	prevouts := make(domain.PrevoutScripts)
+	if err := addPrevouts(prevouts, esploraTx); err != nil {
+		return nil, err
+	}
+
+	return prevouts, nil
+}
+
+func (c *clientEsplora) get(path string) ([]byte, error) {
+	res, err := c.http.Get(c.baseURL + path)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("esplora: GET %s failed (%d): %s", path, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return body, nil
+}
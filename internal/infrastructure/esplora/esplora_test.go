@@ -0,0 +1,324 @@
+package esplora
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/gcs"
+	"github.com/btcsuite/btcd/btcutil/gcs/builder"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	prevoutScript, _ = hex.DecodeString("0014751e76e8199196d454941c45d1b3a323f1433bd6")
+	taprootScript, _ = hex.DecodeString("51201e2b46ff1a93fd9ea1ad79390a785a29aac94a9da0c7b07bbbc2a1b9ec4b4e3e")
+)
+
+func TestClientEsplora(t *testing.T) {
+	fixture := newFixture(t)
+	server := httptest.NewServer(fixture)
+	defer server.Close()
+
+	client, err := New(server.URL + "/")
+	require.NoError(t, err)
+
+	t.Run("GetChainTipHeight", func(t *testing.T) {
+		height, err := client.GetChainTipHeight()
+		require.NoError(t, err)
+		require.Equal(t, int32(1), height)
+	})
+
+	t.Run("GetBlockHash", func(t *testing.T) {
+		hash, err := client.GetBlockHash(1)
+		require.NoError(t, err)
+		require.Equal(t, fixture.block.BlockHash(), *hash)
+
+		_, err = client.GetBlockHash(2)
+		require.Error(t, err)
+	})
+
+	t.Run("GetBlockByHeight", func(t *testing.T) {
+		block, err := client.GetBlockByHeight(1)
+		require.NoError(t, err)
+		require.Equal(t, int32(1), block.Height())
+		require.Equal(t, fixture.block.BlockHash(), *block.Hash())
+		require.Len(t, block.Transactions(), len(fixture.block.Transactions))
+	})
+
+	t.Run("GetPrevoutScript", func(t *testing.T) {
+		script, err := client.GetPrevoutScript(wire.OutPoint{Hash: fixture.prevTx.TxHash(), Index: 0})
+		require.NoError(t, err)
+		require.Equal(t, prevoutScript, script)
+
+		_, err = client.GetPrevoutScript(wire.OutPoint{Hash: fixture.prevTx.TxHash(), Index: 1})
+		require.Error(t, err)
+	})
+
+	t.Run("GetBlockPrevouts", func(t *testing.T) {
+		block, err := client.GetBlockByHeight(1)
+		require.NoError(t, err)
+
+		prevouts, err := client.GetBlockPrevouts(block)
+		require.NoError(t, err)
+		require.Len(t, prevouts, 1)
+
+		script, err := prevouts.GetPrevoutScript(wire.OutPoint{Hash: fixture.prevTx.TxHash(), Index: 0})
+		require.NoError(t, err)
+		require.Equal(t, prevoutScript, script)
+	})
+
+	t.Run("IsUtxo", func(t *testing.T) {
+		spendTx := fixture.block.Transactions[1]
+
+		isUtxo, err := client.IsUtxo(wire.OutPoint{Hash: fixture.prevTx.TxHash(), Index: 0})
+		require.NoError(t, err)
+		require.False(t, isUtxo)
+
+		isUtxo, err = client.IsUtxo(wire.OutPoint{Hash: spendTx.TxHash(), Index: 0})
+		require.NoError(t, err)
+		require.True(t, isUtxo)
+	})
+
+	t.Run("GetBlockFilterByHeight", func(t *testing.T) {
+		filterHex, blockhash, err := client.GetBlockFilterByHeight(1)
+		require.NoError(t, err)
+		require.Equal(t, fixture.block.BlockHash().String(), blockhash)
+
+		expected, err := builder.BuildBasicFilter(fixture.block, [][]byte{prevoutScript})
+		require.NoError(t, err)
+		expectedBytes, err := expected.NBytes()
+		require.NoError(t, err)
+		require.Equal(t, hex.EncodeToString(expectedBytes), filterHex)
+
+		filterBytes, err := hex.DecodeString(filterHex)
+		require.NoError(t, err)
+		filter, err := gcs.FromNBytes(builder.DefaultP, builder.DefaultM, filterBytes)
+		require.NoError(t, err)
+
+		blockHash := fixture.block.BlockHash()
+		key := builder.DeriveKey(&blockHash)
+
+		for _, script := range [][]byte{prevoutScript, taprootScript} {
+			match, err := filter.Match(key, script)
+			require.NoError(t, err)
+			require.True(t, match)
+		}
+	})
+
+	t.Run("GetMempoolTxids", func(t *testing.T) {
+		txids, err := client.GetMempoolTxids()
+		require.NoError(t, err)
+		require.Equal(t, []chainhash.Hash{fixture.prevTx.TxHash()}, txids)
+	})
+
+	t.Run("GetMempoolTx", func(t *testing.T) {
+		tx, err := client.GetMempoolTx(fixture.prevTx.TxHash())
+		require.NoError(t, err)
+		require.Equal(t, fixture.prevTx.TxHash(), *tx.Hash())
+	})
+
+	t.Run("GetMempoolPrevouts", func(t *testing.T) {
+		spendTx := btcutil.NewTx(fixture.block.Transactions[1])
+
+		prevouts, err := client.GetMempoolPrevouts(spendTx)
+		require.NoError(t, err)
+		require.Len(t, prevouts, 1)
+
+		script, err := prevouts.GetPrevoutScript(wire.OutPoint{Hash: fixture.prevTx.TxHash(), Index: 0})
+		require.NoError(t, err)
+		require.Equal(t, prevoutScript, script)
+	})
+
+	t.Run("SubscribeBlocks", func(t *testing.T) {
+		defaultInterval := pollingInterval
+		pollingInterval = 10 * time.Millisecond
+		defer func() { pollingInterval = defaultInterval }()
+
+		blocks, cancel, err := client.SubscribeBlocks()
+		require.NoError(t, err)
+
+		receive := func() *btcutil.Block {
+			select {
+			case block := <-blocks:
+				return block
+			case <-time.After(5 * time.Second):
+				t.Fatal("no block received")
+				return nil
+			}
+		}
+
+		// the new block can't be fetched at first, it is sent once available
+		fixture.blockHeightFailures.Store(3)
+		fixture.tipHeight.Store(2)
+
+		block := receive()
+		require.Equal(t, int32(2), block.Height())
+		require.Equal(t, fixture.block2.BlockHash(), *block.Hash())
+
+		// the tip is replaced by a block at the same height
+		fixture.forked.Store(true)
+
+		block = receive()
+		require.Equal(t, int32(2), block.Height())
+		require.Equal(t, fixture.fork.BlockHash(), *block.Hash())
+
+		// cancel doesn't wait for a reader of the pending block
+		fixture.forked.Store(false)
+		time.Sleep(50 * time.Millisecond)
+
+		done := make(chan struct{})
+		go func() {
+			cancel()
+			cancel()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("cancel is blocked")
+		}
+	})
+}
+
+// fixture serves a chain of 2 blocks with the same transactions, the tip at height 2 can be replaced by fork.
+type fixture struct {
+	prevTx *wire.MsgTx
+	block  *wire.MsgBlock
+	block2 *wire.MsgBlock
+	fork   *wire.MsgBlock
+
+	tipHeight           atomic.Int32
+	forked              atomic.Bool
+	blockHeightFailures atomic.Int32
+}
+
+func newFixture(t *testing.T) *fixture {
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 0}, nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(10000, prevoutScript))
+
+	coinbase := wire.NewMsgTx(2)
+	coinbase.AddTxIn(wire.NewTxIn(&wire.OutPoint{Index: wire.MaxPrevOutIndex}, []byte{0x51, 0x51}, nil))
+	coinbase.AddTxOut(wire.NewTxOut(5000000000, taprootScript))
+
+	spendTx := wire.NewMsgTx(2)
+	spendTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: prevTx.TxHash(), Index: 0}, nil, wire.TxWitness{{0x01}, {0x02}}))
+	spendTx.AddTxOut(wire.NewTxOut(9000, taprootScript))
+
+	newBlock := func(prevBlock chainhash.Hash, nonce uint32) *wire.MsgBlock {
+		block := wire.NewMsgBlock(wire.NewBlockHeader(1, &prevBlock, &chainhash.Hash{0x03}, 0, nonce))
+		require.NoError(t, block.AddTransaction(coinbase))
+		require.NoError(t, block.AddTransaction(spendTx))
+		return block
+	}
+
+	block := newBlock(chainhash.Hash{0x02}, 0)
+
+	f := &fixture{
+		prevTx: prevTx,
+		block:  block,
+		block2: newBlock(block.BlockHash(), 1),
+		fork:   newBlock(block.BlockHash(), 2),
+	}
+	f.tipHeight.Store(1)
+	return f
+}
+
+// blockAt returns the block at height, nil above the tip.
+func (f *fixture) blockAt(height int32) *wire.MsgBlock {
+	switch {
+	case height > f.tipHeight.Load():
+		return nil
+	case height == 1:
+		return f.block
+	case height == 2 && f.forked.Load():
+		return f.fork
+	case height == 2:
+		return f.block2
+	default:
+		return nil
+	}
+}
+
+func (f *fixture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	spendTx := f.block.Transactions[1]
+
+	for _, block := range []*wire.MsgBlock{f.block, f.block2, f.fork} {
+		blockHash := block.BlockHash()
+
+		switch r.URL.Path {
+		case fmt.Sprintf("/block/%s/raw", blockHash):
+			var buf bytes.Buffer
+			_ = block.Serialize(&buf)
+			_, _ = w.Write(buf.Bytes())
+			return
+		case fmt.Sprintf("/block/%s/txs/0", blockHash):
+			_ = json.NewEncoder(w).Encode([]map[string]interface{}{
+				{"vin": []map[string]interface{}{{"is_coinbase": true, "prevout": nil}}},
+				f.spendTxJSON(),
+			})
+			return
+		}
+	}
+
+	switch r.URL.Path {
+	case "/blocks/tip/height":
+		fmt.Fprintf(w, "%d", f.tipHeight.Load())
+	case "/blocks/tip/hash":
+		fmt.Fprint(w, f.blockAt(f.tipHeight.Load()).BlockHash().String())
+	case "/block-height/1", "/block-height/2":
+		if f.blockHeightFailures.Load() > 0 {
+			f.blockHeightFailures.Add(-1)
+			http.Error(w, "Too many requests", http.StatusTooManyRequests)
+			return
+		}
+
+		height := int32(1)
+		if r.URL.Path == "/block-height/2" {
+			height = 2
+		}
+
+		block := f.blockAt(height)
+		if block == nil {
+			http.Error(w, "Block not found", http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, block.BlockHash().String())
+	case fmt.Sprintf("/tx/%s", spendTx.TxHash()):
+		_ = json.NewEncoder(w).Encode(f.spendTxJSON())
+	case "/mempool/txids":
+		_ = json.NewEncoder(w).Encode([]string{f.prevTx.TxHash().String()})
+	case fmt.Sprintf("/tx/%s/raw", f.prevTx.TxHash()):
+		var buf bytes.Buffer
+		_ = f.prevTx.Serialize(&buf)
+		_, _ = w.Write(buf.Bytes())
+	case fmt.Sprintf("/tx/%s/outspend/0", f.prevTx.TxHash()):
+		fmt.Fprint(w, `{"spent":true,"txid":"`+spendTx.TxHash().String()+`","vin":0}`)
+	case fmt.Sprintf("/tx/%s/outspend/0", spendTx.TxHash()):
+		fmt.Fprint(w, `{"spent":false}`)
+	default:
+		http.Error(w, "not found", http.StatusNotFound)
+	}
+}
+
+func (f *fixture) spendTxJSON() map[string]interface{} {
+	return map[string]interface{}{
+		"vin": []map[string]interface{}{{
+			"txid":        f.prevTx.TxHash().String(),
+			"vout":        0,
+			"is_coinbase": false,
+			"prevout":     map[string]interface{}{"scriptpubkey": hex.EncodeToString(prevoutScript)},
+		}},
+	}
+}
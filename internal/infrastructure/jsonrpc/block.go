@@ -0,0 +1,178 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/internal/ports"
+)
+
+// maxCachedPrevouts bounds the number of blocks whose prevouts are kept until the syncer reads them.
+const maxCachedPrevouts = 64
+
+// verboseBlock is the result of getblock with verbosity 3 (bitcoind v23+),
+// the transactions include their raw hex and the prevout of each input.
+type verboseBlock struct {
+	Hash              string `json:"hash"`
+	Version           int32  `json:"version"`
+	PreviousBlockHash string `json:"previousblockhash"`
+	MerkleRoot        string `json:"merkleroot"`
+	Time              int64  `json:"time"`
+	Bits              string `json:"bits"`
+	Nonce             uint32 `json:"nonce"`
+	Tx                []struct {
+		Hex string `json:"hex"`
+		Vin []struct {
+			Coinbase string `json:"coinbase"`
+			Txid     string `json:"txid"`
+			Vout     uint32 `json:"vout"`
+			Prevout  *struct {
+				ScriptPubKey struct {
+					Hex string `json:"hex"`
+				} `json:"scriptPubKey"`
+			} `json:"prevout"`
+		} `json:"vin"`
+	} `json:"tx"`
+}
+
+// block rebuilds the block from the header fields and the raw transactions.
+func (b *verboseBlock) block(height int32) (*btcutil.Block, error) {
+	header := wire.BlockHeader{
+		Version:   b.Version,
+		Timestamp: time.Unix(b.Time, 0),
+		Nonce:     b.Nonce,
+	}
+
+	// the genesis block has no previous block
+	if b.PreviousBlockHash != "" {
+		prevHash, err := chainhash.NewHashFromStr(b.PreviousBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		header.PrevBlock = *prevHash
+	}
+
+	merkleRoot, err := chainhash.NewHashFromStr(b.MerkleRoot)
+	if err != nil {
+		return nil, err
+	}
+	header.MerkleRoot = *merkleRoot
+
+	bits, err := strconv.ParseUint(b.Bits, 16, 32)
+	if err != nil {
+		return nil, err
+	}
+	header.Bits = uint32(bits)
+
+	msgBlock := wire.NewMsgBlock(&header)
+	for _, tx := range b.Tx {
+		rawTx, err := hex.DecodeString(tx.Hex)
+		if err != nil {
+			return nil, err
+		}
+
+		msgTx := wire.NewMsgTx(wire.TxVersion)
+		if err := msgTx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+			return nil, err
+		}
+
+		if err := msgBlock.AddTransaction(msgTx); err != nil {
+			return nil, err
+		}
+	}
+
+	if hash := msgBlock.BlockHash(); hash.String() != b.Hash {
+		return nil, fmt.Errorf("invalid block %s, header hashes to %s", b.Hash, hash)
+	}
+
+	block := btcutil.NewBlock(msgBlock)
+	block.SetHeight(height)
+	return block, nil
+}
+
+func (b *verboseBlock) prevouts() (domain.PrevoutScripts, error) {
+	prevouts := make(domain.PrevoutScripts)
+
+	for _, tx := range b.Tx {
+		for _, vin := range tx.Vin {
+			if vin.Coinbase != "" {
+				continue
+			}
+
+			// bitcoind before v23 handles verbosity 3 as 2, without the prevouts
+			if vin.Prevout == nil {
+				return nil, ports.ErrPrevoutsNotSupported
+			}
+
+			txid, err := chainhash.NewHashFromStr(vin.Txid)
+			if err != nil {
+				return nil, err
+			}
+
+			script, err := hex.DecodeString(vin.Prevout.ScriptPubKey.Hex)
+			if err != nil {
+				return nil, err
+			}
+
+			prevouts[wire.OutPoint{Hash: *txid, Index: vin.Vout}] = script
+		}
+	}
+
+	return prevouts, nil
+}
+
+// prevoutsCache keeps the prevouts of the blocks fetched by GetBlockByHeight,
+// so GetBlockPrevouts doesn't download the block again.
+// an entry is removed once read, the oldest ones are evicted above maxCachedPrevouts.
+type prevoutsCache struct {
+	mu       sync.Mutex
+	hashes   []chainhash.Hash
+	prevouts map[chainhash.Hash]domain.PrevoutScripts
+}
+
+func newPrevoutsCache() *prevoutsCache {
+	return &prevoutsCache{prevouts: make(map[chainhash.Hash]domain.PrevoutScripts)}
+}
+
+func (c *prevoutsCache) add(hash chainhash.Hash, prevouts domain.PrevoutScripts) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.prevouts[hash]; !ok {
+		c.hashes = append(c.hashes, hash)
+	}
+	c.prevouts[hash] = prevouts
+
+	for len(c.hashes) > maxCachedPrevouts {
+		delete(c.prevouts, c.hashes[0])
+		c.hashes = c.hashes[1:]
+	}
+}
+
+func (c *prevoutsCache) pop(hash chainhash.Hash) (domain.PrevoutScripts, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	prevouts, ok := c.prevouts[hash]
+	if !ok {
+		return nil, false
+	}
+
+	delete(c.prevouts, hash)
+	for i, h := range c.hashes {
+		if h == hash {
+			c.hashes = append(c.hashes[:i], c.hashes[i+1:]...)
+			break
+		}
+	}
+
+	return prevouts, true
+}
@@ -1,8 +1,11 @@
 package jsonrpc
 
 import (
-	"bytes"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/btcsuite/btcd/btcjson"
@@ -10,17 +13,28 @@ import (
 	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/rpcclient"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
 	"github.com/louisinger/silentiumd/internal/ports"
 	"github.com/sirupsen/logrus"
 )
 
-var blockFilterType = btcjson.FilterTypeBasic
+var (
+	blockFilterType = btcjson.FilterTypeBasic
+	pollingInterval = 1 * time.Minute
+)
 
 type clientRPC struct {
 	rpc *rpcclient.Client
+	// zmqEndpoint is the bitcoind zmqpubhashblock or zmqpubrawblock address.
+	// if empty, new blocks are discovered by polling.
+	zmqEndpoint string
+	prevouts    *prevoutsCache
+	// noPrevouts is set once bitcoind rejects getblock verbosity 3 (before v23),
+	// blocks are then fetched raw and the prevouts one by one with GetPrevoutScript.
+	noPrevouts atomic.Bool
 }
 
-func New(host, cookiePath string) (*clientRPC, error) {
+func New(host, cookiePath, zmqEndpoint string) (*clientRPC, error) {
 	rpc, err := rpcclient.New(&rpcclient.ConnConfig{
 		Host:         host,
 		CookiePath:   cookiePath,
@@ -31,10 +45,10 @@ func New(host, cookiePath string) (*clientRPC, error) {
 		return nil, err
 	}
 
-	return &clientRPC{rpc}, nil
+	return &clientRPC{rpc: rpc, zmqEndpoint: zmqEndpoint, prevouts: newPrevoutsCache()}, nil
 }
 
-func NewUnsafe(host, user, pass string) (*clientRPC, error) {
+func NewUnsafe(host, user, pass, zmqEndpoint string) (*clientRPC, error) {
 	rpc, err := rpcclient.New(&rpcclient.ConnConfig{
 		Host:         host,
 		User:         user,
@@ -46,30 +60,62 @@ func NewUnsafe(host, user, pass string) (*clientRPC, error) {
 		return nil, err
 	}
 
-	return &clientRPC{rpc}, nil
+	return &clientRPC{rpc: rpc, zmqEndpoint: zmqEndpoint, prevouts: newPrevoutsCache()}, nil
 }
 
 var _ ports.ChainSource = &clientRPC{}
 
+func (c *clientRPC) GetBlockHash(h int32) (*chainhash.Hash, error) {
+	return c.rpc.GetBlockHash(int64(h))
+}
+
+// GetBlockByHeight fetches the block with verbosity 3,
+// its prevouts are kept for the next GetBlockPrevouts call.
+// if bitcoind doesn't support it, the raw block is fetched without prevouts.
 func (c *clientRPC) GetBlockByHeight(h int32) (*btcutil.Block, error) {
-	hash, err := c.rpc.GetBlockHash(int64(h))
+	hash, err := c.GetBlockHash(h)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.noPrevouts.Load() {
+		block, err := c.getBlockWithPrevouts(hash, h)
+		if !errors.Is(err, ports.ErrPrevoutsNotSupported) {
+			return block, err
+		}
+
+		logrus.Warn("bitcoind does not support getblock verbosity 3 (v23+), fetching the prevouts one by one (requires txindex)")
+		c.noPrevouts.Store(true)
+	}
+
+	msgBlock, err := c.rpc.GetBlock(hash)
 	if err != nil {
 		return nil, err
 	}
 
-	block, err := c.rpc.GetBlock(hash)
+	block := btcutil.NewBlock(msgBlock)
+	block.SetHeight(h)
+	return block, nil
+}
+
+func (c *clientRPC) getBlockWithPrevouts(hash *chainhash.Hash, h int32) (*btcutil.Block, error) {
+	verbose, err := c.getVerboseBlock(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	prevouts, err := verbose.prevouts()
 	if err != nil {
 		return nil, err
 	}
 
-	var serializedBlock bytes.Buffer
-	if err := block.Serialize(&serializedBlock); err != nil {
+	block, err := verbose.block(h)
+	if err != nil {
 		return nil, err
 	}
 
-	b := btcutil.NewBlockFromBlockAndBytes(block, serializedBlock.Bytes())
-	b.SetHeight(h)
-	return b, nil
+	c.prevouts.add(*hash, prevouts)
+	return block, nil
 }
 
 func (c *clientRPC) GetBlockFilterByHeight(h int32) (string, string, error) {
@@ -88,6 +134,15 @@ func (c *clientRPC) GetBlockFilterByHeight(h int32) (string, string, error) {
 	return filter.Filter, hash.String(), nil
 }
 
+func (c *clientRPC) GetBlockFilter(hash chainhash.Hash) (string, error) {
+	filter, err := c.rpc.GetBlockFilter(hash, &blockFilterType)
+	if err != nil {
+		return "", err
+	}
+
+	return filter.Filter, nil
+}
+
 func (c *clientRPC) GetChainTipHeight() (int32, error) {
 	info, err := c.rpc.GetBlockChainInfo()
 	if err != nil {
@@ -97,6 +152,16 @@ func (c *clientRPC) GetChainTipHeight() (int32, error) {
 	return info.Blocks, nil
 }
 
+// getChainTip returns the height and the hash of the best block.
+func (c *clientRPC) getChainTip() (int32, string, error) {
+	info, err := c.rpc.GetBlockChainInfo()
+	if err != nil {
+		return 0, "", err
+	}
+
+	return info.Blocks, info.BestBlockHash, nil
+}
+
 func (c *clientRPC) GetPrevoutScript(outpoint wire.OutPoint) ([]byte, error) {
 	tx, err := c.rpc.GetRawTransaction(&outpoint.Hash)
 	if err != nil {
@@ -110,55 +175,141 @@ func (c *clientRPC) GetPrevoutScript(outpoint wire.OutPoint) ([]byte, error) {
 	return tx.MsgTx().TxOut[outpoint.Index].PkScript, nil
 }
 
+// GetBlockPrevouts returns the prevouts of a block fetched by GetBlockByHeight,
+// other blocks are fetched with verbosity 3 (bitcoind v23+), it does not require txindex.
+// ErrPrevoutsNotSupported is returned if bitcoind doesn't support it.
+func (c *clientRPC) GetBlockPrevouts(block *btcutil.Block) (domain.PrevoutScripts, error) {
+	if prevouts, ok := c.prevouts.pop(*block.Hash()); ok {
+		return prevouts, nil
+	}
+
+	if c.noPrevouts.Load() {
+		return nil, ports.ErrPrevoutsNotSupported
+	}
+
+	verbose, err := c.getVerboseBlock(block.Hash())
+	if err != nil {
+		if errors.Is(err, ports.ErrPrevoutsNotSupported) {
+			c.noPrevouts.Store(true)
+		}
+		return nil, err
+	}
+
+	prevouts, err := verbose.prevouts()
+	if errors.Is(err, ports.ErrPrevoutsNotSupported) {
+		c.noPrevouts.Store(true)
+	}
+	return prevouts, err
+}
+
+func (c *clientRPC) getVerboseBlock(hash *chainhash.Hash) (*verboseBlock, error) {
+	hashParam, err := json.Marshal(hash.String())
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.rpc.RawRequest("getblock", []json.RawMessage{hashParam, json.RawMessage("3")})
+	if err != nil {
+		var rpcErr *btcjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == btcjson.ErrRPCInvalidParameter {
+			return nil, ports.ErrPrevoutsNotSupported
+		}
+		return nil, err
+	}
+
+	var verbose verboseBlock
+	if err := json.Unmarshal(res, &verbose); err != nil {
+		return nil, err
+	}
+
+	return &verbose, nil
+}
+
 func (c *clientRPC) HasOneUnspent(txhash chainhash.Hash, outputsPkScript map[uint32][]byte, startBlock int32) (bool, error) {
 	panic("unimplemented")
 }
 
+// SubscribeBlocks sends the new blocks as soon as they are announced by zmq.
+// the chain tip is also polled every minute without notification,
+// it is the only source of new blocks if zmq is not configured.
+// a new tip at the same height (reorg) is sent too, the syncer rolls back the replaced block.
 func (c *clientRPC) SubscribeBlocks() (<-chan *btcutil.Block, func(), error) {
-	currentHeight, err := c.GetChainTipHeight()
+	currentHeight, currentHash, err := c.getChainTip()
 	if err != nil {
 		return nil, nil, err
 	}
 
-	ticker := time.NewTicker(1 * time.Minute)
+	var notifications <-chan chainhash.Hash
+	var notifier *zmqBlockNotifier
+
+	if c.zmqEndpoint != "" {
+		notifier = newZMQBlockNotifier(c.zmqEndpoint)
+		notifier.start()
+		notifications = notifier.notifications
+	}
+
+	ticker := time.NewTicker(pollingInterval)
 	quit := make(chan struct{})
+	var stopOnce sync.Once
 
 	blockChan := make(chan *btcutil.Block)
 
 	go func() {
 		defer close(blockChan)
 		defer ticker.Stop()
+		if notifier != nil {
+			defer notifier.stop()
+		}
 
 		for {
 			select {
 			case <-quit:
 				return
+			case hash := <-notifications:
+				logrus.Debugf("zmq: new block %s", hash)
+				ticker.Reset(pollingInterval)
 			case <-ticker.C:
-				newHeight, err := c.GetChainTipHeight()
+			}
+
+			newHeight, newHash, err := c.getChainTip()
+			if err != nil {
+				logrus.Error(err)
+				continue
+			}
+
+			if newHash == currentHash {
+				continue
+			}
+
+			// the tip replaced without height change is sent alone
+			from := currentHeight + 1
+			if newHeight < from {
+				from = newHeight
+			}
+
+			// the current tip only moves once the block is sent, a failed fetch is retried on the next tick
+			for h := from; h <= newHeight; h++ {
+				block, err := c.GetBlockByHeight(h)
 				if err != nil {
 					logrus.Error(err)
-					continue
+					break
 				}
 
-				if newHeight > currentHeight {
-					for h := currentHeight + 1; h <= newHeight; h++ {
-						block, err := c.GetBlockByHeight(h)
-						if err != nil {
-							logrus.Error(err)
-							continue
-						}
-
-						blockChan <- block
-					}
-					currentHeight = newHeight
+				// the hash is cached by the block, it is read before the receiver owns it
+				hash := block.Hash().String()
+
+				select {
+				case blockChan <- block:
+				case <-quit:
+					return
 				}
+				currentHeight, currentHash = h, hash
 			}
 		}
 	}()
 
 	return blockChan, func() {
-		quit <- struct{}{}
-		close(quit)
+		stopOnce.Do(func() { close(quit) })
 	}, nil
 }
 
@@ -170,3 +321,55 @@ func (c *clientRPC) IsUtxo(outpoint wire.OutPoint) (bool, error) {
 
 	return res != nil, nil
 }
+
+func (c *clientRPC) GetMempoolTxids() ([]chainhash.Hash, error) {
+	txids, err := c.rpc.GetRawMempool()
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]chainhash.Hash, 0, len(txids))
+	for _, txid := range txids {
+		result = append(result, *txid)
+	}
+
+	return result, nil
+}
+
+func (c *clientRPC) GetMempoolTx(txid chainhash.Hash) (*btcutil.Tx, error) {
+	return c.rpc.GetRawTransaction(&txid)
+}
+
+// GetMempoolPrevouts reads the confirmed prevouts from the UTXO set, it does not require txindex.
+// the outputs of unconfirmed parents are read from the mempool.
+func (c *clientRPC) GetMempoolPrevouts(tx *btcutil.Tx) (domain.PrevoutScripts, error) {
+	prevouts := make(domain.PrevoutScripts)
+
+	for _, input := range tx.MsgTx().TxIn {
+		outpoint := input.PreviousOutPoint
+
+		res, err := c.rpc.GetTxOut(&outpoint.Hash, outpoint.Index, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if res == nil {
+			script, err := c.GetPrevoutScript(outpoint)
+			if err != nil {
+				return nil, err
+			}
+
+			prevouts[outpoint] = script
+			continue
+		}
+
+		script, err := hex.DecodeString(res.ScriptPubKey.Hex)
+		if err != nil {
+			return nil, err
+		}
+
+		prevouts[outpoint] = script
+	}
+
+	return prevouts, nil
+}
@@ -0,0 +1,577 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBlockPrevouts(t *testing.T) {
+	prevoutTxid := "f4184fc596403b9d638783cf57adfe4c75c605f6356fbc91338530e9831e9e16"
+	prevoutScript := "76a91419c2f3ae0ca3b642bd3e49598b8da89f50c1416188ac"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, "getblock", req.Method)
+		require.Len(t, req.Params, 2)
+		require.Equal(t, "3", string(req.Params[1]))
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":    req.ID,
+			"error": nil,
+			"result": map[string]interface{}{
+				"tx": []interface{}{
+					map[string]interface{}{
+						"vin": []interface{}{
+							map[string]interface{}{"coinbase": "03ad3e0d", "sequence": 4294967295},
+						},
+					},
+					map[string]interface{}{
+						"vin": []interface{}{
+							map[string]interface{}{
+								"txid": prevoutTxid,
+								"vout": 3,
+								"prevout": map[string]interface{}{
+									"generated": false,
+									"height":    100,
+									"value":     0.1,
+									"scriptPubKey": map[string]interface{}{
+										"hex":  prevoutScript,
+										"type": "pubkeyhash",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewUnsafe(strings.TrimPrefix(server.URL, "http://"), "user", "pass", "")
+	require.NoError(t, err)
+
+	block := btcutil.NewBlock(chaincfg.RegressionNetParams.GenesisBlock)
+
+	prevouts, err := client.GetBlockPrevouts(block)
+	require.NoError(t, err)
+	require.Len(t, prevouts, 1)
+
+	outpoint, err := wire.NewOutPointFromString(prevoutTxid + ":3")
+	require.NoError(t, err)
+
+	script, err := prevouts.GetPrevoutScript(*outpoint)
+	require.NoError(t, err)
+	require.Equal(t, prevoutScript, hex.EncodeToString(script))
+}
+
+func TestGetBlockByHeight(t *testing.T) {
+	prevoutScript := []byte{0x51, 0x20, 0x01}
+	spent := wire.OutPoint{Hash: chainhash.Hash{0x01}, Index: 2}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&spent, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(1000, prevoutScript))
+
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{
+		Version:   4,
+		PrevBlock: *chaincfg.RegressionNetParams.GenesisHash,
+		Timestamp: time.Unix(1700000000, 0),
+		Bits:      0x207fffff,
+		Nonce:     7,
+	})
+	require.NoError(t, msgBlock.AddTransaction(chaincfg.RegressionNetParams.GenesisBlock.Transactions[0]))
+	require.NoError(t, msgBlock.AddTransaction(tx))
+
+	var getblockCalls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+
+		switch req.Method {
+		case "getblockhash":
+			result = msgBlock.BlockHash().String()
+		case "getblock":
+			require.Equal(t, "3", string(req.Params[1]))
+			getblockCalls++
+			result = verboseBlockResult(t, msgBlock, map[wire.OutPoint][]byte{spent: prevoutScript})
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     req.ID,
+			"error":  nil,
+			"result": result,
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewUnsafe(strings.TrimPrefix(server.URL, "http://"), "user", "pass", "")
+	require.NoError(t, err)
+
+	block, err := client.GetBlockByHeight(1)
+	require.NoError(t, err)
+	require.Equal(t, msgBlock.BlockHash(), *block.Hash())
+	require.Equal(t, int32(1), block.Height())
+	require.Len(t, block.Transactions(), 2)
+
+	// the prevouts come with the block
+	prevouts, err := client.GetBlockPrevouts(block)
+	require.NoError(t, err)
+	require.Equal(t, 1, getblockCalls)
+
+	script, err := prevouts.GetPrevoutScript(spent)
+	require.NoError(t, err)
+	require.Equal(t, prevoutScript, script)
+
+	// the cached prevouts are read once
+	_, err = client.GetBlockPrevouts(block)
+	require.NoError(t, err)
+	require.Equal(t, 2, getblockCalls)
+}
+
+func TestGetBlockByHeightWithoutVerbosity3(t *testing.T) {
+	prevTx := wire.NewMsgTx(2)
+	prevTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{0x01}}, nil, nil))
+	prevTx.AddTxOut(wire.NewTxOut(1000, []byte{0x51, 0x20, 0x01}))
+	spent := wire.OutPoint{Hash: prevTx.TxHash(), Index: 0}
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&spent, nil, nil))
+	tx.AddTxOut(wire.NewTxOut(900, []byte{0x51, 0x20, 0x02}))
+
+	msgBlock := wire.NewMsgBlock(&wire.BlockHeader{PrevBlock: *chaincfg.RegressionNetParams.GenesisHash})
+	require.NoError(t, msgBlock.AddTransaction(chaincfg.RegressionNetParams.GenesisBlock.Transactions[0]))
+	require.NoError(t, msgBlock.AddTransaction(tx))
+
+	tests := []struct {
+		name string
+		// verbosity3 answers getblock with verbosity 3
+		verbosity3 func(t *testing.T) (result, rpcErr interface{})
+	}{
+		{
+			name: "rejected",
+			verbosity3: func(t *testing.T) (interface{}, interface{}) {
+				return nil, map[string]interface{}{"code": -8, "message": "Verbosity was out of range"}
+			},
+		},
+		{
+			// bitcoind before v23 handles verbosity 3 as 2
+			name: "without prevouts",
+			verbosity3: func(t *testing.T) (interface{}, interface{}) {
+				return verboseBlockResult(t, msgBlock, nil), nil
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			verbosity3Calls := 0
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					ID     json.RawMessage   `json:"id"`
+					Method string            `json:"method"`
+					Params []json.RawMessage `json:"params"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+				var result interface{}
+				var rpcErr interface{}
+
+				switch req.Method {
+				case "getblockhash":
+					result = msgBlock.BlockHash().String()
+				case "getblock":
+					if string(req.Params[1]) == "3" {
+						verbosity3Calls++
+						result, rpcErr = tt.verbosity3(t)
+						break
+					}
+
+					require.Equal(t, "0", string(req.Params[1]))
+					var buf bytes.Buffer
+					require.NoError(t, msgBlock.Serialize(&buf))
+					result = hex.EncodeToString(buf.Bytes())
+				case "getrawtransaction":
+					var buf bytes.Buffer
+					require.NoError(t, prevTx.Serialize(&buf))
+					result = hex.EncodeToString(buf.Bytes())
+				default:
+					t.Errorf("unexpected method %s", req.Method)
+				}
+
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"id":     req.ID,
+					"error":  rpcErr,
+					"result": result,
+				})
+			}))
+			defer server.Close()
+
+			client, err := NewUnsafe(strings.TrimPrefix(server.URL, "http://"), "user", "pass", "")
+			require.NoError(t, err)
+
+			for i := 0; i < 2; i++ {
+				block, err := client.GetBlockByHeight(1)
+				require.NoError(t, err)
+				require.Equal(t, msgBlock.BlockHash(), *block.Hash())
+				require.Equal(t, int32(1), block.Height())
+
+				// the syncer falls back to GetPrevoutScript
+				_, err = client.GetBlockPrevouts(block)
+				require.ErrorIs(t, err, ports.ErrPrevoutsNotSupported)
+			}
+
+			// verbosity 3 is not requested again
+			require.Equal(t, 1, verbosity3Calls)
+
+			script, err := client.GetPrevoutScript(spent)
+			require.NoError(t, err)
+			require.Equal(t, prevTx.TxOut[0].PkScript, script)
+		})
+	}
+}
+
+func TestGetMempoolPrevouts(t *testing.T) {
+	confirmedScript := "0014751e76e8199196d454941c45d1b3a323f1433bd6"
+	unconfirmedScript := "51201e2b46ff1a93fd9ea1ad79390a785a29aac94a9da0c7b07bbbc2a1b9ec4b4e3e"
+
+	unconfirmedScriptBytes, err := hex.DecodeString(unconfirmedScript)
+	require.NoError(t, err)
+
+	parentTx := wire.NewMsgTx(2)
+	parentTx.AddTxIn(wire.NewTxIn(&wire.OutPoint{Hash: chainhash.Hash{0x01}}, nil, nil))
+	parentTx.AddTxOut(wire.NewTxOut(1000, unconfirmedScriptBytes))
+
+	var parentTxBuf bytes.Buffer
+	require.NoError(t, parentTx.Serialize(&parentTxBuf))
+
+	confirmed := wire.OutPoint{Hash: chainhash.Hash{0x02}, Index: 1}
+	unconfirmed := wire.OutPoint{Hash: parentTx.TxHash(), Index: 0}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		var result interface{}
+
+		switch req.Method {
+		case "gettxout":
+			require.Equal(t, "false", string(req.Params[2]))
+
+			if string(req.Params[0]) == `"`+confirmed.Hash.String()+`"` {
+				result = map[string]interface{}{
+					"scriptPubKey": map[string]interface{}{"hex": confirmedScript},
+				}
+			}
+		case "getrawtransaction":
+			require.Equal(t, `"`+unconfirmed.Hash.String()+`"`, string(req.Params[0]))
+			result = hex.EncodeToString(parentTxBuf.Bytes())
+		default:
+			t.Fatalf("unexpected method %s", req.Method)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     req.ID,
+			"error":  nil,
+			"result": result,
+		})
+	}))
+	defer server.Close()
+
+	client, err := NewUnsafe(strings.TrimPrefix(server.URL, "http://"), "user", "pass", "")
+	require.NoError(t, err)
+
+	tx := wire.NewMsgTx(2)
+	tx.AddTxIn(wire.NewTxIn(&confirmed, nil, nil))
+	tx.AddTxIn(wire.NewTxIn(&unconfirmed, nil, nil))
+
+	prevouts, err := client.GetMempoolPrevouts(btcutil.NewTx(tx))
+	require.NoError(t, err)
+	require.Len(t, prevouts, 2)
+
+	script, err := prevouts.GetPrevoutScript(confirmed)
+	require.NoError(t, err)
+	require.Equal(t, confirmedScript, hex.EncodeToString(script))
+
+	script, err = prevouts.GetPrevoutScript(unconfirmed)
+	require.NoError(t, err)
+	require.Equal(t, unconfirmedScript, hex.EncodeToString(script))
+}
+
+func TestSubscribeBlocksSameHeightReorg(t *testing.T) {
+	setPollingInterval(t, 10*time.Millisecond)
+
+	node := newFakeNode(t, 1)
+	client := node.client(t)
+
+	blocks, cancel, err := client.SubscribeBlocks()
+	require.NoError(t, err)
+	defer cancel()
+
+	// the tip is replaced by a block at the same height
+	replaced := node.replaceTip(t)
+
+	select {
+	case block := <-blocks:
+		require.Equal(t, replaced.BlockHash(), *block.Hash())
+		require.Equal(t, int32(1), block.Height())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new tip has not been sent")
+	}
+}
+
+func TestSubscribeBlocksFetchError(t *testing.T) {
+	setPollingInterval(t, 10*time.Millisecond)
+
+	node := newFakeNode(t, 1)
+	client := node.client(t)
+
+	blocks, cancel, err := client.SubscribeBlocks()
+	require.NoError(t, err)
+
+	// the new block can't be fetched at first, it is sent once available
+	node.lock.Lock()
+	node.getblockFailures = 3
+	node.lock.Unlock()
+	added := node.addBlock(t)
+
+	select {
+	case block := <-blocks:
+		require.Equal(t, added.BlockHash(), *block.Hash())
+		require.Equal(t, int32(2), block.Height())
+	case <-time.After(5 * time.Second):
+		t.Fatal("the new block has not been sent")
+	}
+
+	// cancel doesn't wait for a reader of the pending block
+	node.addBlock(t)
+	time.Sleep(50 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		cancel()
+		cancel()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancel is blocked")
+	}
+
+	require.Eventually(t, func() bool {
+		select {
+		case _, ok := <-blocks:
+			return !ok
+		default:
+			return false
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func setPollingInterval(t *testing.T, interval time.Duration) {
+	defaultInterval := pollingInterval
+	pollingInterval = interval
+	t.Cleanup(func() { pollingInterval = defaultInterval })
+}
+
+// fakeNode answers the bitcoind rpc requests of SubscribeBlocks, blocks[0] is at height 1.
+type fakeNode struct {
+	server *httptest.Server
+
+	lock             sync.Mutex
+	blocks           []*wire.MsgBlock
+	nonce            uint32
+	getblockFailures int
+}
+
+func newFakeNode(t *testing.T, height int) *fakeNode {
+	node := &fakeNode{}
+	for i := 0; i < height; i++ {
+		node.addBlock(t)
+	}
+
+	node.server = httptest.NewServer(http.HandlerFunc(node.handle(t)))
+	t.Cleanup(node.server.Close)
+
+	return node
+}
+
+func (n *fakeNode) client(t *testing.T) *clientRPC {
+	client, err := NewUnsafe(strings.TrimPrefix(n.server.URL, "http://"), "user", "pass", "")
+	require.NoError(t, err)
+	return client
+}
+
+func (n *fakeNode) newBlock(t *testing.T, prevBlock chainhash.Hash) *wire.MsgBlock {
+	n.nonce++
+	block := wire.NewMsgBlock(&wire.BlockHeader{PrevBlock: prevBlock, Nonce: n.nonce})
+	require.NoError(t, block.AddTransaction(chaincfg.RegressionNetParams.GenesisBlock.Transactions[0]))
+	return block
+}
+
+// addBlock extends the chain by one block.
+func (n *fakeNode) addBlock(t *testing.T) *wire.MsgBlock {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	prevBlock := *chaincfg.RegressionNetParams.GenesisHash
+	if len(n.blocks) > 0 {
+		prevBlock = n.blocks[len(n.blocks)-1].BlockHash()
+	}
+
+	block := n.newBlock(t, prevBlock)
+	n.blocks = append(n.blocks, block)
+	return block
+}
+
+// replaceTip replaces the tip by another block at the same height.
+func (n *fakeNode) replaceTip(t *testing.T) *wire.MsgBlock {
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	tip := n.blocks[len(n.blocks)-1]
+	block := n.newBlock(t, tip.Header.PrevBlock)
+	n.blocks[len(n.blocks)-1] = block
+	return block
+}
+
+func (n *fakeNode) handle(t *testing.T) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		n.lock.Lock()
+		defer n.lock.Unlock()
+
+		var result interface{}
+		var rpcErr interface{}
+
+		switch req.Method {
+		case "getinfo":
+			// not a btcd backend
+			rpcErr = map[string]interface{}{"code": -32601, "message": "Method not found"}
+		case "getnetworkinfo":
+			result = map[string]interface{}{"subversion": "/Satoshi:25.0.0/"}
+		case "getblockchaininfo":
+			tip := n.blocks[len(n.blocks)-1]
+			result = map[string]interface{}{"blocks": len(n.blocks), "bestblockhash": tip.BlockHash().String()}
+		case "getblockhash":
+			var height int
+			require.NoError(t, json.Unmarshal(req.Params[0], &height))
+			result = n.blocks[height-1].BlockHash().String()
+		case "getblock":
+			if n.getblockFailures > 0 {
+				n.getblockFailures--
+				rpcErr = map[string]interface{}{"code": -1, "message": "node busy"}
+				break
+			}
+
+			var hash string
+			require.NoError(t, json.Unmarshal(req.Params[0], &hash))
+			for _, block := range n.blocks {
+				if block.BlockHash().String() == hash {
+					result = verboseBlockResult(t, block, nil)
+				}
+			}
+		default:
+			t.Errorf("unexpected method %s", req.Method)
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":     req.ID,
+			"error":  rpcErr,
+			"result": result,
+		})
+	}
+}
+
+// verboseBlockResult returns the getblock verbosity 3 result of the block,
+// the inputs spending the given outpoints have a prevout.
+func verboseBlockResult(t *testing.T, block *wire.MsgBlock, prevouts map[wire.OutPoint][]byte) map[string]interface{} {
+	t.Helper()
+
+	txs := make([]interface{}, 0, len(block.Transactions))
+	for _, tx := range block.Transactions {
+		var buf bytes.Buffer
+		require.NoError(t, tx.Serialize(&buf))
+
+		vins := make([]interface{}, 0, len(tx.TxIn))
+		for _, in := range tx.TxIn {
+			if blockchain.IsCoinBaseTx(tx) {
+				vins = append(vins, map[string]interface{}{"coinbase": hex.EncodeToString(in.SignatureScript)})
+				continue
+			}
+
+			vin := map[string]interface{}{
+				"txid": in.PreviousOutPoint.Hash.String(),
+				"vout": in.PreviousOutPoint.Index,
+			}
+
+			if script, ok := prevouts[in.PreviousOutPoint]; ok {
+				vin["prevout"] = map[string]interface{}{
+					"scriptPubKey": map[string]interface{}{"hex": hex.EncodeToString(script)},
+				}
+			}
+
+			vins = append(vins, vin)
+		}
+
+		txs = append(txs, map[string]interface{}{
+			"hex": hex.EncodeToString(buf.Bytes()),
+			"vin": vins,
+		})
+	}
+
+	return map[string]interface{}{
+		"hash":              block.BlockHash().String(),
+		"version":           block.Header.Version,
+		"previousblockhash": block.Header.PrevBlock.String(),
+		"merkleroot":        block.Header.MerkleRoot.String(),
+		"time":              block.Header.Timestamp.Unix(),
+		"bits":              fmt.Sprintf("%08x", block.Header.Bits),
+		"nonce":             block.Header.Nonce,
+		"tx":                txs,
+	}
+}
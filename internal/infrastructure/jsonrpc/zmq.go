@@ -0,0 +1,140 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/go-zeromq/zmq4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	hashBlockTopic = "hashblock"
+	rawBlockTopic  = "rawblock"
+)
+
+var defaultZMQReconnectDelay = 5 * time.Second
+
+// zmqBlockNotifier subscribes to the hashblock and rawblock topics of a bitcoind ZMQ publisher
+// (zmqpubhashblock / zmqpubrawblock) and sends the hash of every announced block.
+// the subscription is re-established if the connection fails.
+type zmqBlockNotifier struct {
+	endpoint       string
+	reconnectDelay time.Duration
+	notifications  chan chainhash.Hash
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newZMQBlockNotifier(endpoint string) *zmqBlockNotifier {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &zmqBlockNotifier{
+		endpoint:       endpoint,
+		reconnectDelay: defaultZMQReconnectDelay,
+		notifications:  make(chan chainhash.Hash),
+		ctx:            ctx,
+		cancel:         cancel,
+	}
+}
+
+func (n *zmqBlockNotifier) start() {
+	go n.run()
+}
+
+func (n *zmqBlockNotifier) stop() {
+	n.cancel()
+}
+
+func (n *zmqBlockNotifier) run() {
+	defer close(n.notifications)
+
+	for {
+		if err := n.listen(); err != nil && n.ctx.Err() == nil {
+			logrus.Warnf("zmq subscription to %s failed: %s, reconnecting in %s", n.endpoint, err, n.reconnectDelay)
+		}
+
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-time.After(n.reconnectDelay):
+		}
+	}
+}
+
+// listen dials the publisher and forwards the notifications until the connection fails.
+func (n *zmqBlockNotifier) listen() error {
+	sub := zmq4.NewSub(n.ctx)
+	defer sub.Close()
+
+	if err := sub.Dial(n.endpoint); err != nil {
+		return err
+	}
+
+	for _, topic := range []string{hashBlockTopic, rawBlockTopic} {
+		if err := sub.SetOption(zmq4.OptionSubscribe, topic); err != nil {
+			return err
+		}
+	}
+
+	logrus.Infof("subscribed to zmq block notifications at %s", n.endpoint)
+
+	for {
+		msg, err := sub.Recv()
+		if err != nil {
+			return err
+		}
+
+		hash, err := parseBlockNotification(msg)
+		if err != nil {
+			logrus.Warn(err)
+			continue
+		}
+
+		select {
+		case n.notifications <- *hash:
+		case <-n.ctx.Done():
+			return n.ctx.Err()
+		}
+	}
+}
+
+// parseBlockNotification returns the block hash announced by a zmq message.
+// messages are made of 3 frames: topic, body and sequence number.
+func parseBlockNotification(msg zmq4.Msg) (*chainhash.Hash, error) {
+	if len(msg.Frames) < 2 {
+		return nil, fmt.Errorf("invalid zmq message: expected at least 2 frames, got %d", len(msg.Frames))
+	}
+
+	topic, body := string(msg.Frames[0]), msg.Frames[1]
+
+	switch topic {
+	case hashBlockTopic:
+		if len(body) != chainhash.HashSize {
+			return nil, fmt.Errorf("invalid hashblock body length: %d", len(body))
+		}
+
+		// bitcoind publishes the hash in reversed (rpc) byte order
+		reversed := make([]byte, chainhash.HashSize)
+		for i, b := range body {
+			reversed[chainhash.HashSize-1-i] = b
+		}
+
+		return chainhash.NewHash(reversed)
+	case rawBlockTopic:
+		var header wire.BlockHeader
+		if err := header.Deserialize(bytes.NewReader(body)); err != nil {
+			return nil, fmt.Errorf("invalid rawblock body: %s", err)
+		}
+
+		hash := header.BlockHash()
+		return &hash, nil
+	default:
+		return nil, fmt.Errorf("unexpected zmq topic: %s", topic)
+	}
+}
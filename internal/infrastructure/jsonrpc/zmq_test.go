@@ -0,0 +1,116 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/go-zeromq/zmq4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBlockNotification(t *testing.T) {
+	genesis := chaincfg.MainNetParams.GenesisBlock
+	genesisHash := genesis.BlockHash()
+
+	var rawBlock bytes.Buffer
+	require.NoError(t, genesis.Serialize(&rawBlock))
+
+	hash, err := parseBlockNotification(hashBlockMsg(genesisHash))
+	require.NoError(t, err)
+	require.Equal(t, genesisHash, *hash)
+
+	hash, err = parseBlockNotification(zmq4.NewMsgFrom(
+		[]byte(rawBlockTopic), rawBlock.Bytes(), []byte{0, 0, 0, 0},
+	))
+	require.NoError(t, err)
+	require.Equal(t, genesisHash, *hash)
+
+	_, err = parseBlockNotification(zmq4.NewMsgFrom([]byte(hashBlockTopic), []byte{0x01}))
+	require.Error(t, err)
+
+	_, err = parseBlockNotification(zmq4.NewMsgFrom([]byte("hashtx"), genesisHash[:]))
+	require.Error(t, err)
+}
+
+func TestZMQBlockNotifier(t *testing.T) {
+	endpoint := "tcp://" + freeAddress(t)
+
+	publisher := newTestPublisher(t, endpoint)
+
+	notifier := newZMQBlockNotifier(endpoint)
+	notifier.reconnectDelay = 100 * time.Millisecond
+	notifier.start()
+	defer notifier.stop()
+
+	hash := randomHash(t)
+	require.Equal(t, hash, publishUntilNotified(t, publisher, notifier, hash))
+
+	// the notifier must reconnect once the publisher is back
+	publisher.Close()
+	publisher = newTestPublisher(t, endpoint)
+	defer publisher.Close()
+
+	hash = randomHash(t)
+	require.Equal(t, hash, publishUntilNotified(t, publisher, notifier, hash))
+}
+
+// publishUntilNotified re-sends the notification until the subscriber receives it,
+// zmq drops the messages published before the subscription is established.
+func publishUntilNotified(
+	t *testing.T, publisher zmq4.Socket, notifier *zmqBlockNotifier, hash chainhash.Hash,
+) chainhash.Hash {
+	t.Helper()
+
+	timeout := time.After(10 * time.Second)
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case received := <-notifier.notifications:
+			return received
+		case <-ticker.C:
+			_ = publisher.Send(hashBlockMsg(hash))
+		case <-timeout:
+			t.Fatal("no zmq notification received")
+		}
+	}
+}
+
+func newTestPublisher(t *testing.T, endpoint string) zmq4.Socket {
+	t.Helper()
+
+	publisher := zmq4.NewPub(context.Background())
+	require.NoError(t, publisher.Listen(endpoint))
+	return publisher
+}
+
+func hashBlockMsg(hash chainhash.Hash) zmq4.Msg {
+	body := make([]byte, chainhash.HashSize)
+	for i, b := range hash {
+		body[chainhash.HashSize-1-i] = b
+	}
+
+	return zmq4.NewMsgFrom([]byte(hashBlockTopic), body, []byte{0, 0, 0, 0})
+}
+
+func randomHash(t *testing.T) chainhash.Hash {
+	var hash chainhash.Hash
+	_, err := rand.Read(hash[:])
+	require.NoError(t, err)
+	return hash
+}
+
+func freeAddress(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer lis.Close()
+
+	return lis.Addr().String()
+}
@@ -14,8 +14,12 @@ import (
 type Config struct {
 	Port       uint32
 	AppService application.SilentiumService
-	TLSKey     string
-	TLSCert    string
+	// ScanService is served only if set, it is disabled by default.
+	ScanService application.ScanService
+	TLSKey      string
+	TLSCert     string
+	// WSAllowedOrigins are the browser origins allowed on the websocket besides the same origin.
+	WSAllowedOrigins []string
 }
 
 func (c Config) Validate() error {
@@ -25,6 +29,11 @@ func (c Config) Validate() error {
 	}
 	defer lis.Close()
 
+	// the scan private keys must not be sent in plaintext, on gRPC or through the gateway
+	if c.ScanService != nil && c.insecure() {
+		return errors.New("scan service requires tls")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	silentiumv1 "github.com/louisinger/silentiumd/api/protobuf/gen/silentium/v1"
+	"github.com/louisinger/silentiumd/internal/application"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type scanHandler struct {
+	svc application.ScanService
+}
+
+func NewScanHandler(service application.ScanService) silentiumv1.ScanServiceServer {
+	return &scanHandler{service}
+}
+
+func (h *scanHandler) Register(ctx context.Context, req *silentiumv1.RegisterRequest) (*silentiumv1.RegisterResponse, error) {
+	scanKeyBytes, err := hex.DecodeString(req.GetScanPrivateKey())
+	if err != nil || len(scanKeyBytes) != btcec.PrivKeyBytesLen {
+		return nil, status.Error(codes.InvalidArgument, "invalid scan private key, must be 32 hex-encoded bytes")
+	}
+
+	scanKey, _ := btcec.PrivKeyFromBytes(scanKeyBytes)
+	// the decoded key is copied, only the service keeps it
+	for i := range scanKeyBytes {
+		scanKeyBytes[i] = 0
+	}
+
+	if scanKey.Key.IsZero() {
+		return nil, status.Error(codes.InvalidArgument, "invalid scan private key")
+	}
+
+	spendKeyBytes, err := hex.DecodeString(req.GetSpendPublicKey())
+	if err != nil {
+		scanKey.Zero()
+		return nil, status.Error(codes.InvalidArgument, "invalid spend public key, must be hex-encoded")
+	}
+
+	spendKey, err := btcec.ParsePubKey(spendKeyBytes)
+	if err != nil {
+		scanKey.Zero()
+		return nil, status.Errorf(codes.InvalidArgument, "invalid spend public key: %s", err)
+	}
+
+	id, expiresAt, err := h.svc.Register(scanKey, spendKey, req.GetLabels(), req.GetBirthday())
+	if err != nil {
+		scanKey.Zero()
+		return nil, toScanError(err)
+	}
+
+	return &silentiumv1.RegisterResponse{
+		Id:        id,
+		ExpiresAt: expiresAt.Unix(),
+	}, nil
+}
+
+func (h *scanHandler) Unregister(ctx context.Context, req *silentiumv1.UnregisterRequest) (*silentiumv1.UnregisterResponse, error) {
+	if err := h.svc.Unregister(req.GetId()); err != nil {
+		return nil, toScanError(err)
+	}
+
+	return &silentiumv1.UnregisterResponse{}, nil
+}
+
+func (h *scanHandler) GetScanOutputs(ctx context.Context, req *silentiumv1.GetScanOutputsRequest) (*silentiumv1.GetScanOutputsResponse, error) {
+	outputs, scannedHeight, err := h.svc.GetOutputs(req.GetId(), req.GetFrom())
+	if err != nil {
+		return nil, toScanError(err)
+	}
+
+	// the birthday of a registration is above the scanned height until its first block is scanned
+	if scannedHeight < 0 {
+		scannedHeight = 0
+	}
+
+	return &silentiumv1.GetScanOutputsResponse{
+		Outputs:       toScanOutputs(outputs),
+		ScannedHeight: uint32(scannedHeight),
+	}, nil
+}
+
+func (h *scanHandler) SubscribeScanOutputs(req *silentiumv1.SubscribeScanOutputsRequest, stream silentiumv1.ScanService_SubscribeScanOutputsServer) error {
+	events, err := h.svc.SubscribeOutputs(stream.Context(), req.GetId(), req.GetFrom())
+	if err != nil {
+		return toScanError(err)
+	}
+
+	if err := stream.SendHeader(metadata.Pairs(SubscriptionStartedHeader, "true")); err != nil {
+		return err
+	}
+
+	for event := range events {
+		if err := stream.Send(toSubscribeScanOutputsResponse(event)); err != nil {
+			return err
+		}
+	}
+
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	return status.Error(codes.Unavailable, "subscription closed")
+}
+
+func toScanError(err error) error {
+	switch {
+	case errors.Is(err, application.ErrScanKeyNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, application.ErrTooManyScanKeys):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, application.ErrTooManyScanLabels), errors.Is(err, application.ErrInvalidBlockRange):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return err
+	}
+}
+
+func toScanOutputs(outputs []application.ScanOutput) []*silentiumv1.ScanOutput {
+	res := make([]*silentiumv1.ScanOutput, 0, len(outputs))
+	for _, output := range outputs {
+		// blocks indexed before the hashes were stored have no hash
+		blockhash := ""
+		if output.BlockHash != (chainhash.Hash{}) {
+			blockhash = output.BlockHash.String()
+		}
+
+		res = append(res, &silentiumv1.ScanOutput{
+			Height:    uint32(output.Height),
+			Blockhash: blockhash,
+			Txid:      output.TxHash.String(),
+			Index:     output.Index,
+			Key:       hex.EncodeToString(output.Key),
+			Amount:    uint64(output.Value),
+			Tweak:     hex.EncodeToString(output.Tweak),
+			Label:     output.Label,
+		})
+	}
+
+	return res
+}
+
+func toSubscribeScanOutputsResponse(event application.ScanEvent) *silentiumv1.SubscribeScanOutputsResponse {
+	if event.Type == application.ScanReorg {
+		return &silentiumv1.SubscribeScanOutputsResponse{
+			Event: &silentiumv1.SubscribeScanOutputsResponse_Reorg{
+				Reorg: &silentiumv1.ReorgEvent{
+					ForkHeight: uint32(event.ForkHeight),
+				},
+			},
+		}
+	}
+
+	return &silentiumv1.SubscribeScanOutputsResponse{
+		Event: &silentiumv1.SubscribeScanOutputsResponse_Outputs{
+			Outputs: &silentiumv1.ScanOutputsEvent{
+				Height:  uint32(event.Height),
+				Outputs: toScanOutputs(event.Outputs),
+			},
+		},
+	}
+}
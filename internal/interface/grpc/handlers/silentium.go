@@ -2,15 +2,27 @@ package handlers
 
 import (
 	"context"
+	"encoding/hex"
+	"errors"
 
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	silentiumv1 "github.com/louisinger/silentiumd/api/protobuf/gen/silentium/v1"
 	"github.com/louisinger/silentiumd/internal/application"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 type handler struct {
 	svc application.SilentiumService
 }
 
+// SubscriptionStartedHeader is sent by SubscribeScalars once the subscription is started,
+// the failed subscriptions end with a trailers-only response without it.
+const SubscriptionStartedHeader = "x-subscription-started"
+
 func NewHandler(service application.SilentiumService) silentiumv1.SilentiumServiceServer {
 	return &handler{service}
 }
@@ -27,8 +39,38 @@ func (h *handler) GetBlockFilter(ctx context.Context, req *silentiumv1.GetBlockF
 	}, nil
 }
 
+func (h *handler) GetBlockTaprootFilter(ctx context.Context, req *silentiumv1.GetBlockTaprootFilterRequest) (*silentiumv1.GetBlockTaprootFilterResponse, error) {
+	filter, blockhash, err := h.svc.GetBlockTaprootFilter(req.GetBlockId())
+	if err != nil {
+		if errors.As(err, &ports.ErrBlockNotFound{}) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, err
+	}
+
+	return &silentiumv1.GetBlockTaprootFilterResponse{
+		Blockhash: blockhash,
+		Filter:    filter,
+	}, nil
+}
+
 func (h *handler) GetBlockScalars(ctx context.Context, req *silentiumv1.GetBlockScalarsRequest) (*silentiumv1.GetBlockScalarsResponse, error) {
-	scalars, err := h.svc.GetScalarsByHeight(uint32(req.GetBlockId()))
+	filter, err := parseScalarsFilter(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	dustLimit, err := parseDustLimit(req.GetDustLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	if req.GetVerbose() {
+		return h.getBlockTransactions(req.GetBlockId(), filter, dustLimit)
+	}
+
+	scalars, err := h.svc.GetScalarsByHeight(uint32(req.GetBlockId()), filter, dustLimit)
 	if err != nil {
 		return nil, err
 	}
@@ -39,6 +81,125 @@ func (h *handler) GetBlockScalars(ctx context.Context, req *silentiumv1.GetBlock
 	return res, nil
 }
 
+func (h *handler) getBlockTransactions(height uint32, filter ports.ScalarsFilter, dustLimit int64) (*silentiumv1.GetBlockScalarsResponse, error) {
+	silentScalars, err := h.svc.GetTransactionsByHeight(height, filter, dustLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &silentiumv1.GetBlockScalarsResponse{
+		Scalars:      make([]string, 0, len(silentScalars)),
+		Transactions: make([]*silentiumv1.TxScalar, 0, len(silentScalars)),
+	}
+
+	for _, silentScalar := range silentScalars {
+		scalar := hex.EncodeToString(silentScalar.Scalar)
+
+		outputs := make([]*silentiumv1.TaprootOutput, 0, len(silentScalar.TaprootOutputs))
+		for _, out := range silentScalar.TaprootOutputs {
+			outputs = append(outputs, &silentiumv1.TaprootOutput{
+				Index:  out.Index,
+				Key:    hex.EncodeToString(out.Key),
+				Amount: uint64(out.Value),
+				Spent:  out.Spent,
+			})
+		}
+
+		res.Scalars = append(res.Scalars, scalar)
+		res.Transactions = append(res.Transactions, &silentiumv1.TxScalar{
+			Txid:    silentScalar.TxHash.String(),
+			Scalar:  scalar,
+			Outputs: outputs,
+		})
+	}
+
+	return res, nil
+}
+
+func (h *handler) GetBlockScalarsRange(ctx context.Context, req *silentiumv1.GetBlockScalarsRangeRequest) (*silentiumv1.GetBlockScalarsRangeResponse, error) {
+	filter, err := parseScalarsFilter(req.GetFilter())
+	if err != nil {
+		return nil, err
+	}
+
+	dustLimit, err := parseDustLimit(req.GetDustLimit())
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, next, err := h.svc.GetScalarsRange(req.GetFrom(), req.GetTo(), req.GetLimit(), filter, dustLimit)
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidBlockRange) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, err
+	}
+
+	res := &silentiumv1.GetBlockScalarsRangeResponse{
+		Blocks: make([]*silentiumv1.BlockScalars, 0, len(blocks)),
+		Next:   next,
+	}
+
+	for _, block := range blocks {
+		// blocks indexed before the hashes were stored have no hash
+		blockhash := ""
+		if block.Hash != (chainhash.Hash{}) {
+			blockhash = block.Hash.String()
+		}
+
+		res.Blocks = append(res.Blocks, &silentiumv1.BlockScalars{
+			Height:    uint32(block.Height),
+			Blockhash: blockhash,
+			Scalars:   block.Scalars,
+		})
+	}
+
+	return res, nil
+}
+
+func (h *handler) SubscribeScalars(req *silentiumv1.SubscribeScalarsRequest, stream silentiumv1.SilentiumService_SubscribeScalarsServer) error {
+	events, err := h.svc.SubscribeScalars(stream.Context(), req.GetFrom())
+	if err != nil {
+		if errors.Is(err, application.ErrInvalidBlockRange) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return err
+	}
+
+	// the header tells the client the subscription is started before the first event
+	if err := stream.SendHeader(metadata.Pairs(SubscriptionStartedHeader, "true")); err != nil {
+		return err
+	}
+
+	for event := range events {
+		if err := stream.Send(toSubscribeScalarsResponse(event)); err != nil {
+			return err
+		}
+	}
+
+	if err := stream.Context().Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+
+	return status.Error(codes.Unavailable, "subscription closed")
+}
+
+func (h *handler) GetMempoolScalars(ctx context.Context, req *silentiumv1.GetMempoolScalarsRequest) (*silentiumv1.GetMempoolScalarsResponse, error) {
+	scalars, err := h.svc.GetMempoolScalars()
+	if err != nil {
+		if errors.Is(err, application.ErrMempoolDisabled) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return nil, err
+	}
+
+	return &silentiumv1.GetMempoolScalarsResponse{
+		Scalars: scalars,
+	}, nil
+}
+
 func (h *handler) GetChainTipHeight(_ context.Context, req *silentiumv1.GetChainTipHeightRequest) (*silentiumv1.GetChainTipHeightResponse, error) {
 	tip, err := h.svc.GetChainTip()
 	if err != nil {
@@ -49,3 +210,46 @@ func (h *handler) GetChainTipHeight(_ context.Context, req *silentiumv1.GetChain
 		Height: tip,
 	}, nil
 }
+
+// parseScalarsFilter maps the filter request parameter, empty defaults to unspent.
+func parseScalarsFilter(filter string) (ports.ScalarsFilter, error) {
+	switch filter {
+	case "", "unspent":
+		return ports.UnspentScalars, nil
+	case "all":
+		return ports.AllScalars, nil
+	default:
+		return 0, status.Errorf(codes.InvalidArgument, "invalid filter: %s, must be unspent or all", filter)
+	}
+}
+
+func parseDustLimit(dustLimit uint64) (int64, error) {
+	if dustLimit > btcutil.MaxSatoshi {
+		return 0, status.Errorf(codes.InvalidArgument, "invalid dust limit: %d, must be at most %d", dustLimit, int64(btcutil.MaxSatoshi))
+	}
+
+	return int64(dustLimit), nil
+}
+
+func toSubscribeScalarsResponse(event application.ChainEvent) *silentiumv1.SubscribeScalarsResponse {
+	if event.Type == application.ChainReorg {
+		return &silentiumv1.SubscribeScalarsResponse{
+			Event: &silentiumv1.SubscribeScalarsResponse_Reorg{
+				Reorg: &silentiumv1.ReorgEvent{
+					ForkHeight: uint32(event.ForkHeight),
+				},
+			},
+		}
+	}
+
+	return &silentiumv1.SubscribeScalarsResponse{
+		Event: &silentiumv1.SubscribeScalarsResponse_Block{
+			Block: &silentiumv1.BlockScalarsEvent{
+				Height:    uint32(event.Block.Height),
+				Blockhash: event.Block.Hash.String(),
+				Scalars:   event.Block.Scalars,
+				Filter:    event.Filter,
+			},
+		},
+	}
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/louisinger/silentiumd/internal/application"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	TopicBlocks  = "blocks"
+	TopicScalars = "scalars"
+	TopicReorg   = "reorg"
+
+	wsMaxMessageSize = 4096
+	wsWriteWait      = 10 * time.Second
+	// wsSendBufferSize is the number of frames queued per connection,
+	// a client not reading its frames fast enough is disconnected.
+	wsSendBufferSize = 64
+)
+
+var (
+	wsPongWait   = 60 * time.Second
+	wsPingPeriod = 30 * time.Second
+)
+
+// wsRequest is a frame sent by the client to manage its subscriptions.
+// from is the height to replay the blocks from, it only applies to the first subscription.
+type wsRequest struct {
+	Action string   `json:"action"`
+	Topics []string `json:"topics"`
+	From   uint32   `json:"from,omitempty"`
+}
+
+// wsFrame is a frame sent to the client, data depends on the type.
+// subscribe and unsubscribe requests are acknowledged by a frame of the same type.
+type wsFrame struct {
+	Type   string      `json:"type"`
+	Data   interface{} `json:"data,omitempty"`
+	Topics []string    `json:"topics,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+type wsBlock struct {
+	Height    int32  `json:"height"`
+	Blockhash string `json:"blockhash"`
+	Filter    string `json:"filter"`
+}
+
+type wsScalars struct {
+	Height    int32    `json:"height"`
+	Blockhash string   `json:"blockhash"`
+	Scalars   []string `json:"scalars"`
+}
+
+type wsReorg struct {
+	ForkHeight int32 `json:"fork_height"`
+}
+
+type wsHandler struct {
+	svc      application.SilentiumService
+	upgrader websocket.Upgrader
+}
+
+// NewWebSocketHandler returns the handler multiplexing the chain events subscriptions over a websocket.
+// the browsers are only allowed from the same origin or from allowedOrigins, "*" allows any origin.
+func NewWebSocketHandler(service application.SilentiumService, allowedOrigins []string) http.Handler {
+	return &wsHandler{
+		svc: service,
+		upgrader: websocket.Upgrader{
+			CheckOrigin: checkOrigin(allowedOrigins),
+		},
+	}
+}
+
+// checkOrigin returns nil without allowedOrigins, the upgrader then applies its same origin check.
+func checkOrigin(allowedOrigins []string) func(*http.Request) bool {
+	if len(allowedOrigins) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[strings.ToLower(origin)] = true
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		// not a browser
+		if origin == "" {
+			return true
+		}
+
+		if allowed["*"] || allowed[strings.ToLower(origin)] {
+			return true
+		}
+
+		u, err := url.Parse(origin)
+		return err == nil && strings.EqualFold(u.Host, r.Host)
+	}
+}
+
+func (h *wsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		logrus.Debugf("websocket upgrade failed: %s", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	c := &wsConn{
+		conn:   conn,
+		svc:    h.svc,
+		ctx:    ctx,
+		cancel: cancel,
+		send:   make(chan wsFrame, wsSendBufferSize),
+		topics: make(map[string]bool),
+	}
+
+	go c.writeLoop()
+	c.readLoop()
+}
+
+type wsConn struct {
+	conn   *websocket.Conn
+	svc    application.SilentiumService
+	ctx    context.Context
+	cancel context.CancelFunc
+	send   chan wsFrame
+
+	lock       sync.RWMutex
+	topics     map[string]bool
+	subscribed bool
+}
+
+// readLoop handles the client requests until the connection is closed.
+func (c *wsConn) readLoop() {
+	defer c.cancel()
+
+	c.conn.SetReadLimit(wsMaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var req wsRequest
+		if err := json.Unmarshal(msg, &req); err != nil {
+			c.enqueue(wsFrame{Type: "error", Error: "invalid request"})
+			continue
+		}
+
+		if err := c.handleRequest(req); err != nil {
+			c.enqueue(wsFrame{Type: "error", Error: err.Error()})
+		}
+	}
+}
+
+func (c *wsConn) handleRequest(req wsRequest) error {
+	for _, topic := range req.Topics {
+		if topic != TopicBlocks && topic != TopicScalars && topic != TopicReorg {
+			return fmt.Errorf("invalid topic: %s", topic)
+		}
+	}
+
+	switch req.Action {
+	case "subscribe":
+		c.lock.Lock()
+		for _, topic := range req.Topics {
+			c.topics[topic] = true
+		}
+		startSubscription := !c.subscribed
+		c.subscribed = true
+		c.lock.Unlock()
+
+		if startSubscription {
+			events, err := c.svc.SubscribeScalars(c.ctx, req.From)
+			if err != nil {
+				return err
+			}
+
+			go c.eventLoop(events)
+		}
+	case "unsubscribe":
+		c.lock.Lock()
+		for _, topic := range req.Topics {
+			delete(c.topics, topic)
+		}
+		c.lock.Unlock()
+	default:
+		return fmt.Errorf("invalid action: %s", req.Action)
+	}
+
+	c.enqueue(wsFrame{Type: req.Action, Topics: req.Topics})
+	return nil
+}
+
+// eventLoop forwards the chain events matching the subscribed topics.
+// the replayed blocks wait for room in the queue, the live events never block the subscription.
+func (c *wsConn) eventLoop(events <-chan application.ChainEvent) {
+	for event := range events {
+		c.lock.RLock()
+		blocks, scalars, reorg := c.topics[TopicBlocks], c.topics[TopicScalars], c.topics[TopicReorg]
+		c.lock.RUnlock()
+
+		enqueue := c.enqueue
+		if event.Replayed {
+			enqueue = c.enqueueWait
+		}
+
+		switch event.Type {
+		case application.BlockIndexed:
+			blockhash := event.Block.Hash.String()
+
+			if blocks {
+				enqueue(wsFrame{Type: TopicBlocks, Data: wsBlock{
+					Height:    event.Block.Height,
+					Blockhash: blockhash,
+					Filter:    event.Filter,
+				}})
+			}
+
+			if scalars {
+				enqueue(wsFrame{Type: TopicScalars, Data: wsScalars{
+					Height:    event.Block.Height,
+					Blockhash: blockhash,
+					Scalars:   event.Block.Scalars,
+				}})
+			}
+		case application.ChainReorg:
+			if reorg {
+				enqueue(wsFrame{Type: TopicReorg, Data: wsReorg{ForkHeight: event.ForkHeight}})
+			}
+		}
+	}
+
+	// the subscription has been closed by the service
+	c.cancel()
+}
+
+// enqueue queues the frame without blocking, the connection is closed if the queue is full.
+func (c *wsConn) enqueue(frame wsFrame) {
+	select {
+	case <-c.ctx.Done():
+	case c.send <- frame:
+	default:
+		logrus.Debug("websocket client is too slow, closing connection")
+		c.cancel()
+	}
+}
+
+// enqueueWait queues the frame, waiting for room in the queue until the connection is closed.
+func (c *wsConn) enqueueWait(frame wsFrame) {
+	select {
+	case <-c.ctx.Done():
+	case c.send <- frame:
+	}
+}
+
+// writeLoop is the only writer of the connection, it sends the queued frames and the pings.
+func (c *wsConn) writeLoop() {
+	ticker := time.NewTicker(wsPingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.ctx.Done():
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			c.conn.WriteMessage(
+				websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, ""),
+			)
+			return
+		case frame := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteJSON(frame); err != nil {
+				c.cancel()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.cancel()
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/gorilla/websocket"
+	"github.com/louisinger/silentiumd/internal/application"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebSocket(t *testing.T) {
+	wsPingPeriod = 50 * time.Millisecond
+
+	svc := &stubService{events: make(chan application.ChainEvent), from: make(chan uint32, 1)}
+	server := httptest.NewServer(NewWebSocketHandler(svc, nil))
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	pings := make(chan struct{}, 10)
+	conn.SetPingHandler(func(string) error {
+		pings <- struct{}{}
+		return nil
+	})
+
+	frames := make(chan map[string]interface{})
+	go func() {
+		defer close(frames)
+		for {
+			var frame map[string]interface{}
+			if err := conn.ReadJSON(&frame); err != nil {
+				return
+			}
+			frames <- frame
+		}
+	}()
+
+	receive := func() map[string]interface{} {
+		select {
+		case frame := <-frames:
+			return frame
+		case <-time.After(5 * time.Second):
+			t.Fatal("no frame received")
+			return nil
+		}
+	}
+
+	t.Run("invalid requests", func(t *testing.T) {
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("{")))
+		require.Equal(t, "invalid request", receive()["error"])
+
+		require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Topics: []string{"mempool"}}))
+		require.Equal(t, "invalid topic: mempool", receive()["error"])
+
+		require.NoError(t, conn.WriteJSON(wsRequest{Action: "watch"}))
+		require.Equal(t, "invalid action: watch", receive()["error"])
+	})
+
+	t.Run("subscribe", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(wsRequest{
+			Action: "subscribe",
+			Topics: []string{TopicScalars, TopicReorg},
+			From:   10,
+		}))
+
+		frame := receive()
+		require.Equal(t, "subscribe", frame["type"])
+		require.Equal(t, uint32(10), <-svc.from)
+
+		svc.events <- application.ChainEvent{
+			Type: application.BlockIndexed,
+			Block: domain.BlockScalars{
+				Height:  10,
+				Hash:    chainhash.Hash{0x01},
+				Scalars: []string{"02aa"},
+			},
+			Filter: "0123",
+		}
+
+		frame = receive()
+		require.Equal(t, TopicScalars, frame["type"])
+		data := frame["data"].(map[string]interface{})
+		require.Equal(t, float64(10), data["height"])
+		require.Equal(t, chainhash.Hash{0x01}.String(), data["blockhash"])
+		require.Equal(t, []interface{}{"02aa"}, data["scalars"])
+
+		svc.events <- application.ChainEvent{Type: application.ChainReorg, ForkHeight: 9}
+
+		frame = receive()
+		require.Equal(t, TopicReorg, frame["type"])
+		require.Equal(t, float64(9), frame["data"].(map[string]interface{})["fork_height"])
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		require.NoError(t, conn.WriteJSON(wsRequest{Action: "unsubscribe", Topics: []string{TopicScalars}}))
+		require.Equal(t, "unsubscribe", receive()["type"])
+
+		require.NoError(t, conn.WriteJSON(wsRequest{Action: "subscribe", Topics: []string{TopicBlocks}}))
+		require.Equal(t, "subscribe", receive()["type"])
+
+		svc.events <- application.ChainEvent{
+			Type:   application.BlockIndexed,
+			Block:  domain.BlockScalars{Height: 11, Scalars: []string{"02bb"}},
+			Filter: "4567",
+		}
+
+		frame := receive()
+		require.Equal(t, TopicBlocks, frame["type"])
+		data := frame["data"].(map[string]interface{})
+		require.Equal(t, float64(11), data["height"])
+		require.Equal(t, "4567", data["filter"])
+	})
+
+	t.Run("heartbeat", func(t *testing.T) {
+		select {
+		case <-pings:
+		case <-time.After(5 * time.Second):
+			t.Fatal("no ping received")
+		}
+	})
+}
+
+func TestWebSocketBackpressure(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &wsConn{
+		ctx:    ctx,
+		cancel: cancel,
+		send:   make(chan wsFrame, wsSendBufferSize),
+	}
+
+	for i := 0; i < wsSendBufferSize; i++ {
+		c.enqueue(wsFrame{Type: TopicBlocks})
+	}
+	require.NoError(t, ctx.Err())
+
+	c.enqueue(wsFrame{Type: TopicBlocks})
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestWebSocketReplay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	c := &wsConn{
+		ctx:    ctx,
+		cancel: cancel,
+		send:   make(chan wsFrame, wsSendBufferSize),
+		topics: map[string]bool{TopicBlocks: true, TopicScalars: true},
+	}
+
+	// the replay is larger than the queue, it waits for the frames to be written
+	const replayed = 2 * wsSendBufferSize
+	events := make(chan application.ChainEvent)
+	go func() {
+		defer close(events)
+		for height := int32(1); height <= replayed; height++ {
+			events <- application.ChainEvent{
+				Type:     application.BlockIndexed,
+				Block:    domain.BlockScalars{Height: height},
+				Replayed: true,
+			}
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.eventLoop(events)
+	}()
+
+	for i := 0; i < 2*replayed; i++ {
+		select {
+		case <-c.send:
+			time.Sleep(time.Millisecond)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("frame %d not received", i)
+		}
+	}
+	<-done
+
+	// the connection is closed with the end of the subscription only
+	require.ErrorIs(t, ctx.Err(), context.Canceled)
+	require.Len(t, c.send, 0)
+}
+
+func TestWebSocketOrigin(t *testing.T) {
+	tests := []struct {
+		name           string
+		allowedOrigins []string
+		origin         string
+		allowed        bool
+	}{
+		{name: "no origin", origin: "", allowed: true},
+		{name: "same origin", origin: "same", allowed: true},
+		{name: "cross origin", origin: "https://wallet.example.com", allowed: false},
+		{name: "allowed origin", allowedOrigins: []string{"https://wallet.example.com"}, origin: "https://wallet.example.com", allowed: true},
+		{name: "same origin with allowlist", allowedOrigins: []string{"https://wallet.example.com"}, origin: "same", allowed: true},
+		{name: "not allowed origin", allowedOrigins: []string{"https://wallet.example.com"}, origin: "https://evil.example.com", allowed: false},
+		{name: "any origin", allowedOrigins: []string{"*"}, origin: "https://evil.example.com", allowed: true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &stubService{events: make(chan application.ChainEvent), from: make(chan uint32, 1)}
+			server := httptest.NewServer(NewWebSocketHandler(svc, tt.allowedOrigins))
+			defer server.Close()
+
+			header := http.Header{}
+			switch tt.origin {
+			case "":
+			case "same":
+				header.Set("Origin", server.URL)
+			default:
+				header.Set("Origin", tt.origin)
+			}
+
+			conn, res, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), header)
+			if !tt.allowed {
+				require.ErrorIs(t, err, websocket.ErrBadHandshake)
+				require.Equal(t, http.StatusForbidden, res.StatusCode)
+				return
+			}
+
+			require.NoError(t, err)
+			conn.Close()
+		})
+	}
+}
+
+type stubService struct {
+	application.SilentiumService
+
+	events chan application.ChainEvent
+	from   chan uint32
+}
+
+func (s *stubService) SubscribeScalars(_ context.Context, from uint32) (<-chan application.ChainEvent, error) {
+	s.from <- from
+	return s.events, nil
+}
@@ -9,3 +9,8 @@ import (
 func UnaryInterceptor() grpc.ServerOption {
 	return grpc.UnaryInterceptor(middleware.ChainUnaryServer(unaryLogger))
 }
+
+// StreamInterceptor returns the stream interceptor
+func StreamInterceptor() grpc.ServerOption {
+	return grpc.StreamInterceptor(middleware.ChainStreamServer(streamLogger))
+}
@@ -21,6 +21,8 @@ import (
 	"google.golang.org/protobuf/encoding/protojson"
 )
 
+const wsPath = "/v1/ws"
+
 type Service struct {
 	config Config
 	server *http.Server
@@ -33,7 +35,9 @@ func NewService(
 		return nil, fmt.Errorf("invalid service config: %s", err)
 	}
 
-	grpcConfig := []grpc.ServerOption{interceptors.UnaryInterceptor()}
+	grpcConfig := []grpc.ServerOption{
+		interceptors.UnaryInterceptor(), interceptors.StreamInterceptor(),
+	}
 
 	var tlsConfig *tls.Config
 
@@ -55,6 +59,9 @@ func NewService(
 	grpcServer := grpc.NewServer(grpcConfig...)
 	appHandler := handlers.NewHandler(svcConfig.AppService)
 	silentiumv1.RegisterSilentiumServiceServer(grpcServer, appHandler)
+	if svcConfig.ScanService != nil {
+		silentiumv1.RegisterScanServiceServer(grpcServer, handlers.NewScanHandler(svcConfig.ScanService))
+	}
 	healthHandler := handlers.NewHealthHandler()
 	grpchealth.RegisterHealthServer(grpcServer, healthHandler)
 
@@ -91,9 +98,18 @@ func NewService(
 	); err != nil {
 		return nil, err
 	}
+	if svcConfig.ScanService != nil {
+		if err := silentiumv1.RegisterScanServiceHandler(
+			ctx, gwmux, conn,
+		); err != nil {
+			return nil, err
+		}
+	}
 	grpcGateway := http.Handler(gwmux)
 
-	handler := router(grpcServer, grpcGateway)
+	wsHandler := handlers.NewWebSocketHandler(svcConfig.AppService, svcConfig.WSAllowedOrigins)
+
+	handler := router(grpcServer, grpcGateway, wsHandler)
 	mux := http.NewServeMux()
 	mux.Handle("/", handler)
 
@@ -130,9 +146,14 @@ func (s *Service) Stop() {
 }
 
 func router(
-	grpcServer *grpc.Server, grpcGateway http.Handler,
+	grpcServer *grpc.Server, grpcGateway http.Handler, wsHandler http.Handler,
 ) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isWebSocketRequest(r) {
+			wsHandler.ServeHTTP(w, r)
+			return
+		}
+
 		if isOptionRequest(r) {
 			w.Header().Set("Access-Control-Allow-Origin", "*")
 			w.Header().Set("Access-Control-Allow-Headers", "*")
@@ -152,6 +173,10 @@ func router(
 	})
 }
 
+func isWebSocketRequest(req *http.Request) bool {
+	return req.URL.Path == wsPath
+}
+
 func isOptionRequest(req *http.Request) bool {
 	return req.Method == http.MethodOptions
 }
@@ -1,15 +1,32 @@
 package ports
 
 import (
+	"errors"
+
 	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
 )
 
+// ErrPrevoutsNotSupported is returned by GetBlockPrevouts if the chain source can't serve the prevouts of a whole block,
+// the prevouts must be fetched one by one with GetPrevoutScript.
+var ErrPrevoutsNotSupported = errors.New("block prevouts not supported by the chain source")
+
 type ChainSource interface {
 	GetPrevoutScript(wire.OutPoint) ([]byte, error)
 	SubscribeBlocks() (<-chan *btcutil.Block, func(), error)
 	GetChainTipHeight() (int32, error)
+	GetBlockHash(int32) (*chainhash.Hash, error)
 	GetBlockByHeight(int32) (*btcutil.Block, error)
+	// GetBlockPrevouts returns the scripts of all the outputs spent by the block.
+	GetBlockPrevouts(*btcutil.Block) (domain.PrevoutScripts, error)
 	GetBlockFilterByHeight(int32) (string, string, error)
+	// GetBlockFilter returns the hex-encoded BIP158 basic filter of the block.
+	GetBlockFilter(chainhash.Hash) (string, error)
 	IsUtxo(outpoint wire.OutPoint) (bool, error)
+	GetMempoolTxids() ([]chainhash.Hash, error)
+	GetMempoolTx(txid chainhash.Hash) (*btcutil.Tx, error)
+	// GetMempoolPrevouts returns the scripts of the outputs spent by an unconfirmed transaction.
+	GetMempoolPrevouts(*btcutil.Tx) (domain.PrevoutScripts, error)
 }
@@ -0,0 +1,20 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/louisinger/silentiumd/internal/domain"
+)
+
+// MempoolRepository stores the scalars of the unconfirmed transactions.
+type MempoolRepository interface {
+	// Add stores the scalar of a mempool transaction seen at the given time,
+	// the stored transactions spending the same outpoints are replaced.
+	Add(scalar *domain.SilentScalar, seenAt time.Time) error
+	Remove(txids []chainhash.Hash) error
+	// RemoveExpired removes the transactions seen before the given time and returns their txids.
+	RemoveExpired(before time.Time) ([]chainhash.Hash, error)
+	GetTxids() ([]chainhash.Hash, error)
+	GetScalars() ([]string, error)
+}
@@ -12,6 +12,26 @@ type ErrScalarNotFound struct {
 	MethodName string
 }
 
+type ErrBlockNotFound struct {
+	Height int32
+}
+
+// ScalarsFilter selects the transactions returned by the scalars queries.
+type ScalarsFilter int
+
+const (
+	// UnspentScalars keeps the transactions with at least 1 unspent taproot output (cut-through).
+	UnspentScalars ScalarsFilter = iota
+	// AllScalars keeps every indexed transaction, even if all its taproot outputs are spent.
+	AllScalars
+)
+
+// Selects returns true if the output is kept by the filter and not below dustLimit.
+// a transaction is selected if at least 1 of its taproot outputs is.
+func (f ScalarsFilter) Selects(out domain.TaprootOutput, dustLimit int64) bool {
+	return (f == AllScalars || !out.Spent) && !out.IsDust(dustLimit)
+}
+
 type Outpoint struct {
 	TxHash *chainhash.Hash
 	Index  uint32
@@ -21,9 +41,36 @@ func (e ErrScalarNotFound) Error() string {
 	return fmt.Sprintf("scalar not found (%s)", e.MethodName)
 }
 
+func (e ErrBlockNotFound) Error() string {
+	return fmt.Sprintf("block not found (%d)", e.Height)
+}
+
 type ScalarRepository interface {
 	GetLatestBlockHeight() (int32, error)
-	GetScalars(height int32) ([]string, error)
-	MarkSpent(outpoints []wire.OutPoint) error
-	Write(scalars []*domain.SilentScalar, blockHeight int32) error
+	GetBlockHeader(height int32) (*domain.BlockHeader, error)
+	// GetFirstHashedHeight returns the height of the lowest block indexed with its hash, 0 if there is none.
+	// the blocks below it were indexed before the hashes were stored, GetBlockHeader can't return them.
+	GetFirstHashedHeight() (int32, error)
+	// GetScalars returns the hex-encoded scalars of the block transactions selected by filter
+	// and dustLimit (in sats, 0 to keep dust), once per transaction.
+	GetScalars(height int32, filter ScalarsFilter, dustLimit int64) ([]string, error)
+	// GetSilentScalars returns the silent scalars of the block transactions selected by filter and dustLimit, with all their taproot outputs.
+	GetSilentScalars(height int32, filter ScalarsFilter, dustLimit int64) ([]*domain.SilentScalar, error)
+	// GetScalarsRange returns the scalars of the indexed blocks in [from, to], ordered by height.
+	GetScalarsRange(from, to int32, filter ScalarsFilter, dustLimit int64) ([]domain.BlockScalars, error)
+	// MarkSpent flags the taproot outputs as spent by the block at spentHeight.
+	MarkSpent(outpoints []wire.OutPoint, spentHeight int32) error
+	// MarkUnspent clears the spent flags of the taproot outputs, it repairs outputs wrongly flagged as spent.
+	MarkUnspent(outpoints []wire.OutPoint) error
+	// GetTaprootFilter returns the taproot filter of the block, ErrBlockNotFound if not indexed.
+	GetTaprootFilter(height int32) ([]byte, error)
+	// Write stores the block scalars with its taproot filter.
+	Write(scalars []*domain.SilentScalar, header domain.BlockHeader, taprootFilter []byte) error
+	// ApplyBlock atomically writes the block scalars with its taproot filter,
+	// marks the outpoints spent by the block and moves the tip to the block.
+	// the outputs created and spent by the block must be flagged in scalars.
+	ApplyBlock(scalars []*domain.SilentScalar, header domain.BlockHeader, taprootFilter []byte, spentOutpoints []wire.OutPoint) error
+	// Rollback removes the blocks above forkHeight and marks as unspent
+	// the taproot outputs spent by those blocks.
+	Rollback(forkHeight int32) error
 }
@@ -0,0 +1,208 @@
+// Package address encodes and decodes the silent payment (BIP352) addresses.
+//
+// an address is the bech32m encoding of a version and the scan and spend public keys,
+// the human readable part identifies the network.
+package address
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+)
+
+const (
+	// Version0 is the only version defined by BIP352.
+	Version0 byte = 0
+	// MaxVersion is the highest valid version, 31 is reserved for backward incompatible changes.
+	MaxVersion byte = 30
+
+	// maxLength is the maximum length of an address, versions 1 to 30 may append data to the keys.
+	maxLength = 1023
+	keysLen   = 2 * btcec.PubKeyBytesLenCompressed
+)
+
+var (
+	labelTag = []byte("BIP0352/Label")
+
+	ErrInvalidChecksum   = errors.New("address must be bech32m encoded")
+	ErrUnknownHRP        = errors.New("unknown human readable part")
+	ErrWrongNetwork      = errors.New("address is for another network")
+	ErrInvalidVersion    = errors.New("invalid version")
+	ErrInvalidLength     = errors.New("invalid address length")
+	ErrInvalidLabelTweak = errors.New("invalid label tweak")
+)
+
+// Address is a silent payment address.
+type Address struct {
+	Version byte
+	// HRP is the human readable part, it depends on the network.
+	HRP      string
+	ScanKey  *btcec.PublicKey
+	SpendKey *btcec.PublicKey
+}
+
+// HRP returns the human readable part of the addresses of the network:
+// sp for mainnet, tsp for testnet and signet, sprt for regtest.
+func HRP(params *chaincfg.Params) (string, error) {
+	switch params.Name {
+	case chaincfg.MainNetParams.Name:
+		return "sp", nil
+	case chaincfg.TestNet3Params.Name, chaincfg.SigNetParams.Name:
+		return "tsp", nil
+	case chaincfg.RegressionNetParams.Name:
+		return "sprt", nil
+	default:
+		return "", fmt.Errorf("%w: unsupported network %s", ErrUnknownHRP, params.Name)
+	}
+}
+
+// New returns the version 0 address of the keys on the network.
+func New(scanKey, spendKey *btcec.PublicKey, params *chaincfg.Params) (*Address, error) {
+	hrp, err := HRP(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Address{Version: Version0, HRP: hrp, ScanKey: scanKey, SpendKey: spendKey}, nil
+}
+
+// NewLabeled returns the version 0 address of the label m, its spend key is B_spend + hash_BIP0352/Label(ser_256(b_scan) || ser_32(m)) * G.
+// the label 0 is reserved for the change.
+func NewLabeled(scanKey *btcec.PrivateKey, spendKey *btcec.PublicKey, m uint32, params *chaincfg.Params) (*Address, error) {
+	labeledSpendKey, err := LabeledSpendKey(scanKey, spendKey, m)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(scanKey.PubKey(), labeledSpendKey, params)
+}
+
+// LabelTweak returns hash_BIP0352/Label(ser_256(b_scan) || ser_32(m)).
+func LabelTweak(scanKey *btcec.PrivateKey, m uint32) (*btcec.ModNScalar, error) {
+	msg := make([]byte, 36)
+	copy(msg, scanKey.Serialize())
+	binary.BigEndian.PutUint32(msg[32:], m)
+
+	hash := chainhash.TaggedHash(labelTag, msg)
+
+	tweak := new(btcec.ModNScalar)
+	if overflow := tweak.SetByteSlice(hash[:]); overflow || tweak.IsZero() {
+		return nil, ErrInvalidLabelTweak
+	}
+
+	return tweak, nil
+}
+
+// LabeledSpendKey returns the spend key of the label m, B_spend + LabelTweak(b_scan, m) * G.
+func LabeledSpendKey(scanKey *btcec.PrivateKey, spendKey *btcec.PublicKey, m uint32) (*btcec.PublicKey, error) {
+	tweak, err := LabelTweak(scanKey, m)
+	if err != nil {
+		return nil, err
+	}
+
+	var spend, tG, labeled btcec.JacobianPoint
+	spendKey.AsJacobian(&spend)
+	btcec.ScalarBaseMultNonConst(tweak, &tG)
+	btcec.AddNonConst(&spend, &tG, &labeled)
+
+	if labeled.Z.IsZero() {
+		return nil, ErrInvalidLabelTweak
+	}
+	labeled.ToAffine()
+
+	return btcec.NewPublicKey(&labeled.X, &labeled.Y), nil
+}
+
+// Decode parses the address of the network.
+// versions 1 to 30 are decoded as version 0, the data appended to the keys is ignored.
+func Decode(address string, params *chaincfg.Params) (*Address, error) {
+	if len(address) > maxLength {
+		return nil, ErrInvalidLength
+	}
+
+	// the addresses are longer than the 90 characters limit of bech32.DecodeGeneric
+	hrp, data, err := bech32.DecodeNoLimit(address)
+	if err != nil {
+		return nil, err
+	}
+
+	// DecodeNoLimit accepts both checksums
+	if encoded, err := bech32.EncodeM(hrp, data); err != nil || encoded != strings.ToLower(address) {
+		return nil, ErrInvalidChecksum
+	}
+
+	expectedHRP, err := HRP(params)
+	if err != nil {
+		return nil, err
+	}
+
+	if hrp != expectedHRP {
+		if hrp != "sp" && hrp != "tsp" && hrp != "sprt" {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownHRP, hrp)
+		}
+		return nil, fmt.Errorf("%w: %s, expected %s", ErrWrongNetwork, hrp, expectedHRP)
+	}
+
+	if len(data) == 0 {
+		return nil, ErrInvalidLength
+	}
+
+	version := data[0]
+	if version > MaxVersion {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidVersion, version)
+	}
+
+	payload, err := bech32.ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(payload) < keysLen || (version == Version0 && len(payload) != keysLen) {
+		return nil, fmt.Errorf("%w: %d bytes", ErrInvalidLength, len(payload))
+	}
+
+	scanKey, err := btcec.ParsePubKey(payload[:btcec.PubKeyBytesLenCompressed])
+	if err != nil {
+		return nil, fmt.Errorf("invalid scan key: %w", err)
+	}
+
+	spendKey, err := btcec.ParsePubKey(payload[btcec.PubKeyBytesLenCompressed:keysLen])
+	if err != nil {
+		return nil, fmt.Errorf("invalid spend key: %w", err)
+	}
+
+	return &Address{Version: version, HRP: hrp, ScanKey: scanKey, SpendKey: spendKey}, nil
+}
+
+// Encode returns the bech32m encoding of the address.
+func (a *Address) Encode() (string, error) {
+	if a.Version > MaxVersion {
+		return "", fmt.Errorf("%w: %d", ErrInvalidVersion, a.Version)
+	}
+
+	payload := make([]byte, 0, keysLen)
+	payload = append(payload, a.ScanKey.SerializeCompressed()...)
+	payload = append(payload, a.SpendKey.SerializeCompressed()...)
+
+	data, err := bech32.ConvertBits(payload, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+
+	return bech32.EncodeM(a.HRP, append([]byte{a.Version}, data...))
+}
+
+// String returns the encoded address, or an empty string if it can't be encoded.
+func (a *Address) String() string {
+	s, err := a.Encode()
+	if err != nil {
+		return ""
+	}
+	return s
+}
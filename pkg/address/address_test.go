@@ -0,0 +1,220 @@
+package address_test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil/bech32"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/louisinger/silentiumd/pkg/address"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddress(t *testing.T) {
+	var testVectors []testVector
+	file, err := os.Open("../../internal/domain/test_data/test_vectors.json")
+	require.NoError(t, err)
+	defer file.Close()
+	require.NoError(t, json.NewDecoder(file).Decode(&testVectors))
+
+	for _, testVector := range testVectors {
+		testVector := testVector
+
+		t.Run(testVector.Comment, func(t *testing.T) {
+			for _, receiving := range testVector.Receiving {
+				scanKey, scanPubKey := btcec.PrivKeyFromBytes(decodeHex(t, receiving.Given.KeyMaterial.ScanPrivKey))
+				_, spendPubKey := btcec.PrivKeyFromBytes(decodeHex(t, receiving.Given.KeyMaterial.SpendPrivKey))
+
+				addr, err := address.New(scanPubKey, spendPubKey, &chaincfg.MainNetParams)
+				require.NoError(t, err)
+
+				encoded := []string{addr.String()}
+				for _, m := range receiving.Given.Labels {
+					labeled, err := address.NewLabeled(scanKey, spendPubKey, m, &chaincfg.MainNetParams)
+					require.NoError(t, err)
+					require.True(t, labeled.ScanKey.IsEqual(scanPubKey))
+
+					encoded = append(encoded, labeled.String())
+				}
+
+				require.ElementsMatch(t, receiving.Expected.Addresses, encoded)
+
+				for _, expected := range receiving.Expected.Addresses {
+					decoded, err := address.Decode(expected, &chaincfg.MainNetParams)
+					require.NoError(t, err)
+					require.Equal(t, address.Version0, decoded.Version)
+					require.True(t, decoded.ScanKey.IsEqual(scanPubKey))
+					require.Equal(t, expected, decoded.String())
+
+					// the address is case insensitive
+					upper, err := address.Decode(strings.ToUpper(expected), &chaincfg.MainNetParams)
+					require.NoError(t, err)
+					require.Equal(t, expected, upper.String())
+				}
+			}
+		})
+	}
+}
+
+func TestNetworks(t *testing.T) {
+	_, scanKey := btcec.PrivKeyFromBytes([]byte{0x01})
+	_, spendKey := btcec.PrivKeyFromBytes([]byte{0x02})
+
+	networks := []struct {
+		params *chaincfg.Params
+		hrp    string
+	}{
+		{&chaincfg.MainNetParams, "sp"},
+		{&chaincfg.TestNet3Params, "tsp"},
+		{&chaincfg.SigNetParams, "tsp"},
+		{&chaincfg.RegressionNetParams, "sprt"},
+	}
+
+	for _, network := range networks {
+		addr, err := address.New(scanKey, spendKey, network.params)
+		require.NoError(t, err)
+		require.Equal(t, network.hrp, addr.HRP)
+
+		encoded := addr.String()
+		require.True(t, strings.HasPrefix(encoded, network.hrp+"1q"), encoded)
+
+		decoded, err := address.Decode(encoded, network.params)
+		require.NoError(t, err)
+		require.Equal(t, addr, decoded)
+	}
+
+	_, err := address.New(scanKey, spendKey, &chaincfg.SimNetParams)
+	require.ErrorIs(t, err, address.ErrUnknownHRP)
+
+	testnet, err := address.New(scanKey, spendKey, &chaincfg.TestNet3Params)
+	require.NoError(t, err)
+
+	_, err = address.Decode(testnet.String(), &chaincfg.MainNetParams)
+	require.ErrorIs(t, err, address.ErrWrongNetwork)
+}
+
+func TestDecodeVersions(t *testing.T) {
+	_, scanKey := btcec.PrivKeyFromBytes([]byte{0x01})
+	_, spendKey := btcec.PrivKeyFromBytes([]byte{0x02})
+
+	keys := append(scanKey.SerializeCompressed(), spendKey.SerializeCompressed()...)
+	extra := append(append([]byte{}, keys...), 0xff, 0xff)
+
+	t.Run("valid", func(t *testing.T) {
+		// future versions are decoded as version 0, the data after the keys is ignored
+		for _, version := range []byte{1, address.MaxVersion} {
+			decoded, err := address.Decode(encode(t, "sp", version, extra, bech32.VersionM), &chaincfg.MainNetParams)
+			require.NoError(t, err)
+			require.Equal(t, version, decoded.Version)
+			require.True(t, decoded.ScanKey.IsEqual(scanKey))
+			require.True(t, decoded.SpendKey.IsEqual(spendKey))
+		}
+	})
+
+	invalidKeys := append([]byte{}, keys...)
+	invalidKeys[0] = 0x05
+
+	invalid := map[string]struct {
+		address string
+		err     error
+	}{
+		"bech32 checksum": {
+			address: encode(t, "sp", 0, keys, bech32.Version0),
+			err:     address.ErrInvalidChecksum,
+		},
+		"unknown hrp": {
+			address: encode(t, "bc", 0, keys, bech32.VersionM),
+			err:     address.ErrUnknownHRP,
+		},
+		"version 31": {
+			address: encode(t, "sp", 31, keys, bech32.VersionM),
+			err:     address.ErrInvalidVersion,
+		},
+		"version 0 with extra data": {
+			address: encode(t, "sp", 0, extra, bech32.VersionM),
+			err:     address.ErrInvalidLength,
+		},
+		"truncated keys": {
+			address: encode(t, "sp", 0, keys[:65], bech32.VersionM),
+			err:     address.ErrInvalidLength,
+		},
+		"truncated keys of future version": {
+			address: encode(t, "sp", 1, keys[:65], bech32.VersionM),
+			err:     address.ErrInvalidLength,
+		},
+		"too long": {
+			address: encode(t, "sp", 1, append(keys, make([]byte, 600)...), bech32.VersionM),
+			err:     address.ErrInvalidLength,
+		},
+	}
+
+	for name, tc := range invalid {
+		tc := tc
+
+		t.Run(name, func(t *testing.T) {
+			_, err := address.Decode(tc.address, &chaincfg.MainNetParams)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+
+	otherErrors := map[string]string{
+		"invalid key":  encode(t, "sp", 0, invalidKeys, bech32.VersionM),
+		"bad checksum": encode(t, "sp", 0, keys, bech32.VersionM)[:10] + "qqqq" + encode(t, "sp", 0, keys, bech32.VersionM)[14:],
+		"mixed case":   "SP" + encode(t, "sp", 0, keys, bech32.VersionM)[2:],
+	}
+
+	for name, invalidAddress := range otherErrors {
+		invalidAddress := invalidAddress
+
+		t.Run(name, func(t *testing.T) {
+			_, err := address.Decode(invalidAddress, &chaincfg.MainNetParams)
+			require.Error(t, err)
+		})
+	}
+}
+
+func encode(t *testing.T, hrp string, version byte, payload []byte, checksum bech32.Version) string {
+	t.Helper()
+
+	data, err := bech32.ConvertBits(payload, 8, 5, true)
+	require.NoError(t, err)
+	data = append([]byte{version}, data...)
+
+	var encoded string
+	if checksum == bech32.VersionM {
+		encoded, err = bech32.EncodeM(hrp, data)
+	} else {
+		encoded, err = bech32.Encode(hrp, data)
+	}
+	require.NoError(t, err)
+
+	return encoded
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+type testVector struct {
+	Comment   string `json:"comment"`
+	Receiving []struct {
+		Given struct {
+			KeyMaterial struct {
+				SpendPrivKey string `json:"spend_priv_key"`
+				ScanPrivKey  string `json:"scan_priv_key"`
+			} `json:"key_material"`
+			Labels []uint32 `json:"labels"`
+		} `json:"given"`
+		Expected struct {
+			Addresses []string `json:"addresses"`
+		} `json:"expected"`
+	} `json:"receiving"`
+}
@@ -0,0 +1,437 @@
+// Package client is the Go client of the silentium API.
+//
+// the gRPC and REST transports expose the same Client interface,
+// scalars are parsed as public keys and the failed requests are retried.
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	silentiumv1 "github.com/louisinger/silentiumd/api/protobuf/gen/silentium/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultMaxRetries = 3
+	defaultRetryDelay = 500 * time.Millisecond
+)
+
+// Client queries a silentium server.
+// errors returned by the server are gRPC status errors for both transports, see status.Code.
+type Client interface {
+	GetChainTipHeight(ctx context.Context) (uint32, error)
+	// GetBlockScalars returns the scalars of the block transactions selected by the options.
+	GetBlockScalars(ctx context.Context, height uint32, opts ...ScalarsOption) ([]*btcec.PublicKey, error)
+	// GetBlockTransactions returns the block transactions selected by the options with their taproot outputs.
+	GetBlockTransactions(ctx context.Context, height uint32, opts ...ScalarsOption) ([]Transaction, error)
+	// GetBlockScalarsRange returns a page of the blocks in [from, to],
+	// next is the height to request the following page from, 0 if the range is complete.
+	GetBlockScalarsRange(ctx context.Context, from, to uint32, opts ...ScalarsOption) (blocks []BlockScalars, next uint32, err error)
+	// RangeScalars calls fn for each block in [from, to] in height order, requesting the pages as needed.
+	// from 0 is the first height. it stops at the first error returned by fn.
+	RangeScalars(ctx context.Context, from, to uint32, fn func(BlockScalars) error, opts ...ScalarsOption) error
+	GetMempoolScalars(ctx context.Context) ([]*btcec.PublicKey, error)
+	// GetBlockFilter returns the BIP158 basic filter of the block.
+	GetBlockFilter(ctx context.Context, height uint32) (*BlockFilter, error)
+	// GetBlockTaprootFilter returns the filter over the unspent taproot output keys of the block.
+	GetBlockTaprootFilter(ctx context.Context, height uint32) (*BlockFilter, error)
+	// Subscribe replays the blocks from the given height (0 to only receive the new ones) and then the chain events.
+	// the subscription is restarted from the last received block if the connection is lost,
+	// a ReorgEvent is sent if the block has been orphaned meanwhile.
+	Subscribe(ctx context.Context, from uint32) (*Subscription, error)
+	Close() error
+}
+
+// BlockScalars are the scalars of an indexed block.
+type BlockScalars struct {
+	Height uint32
+	// BlockHash is nil for the blocks indexed before the hashes were stored.
+	BlockHash *chainhash.Hash
+	Scalars   []*btcec.PublicKey
+}
+
+// Transaction is an indexed transaction with its selected taproot outputs.
+type Transaction struct {
+	TxHash  chainhash.Hash
+	Scalar  *btcec.PublicKey
+	Outputs []TaprootOutput
+}
+
+type TaprootOutput struct {
+	Index uint32
+	// Key is the x-only output key, nil if not indexed.
+	Key    []byte
+	Amount uint64
+	Spent  bool
+}
+
+type BlockFilter struct {
+	BlockHash chainhash.Hash
+	Filter    []byte
+}
+
+type ScalarsFilter string
+
+const (
+	// UnspentScalars keeps the transactions with at least 1 unspent taproot output.
+	UnspentScalars ScalarsFilter = "unspent"
+	// AllScalars keeps every indexed transaction.
+	AllScalars ScalarsFilter = "all"
+)
+
+type scalarsOptions struct {
+	filter    ScalarsFilter
+	dustLimit uint64
+	limit     uint32
+}
+
+// ScalarsOption customizes the scalars requests.
+type ScalarsOption func(*scalarsOptions)
+
+// WithFilter selects the transactions, UnspentScalars by default.
+func WithFilter(filter ScalarsFilter) ScalarsOption {
+	return func(o *scalarsOptions) { o.filter = filter }
+}
+
+// WithDustLimit drops the transactions with all their selected taproot outputs below dustLimit sats.
+func WithDustLimit(dustLimit uint64) ScalarsOption {
+	return func(o *scalarsOptions) { o.dustLimit = dustLimit }
+}
+
+// WithPageSize sets the maximum number of blocks per range page, capped by the server.
+func WithPageSize(limit uint32) ScalarsOption {
+	return func(o *scalarsOptions) { o.limit = limit }
+}
+
+func newScalarsOptions(opts []ScalarsOption) scalarsOptions {
+	o := scalarsOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+type options struct {
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// Option customizes the client.
+type Option func(*options)
+
+// WithRetries sets the number of retries of the requests failing with a transient error,
+// the delay doubles after each attempt. 0 disables the retries.
+func WithRetries(maxRetries int, delay time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.retryDelay = delay
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{maxRetries: defaultMaxRetries, retryDelay: defaultRetryDelay}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// transport sends the requests to the server, the responses are the API messages.
+type transport interface {
+	getBlockScalars(ctx context.Context, req *silentiumv1.GetBlockScalarsRequest) (*silentiumv1.GetBlockScalarsResponse, error)
+	getBlockScalarsRange(ctx context.Context, req *silentiumv1.GetBlockScalarsRangeRequest) (*silentiumv1.GetBlockScalarsRangeResponse, error)
+	getMempoolScalars(ctx context.Context) (*silentiumv1.GetMempoolScalarsResponse, error)
+	getBlockFilter(ctx context.Context, height uint32) (*silentiumv1.GetBlockFilterResponse, error)
+	getBlockTaprootFilter(ctx context.Context, height uint32) (*silentiumv1.GetBlockTaprootFilterResponse, error)
+	getChainTipHeight(ctx context.Context) (*silentiumv1.GetChainTipHeightResponse, error)
+	// subscribe opens an event stream, recv blocks until the next event.
+	subscribe(ctx context.Context, from uint32) (recv func() (*Event, error), err error)
+	close() error
+}
+
+type client struct {
+	transport transport
+	options
+}
+
+func (c *client) GetChainTipHeight(ctx context.Context) (uint32, error) {
+	var res *silentiumv1.GetChainTipHeightResponse
+	if err := c.retry(ctx, func() (err error) {
+		res, err = c.transport.getChainTipHeight(ctx)
+		return err
+	}); err != nil {
+		return 0, err
+	}
+
+	return res.GetHeight(), nil
+}
+
+func (c *client) GetBlockScalars(ctx context.Context, height uint32, opts ...ScalarsOption) ([]*btcec.PublicKey, error) {
+	o := newScalarsOptions(opts)
+
+	var res *silentiumv1.GetBlockScalarsResponse
+	if err := c.retry(ctx, func() (err error) {
+		res, err = c.transport.getBlockScalars(ctx, &silentiumv1.GetBlockScalarsRequest{
+			BlockId:   height,
+			Filter:    string(o.filter),
+			DustLimit: o.dustLimit,
+		})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return parseScalars(res.GetScalars())
+}
+
+func (c *client) GetBlockTransactions(ctx context.Context, height uint32, opts ...ScalarsOption) ([]Transaction, error) {
+	o := newScalarsOptions(opts)
+
+	var res *silentiumv1.GetBlockScalarsResponse
+	if err := c.retry(ctx, func() (err error) {
+		res, err = c.transport.getBlockScalars(ctx, &silentiumv1.GetBlockScalarsRequest{
+			BlockId:   height,
+			Verbose:   true,
+			Filter:    string(o.filter),
+			DustLimit: o.dustLimit,
+		})
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	txs := make([]Transaction, 0, len(res.GetTransactions()))
+	for _, tx := range res.GetTransactions() {
+		txHash, err := chainhash.NewHashFromStr(tx.GetTxid())
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid %s: %w", tx.GetTxid(), err)
+		}
+
+		scalar, err := parseScalar(tx.GetScalar())
+		if err != nil {
+			return nil, err
+		}
+
+		outputs := make([]TaprootOutput, 0, len(tx.GetOutputs()))
+		for _, out := range tx.GetOutputs() {
+			var key []byte
+			if out.GetKey() != "" {
+				if key, err = hex.DecodeString(out.GetKey()); err != nil {
+					return nil, fmt.Errorf("invalid taproot output key %s: %w", out.GetKey(), err)
+				}
+			}
+
+			outputs = append(outputs, TaprootOutput{
+				Index:  out.GetIndex(),
+				Key:    key,
+				Amount: out.GetAmount(),
+				Spent:  out.GetSpent(),
+			})
+		}
+
+		txs = append(txs, Transaction{TxHash: *txHash, Scalar: scalar, Outputs: outputs})
+	}
+
+	return txs, nil
+}
+
+func (c *client) GetBlockScalarsRange(ctx context.Context, from, to uint32, opts ...ScalarsOption) ([]BlockScalars, uint32, error) {
+	o := newScalarsOptions(opts)
+
+	var res *silentiumv1.GetBlockScalarsRangeResponse
+	if err := c.retry(ctx, func() (err error) {
+		res, err = c.transport.getBlockScalarsRange(ctx, &silentiumv1.GetBlockScalarsRangeRequest{
+			From:      from,
+			To:        to,
+			Limit:     o.limit,
+			Filter:    string(o.filter),
+			DustLimit: o.dustLimit,
+		})
+		return err
+	}); err != nil {
+		return nil, 0, err
+	}
+
+	blocks := make([]BlockScalars, 0, len(res.GetBlocks()))
+	for _, block := range res.GetBlocks() {
+		parsed, err := parseBlockScalars(block.GetHeight(), block.GetBlockhash(), block.GetScalars())
+		if err != nil {
+			return nil, 0, err
+		}
+
+		blocks = append(blocks, *parsed)
+	}
+
+	return blocks, res.GetNext(), nil
+}
+
+func (c *client) RangeScalars(ctx context.Context, from, to uint32, fn func(BlockScalars) error, opts ...ScalarsOption) error {
+	// next is 0 once the range is complete, the first page can't start at 0
+	if from == 0 {
+		from = 1
+	}
+
+	for from != 0 {
+		blocks, next, err := c.GetBlockScalarsRange(ctx, from, to, opts...)
+		if err != nil {
+			return err
+		}
+
+		for _, block := range blocks {
+			if err := fn(block); err != nil {
+				return err
+			}
+		}
+
+		// the server always moves forward, a page can't be requested twice
+		if next != 0 && next <= from {
+			return fmt.Errorf("invalid next page %d after %d", next, from)
+		}
+		from = next
+	}
+
+	return nil
+}
+
+func (c *client) GetMempoolScalars(ctx context.Context) ([]*btcec.PublicKey, error) {
+	var res *silentiumv1.GetMempoolScalarsResponse
+	if err := c.retry(ctx, func() (err error) {
+		res, err = c.transport.getMempoolScalars(ctx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return parseScalars(res.GetScalars())
+}
+
+func (c *client) GetBlockFilter(ctx context.Context, height uint32) (*BlockFilter, error) {
+	var res *silentiumv1.GetBlockFilterResponse
+	if err := c.retry(ctx, func() (err error) {
+		res, err = c.transport.getBlockFilter(ctx, height)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return parseBlockFilter(res.GetBlockhash(), res.GetFilter())
+}
+
+func (c *client) GetBlockTaprootFilter(ctx context.Context, height uint32) (*BlockFilter, error) {
+	var res *silentiumv1.GetBlockTaprootFilterResponse
+	if err := c.retry(ctx, func() (err error) {
+		res, err = c.transport.getBlockTaprootFilter(ctx, height)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	return parseBlockFilter(res.GetBlockhash(), res.GetFilter())
+}
+
+func (c *client) Close() error {
+	return c.transport.close()
+}
+
+// retry calls fn until it succeeds, fails with a non transient error or the retries are exhausted.
+func (c *client) retry(ctx context.Context, fn func() error) error {
+	delay := c.retryDelay
+
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= c.maxRetries || !isTransient(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+
+		delay *= 2
+	}
+}
+
+// isTransient returns true if the request may succeed if sent again.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	default:
+		return false
+	}
+}
+
+func parseScalar(scalar string) (*btcec.PublicKey, error) {
+	b, err := hex.DecodeString(scalar)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scalar %s: %w", scalar, err)
+	}
+
+	key, err := btcec.ParsePubKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid scalar %s: %w", scalar, err)
+	}
+
+	return key, nil
+}
+
+func parseScalars(scalars []string) ([]*btcec.PublicKey, error) {
+	keys := make([]*btcec.PublicKey, 0, len(scalars))
+	for _, scalar := range scalars {
+		key, err := parseScalar(scalar)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func parseBlockScalars(height uint32, blockhash string, scalars []string) (*BlockScalars, error) {
+	block := &BlockScalars{Height: height}
+
+	if blockhash != "" {
+		hash, err := chainhash.NewHashFromStr(blockhash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blockhash %s: %w", blockhash, err)
+		}
+		block.BlockHash = hash
+	}
+
+	keys, err := parseScalars(scalars)
+	if err != nil {
+		return nil, err
+	}
+	block.Scalars = keys
+
+	return block, nil
+}
+
+func parseBlockFilter(blockhash, filter string) (*BlockFilter, error) {
+	hash, err := chainhash.NewHashFromStr(blockhash)
+	if err != nil {
+		return nil, fmt.Errorf("invalid blockhash %s: %w", blockhash, err)
+	}
+
+	b, err := hex.DecodeString(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter: %w", err)
+	}
+
+	return &BlockFilter{BlockHash: *hash, Filter: b}, nil
+}
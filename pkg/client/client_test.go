@@ -0,0 +1,473 @@
+package client
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/louisinger/silentiumd/internal/application"
+	"github.com/louisinger/silentiumd/internal/domain"
+	grpcservice "github.com/louisinger/silentiumd/internal/interface/grpc"
+	"github.com/louisinger/silentiumd/internal/ports"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const testTip = 5
+
+func TestClient(t *testing.T) {
+	svc := newFakeService()
+	port := startServer(t, svc)
+
+	newClients := map[string]func() (Client, error){
+		"grpc": func() (Client, error) {
+			return NewGRPCClient(fmt.Sprintf("localhost:%d", port), nil, WithRetries(3, 10*time.Millisecond))
+		},
+		"rest": func() (Client, error) {
+			return NewRESTClient(fmt.Sprintf("http://localhost:%d", port), nil, WithRetries(3, 10*time.Millisecond))
+		},
+	}
+
+	for name, newClient := range newClients {
+		newClient := newClient
+
+		t.Run(name, func(t *testing.T) {
+			c, err := newClient()
+			require.NoError(t, err)
+			defer c.Close()
+
+			ctx := context.Background()
+
+			t.Run("chain tip", func(t *testing.T) {
+				tip, err := c.GetChainTipHeight(ctx)
+				require.NoError(t, err)
+				require.Equal(t, uint32(testTip), tip)
+			})
+
+			t.Run("block scalars", func(t *testing.T) {
+				scalars, err := c.GetBlockScalars(ctx, 2, WithFilter(AllScalars), WithDustLimit(330))
+				require.NoError(t, err)
+				require.Len(t, scalars, 1)
+				require.True(t, scalars[0].IsEqual(testScalar(2)))
+				require.Equal(t, fakeRequest{2, ports.AllScalars, 330}, svc.lastRequest())
+
+				_, err = c.GetBlockScalars(ctx, 2, WithFilter("bogus"))
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			})
+
+			t.Run("block transactions", func(t *testing.T) {
+				txs, err := c.GetBlockTransactions(ctx, 3)
+				require.NoError(t, err)
+				require.Len(t, txs, 1)
+				require.Equal(t, chainhash.Hash{3}, txs[0].TxHash)
+				require.True(t, txs[0].Scalar.IsEqual(testScalar(3)))
+				require.Equal(t, []TaprootOutput{
+					{Index: 0, Key: []byte{0x03, 0x01}, Amount: 1000, Spent: false},
+					{Index: 1, Key: []byte{0x03, 0x02}, Amount: 2000, Spent: true},
+				}, txs[0].Outputs)
+				require.Equal(t, fakeRequest{3, ports.UnspentScalars, 0}, svc.lastRequest())
+			})
+
+			t.Run("range", func(t *testing.T) {
+				blocks, next, err := c.GetBlockScalarsRange(ctx, 1, testTip, WithPageSize(2))
+				require.NoError(t, err)
+				require.Equal(t, uint32(3), next)
+				require.Len(t, blocks, 2)
+				require.Equal(t, uint32(1), blocks[0].Height)
+				require.Equal(t, testBlockHash(1), *blocks[0].BlockHash)
+
+				heights := make([]uint32, 0)
+				err = c.RangeScalars(ctx, 1, testTip, func(block BlockScalars) error {
+					require.Len(t, block.Scalars, 1)
+					require.True(t, block.Scalars[0].IsEqual(testScalar(block.Height)))
+					heights = append(heights, block.Height)
+					return nil
+				}, WithPageSize(2))
+				require.NoError(t, err)
+				require.Equal(t, []uint32{1, 2, 3, 4, 5}, heights)
+
+				// 0 is the first height
+				heights = heights[:0]
+				err = c.RangeScalars(ctx, 0, 2, func(block BlockScalars) error {
+					heights = append(heights, block.Height)
+					return nil
+				})
+				require.NoError(t, err)
+				require.Equal(t, []uint32{1, 2}, heights)
+
+				_, _, err = c.GetBlockScalarsRange(ctx, 3, 1)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			})
+
+			t.Run("mempool", func(t *testing.T) {
+				scalars, err := c.GetMempoolScalars(ctx)
+				require.NoError(t, err)
+				require.Len(t, scalars, 1)
+				require.True(t, scalars[0].IsEqual(testScalar(100)))
+			})
+
+			t.Run("filters", func(t *testing.T) {
+				filter, err := c.GetBlockFilter(ctx, 4)
+				require.NoError(t, err)
+				require.Equal(t, testBlockHash(4), filter.BlockHash)
+				require.Equal(t, []byte{0x04}, filter.Filter)
+
+				taprootFilter, err := c.GetBlockTaprootFilter(ctx, 4)
+				require.NoError(t, err)
+				require.Equal(t, testBlockHash(4), taprootFilter.BlockHash)
+				require.Equal(t, []byte{0x04, 0x04}, taprootFilter.Filter)
+
+				_, err = c.GetBlockTaprootFilter(ctx, 10)
+				require.Equal(t, codes.NotFound, status.Code(err))
+			})
+
+			t.Run("retries", func(t *testing.T) {
+				svc.failChainTip(2)
+				tip, err := c.GetChainTipHeight(ctx)
+				require.NoError(t, err)
+				require.Equal(t, uint32(testTip), tip)
+
+				svc.failChainTip(1)
+				_, err = c.(*client).withoutRetries().GetChainTipHeight(ctx)
+				require.Equal(t, codes.Unavailable, status.Code(err))
+			})
+
+			t.Run("subscribe", func(t *testing.T) {
+				subCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+				defer cancel()
+
+				sub, err := c.Subscribe(subCtx, 2)
+				require.NoError(t, err)
+				defer sub.Close()
+
+				first := svc.nextSubscription(t)
+				require.Equal(t, uint32(2), first.from)
+
+				first.events <- testBlockEvent(2)
+				event := receiveEvent(t, sub)
+				require.Equal(t, BlockEvent, event.Type)
+				require.Equal(t, uint32(2), event.Block.Height)
+				require.Equal(t, testBlockHash(2), *event.Block.BlockHash)
+				require.True(t, event.Block.Scalars[0].IsEqual(testScalar(2)))
+				require.Equal(t, "02", event.Filter)
+
+				first.events <- application.ChainEvent{Type: application.ChainReorg, ForkHeight: 1}
+				event = receiveEvent(t, sub)
+				require.Equal(t, ReorgEvent, event.Type)
+				require.Equal(t, uint32(1), event.ForkHeight)
+
+				first.events <- testBlockEvent(2)
+				first.events <- testBlockEvent(3)
+				require.Equal(t, uint32(2), receiveEvent(t, sub).Block.Height)
+				require.Equal(t, uint32(3), receiveEvent(t, sub).Block.Height)
+
+				// the server closes the subscription, the client resumes from the last block
+				close(first.events)
+				second := svc.nextSubscription(t)
+				require.Equal(t, uint32(3), second.from)
+
+				// the replayed block is dropped
+				second.events <- testBlockEvent(3)
+				second.events <- testBlockEvent(4)
+				require.Equal(t, uint32(4), receiveEvent(t, sub).Block.Height)
+
+				// the block 4 is orphaned while disconnected
+				close(second.events)
+				third := svc.nextSubscription(t)
+				require.Equal(t, uint32(4), third.from)
+
+				third.events <- testForkBlockEvent(4)
+				event = receiveEvent(t, sub)
+				require.Equal(t, ReorgEvent, event.Type)
+				require.Equal(t, uint32(3), event.ForkHeight)
+
+				event = receiveEvent(t, sub)
+				require.Equal(t, BlockEvent, event.Type)
+				require.Equal(t, uint32(4), event.Block.Height)
+				require.Equal(t, testForkBlockEvent(4).Block.Hash, *event.Block.BlockHash)
+
+				sub.Close()
+				_, ok := <-sub.Events()
+				require.False(t, ok)
+				require.NoError(t, sub.Err())
+			})
+
+			t.Run("subscribe new blocks", func(t *testing.T) {
+				subCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+				defer cancel()
+
+				sub, err := c.Subscribe(subCtx, 0)
+				require.NoError(t, err)
+				defer sub.Close()
+
+				// the stream starts at the tip, the tip block is not sent
+				first := svc.nextSubscription(t)
+				require.Equal(t, uint32(testTip), first.from)
+				first.events <- testBlockEvent(testTip)
+
+				// the client resumes from the tip even if no block was received
+				close(first.events)
+				second := svc.nextSubscription(t)
+				require.Equal(t, uint32(testTip), second.from)
+
+				// the tip changed while disconnected, the subscriber never received it so nothing is orphaned
+				second.events <- testForkBlockEvent(testTip)
+				event := receiveEvent(t, sub)
+				require.Equal(t, BlockEvent, event.Type)
+				require.Equal(t, testForkBlockEvent(testTip).Block.Hash, *event.Block.BlockHash)
+			})
+
+			t.Run("subscribe invalid", func(t *testing.T) {
+				_, err := c.Subscribe(ctx, testTip+1)
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			})
+		})
+	}
+}
+
+// withoutRetries returns a copy of the client sending the requests once.
+func (c *client) withoutRetries() *client {
+	return &client{transport: c.transport, options: options{}}
+}
+
+func receiveEvent(t *testing.T, sub *Subscription) Event {
+	select {
+	case event, ok := <-sub.Events():
+		require.True(t, ok, "subscription ended: %v", sub.Err())
+		return event
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event received")
+		return Event{}
+	}
+}
+
+func startServer(t *testing.T, svc application.SilentiumService) uint32 {
+	lis, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := uint32(lis.Addr().(*net.TCPAddr).Port)
+	require.NoError(t, lis.Close())
+
+	server, err := grpcservice.NewService(grpcservice.Config{Port: port, AppService: svc})
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	t.Cleanup(server.Stop)
+
+	// the gateway connection to the gRPC server may be dialed before the server listens, wait for it to be up
+	require.Eventually(t, func() bool {
+		resp, err := http.Get(fmt.Sprintf("http://localhost:%d/v1/chain/tip", port))
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 10*time.Second, 10*time.Millisecond)
+
+	return port
+}
+
+// testScalar is a deterministic scalar of the block at height.
+func testScalar(height uint32) *btcec.PublicKey {
+	var key [32]byte
+	key[31] = byte(height)
+	key[0] = 1
+
+	_, pubkey := btcec.PrivKeyFromBytes(key[:])
+	return pubkey
+}
+
+func testBlockHash(height uint32) chainhash.Hash {
+	return chainhash.Hash{0xbb, byte(height)}
+}
+
+func testBlockEvent(height uint32) application.ChainEvent {
+	return application.ChainEvent{
+		Type: application.BlockIndexed,
+		Block: domain.BlockScalars{
+			Height:  int32(height),
+			Hash:    testBlockHash(height),
+			Scalars: []string{hex.EncodeToString(testScalar(height).SerializeCompressed())},
+		},
+		Filter: fmt.Sprintf("%02x", height),
+	}
+}
+
+// testForkBlockEvent is the block replacing the test block at height.
+func testForkBlockEvent(height uint32) application.ChainEvent {
+	event := testBlockEvent(height)
+	event.Block.Hash = chainhash.Hash{0xcc, byte(height)}
+	return event
+}
+
+type fakeRequest struct {
+	height    uint32
+	filter    ports.ScalarsFilter
+	dustLimit int64
+}
+
+type fakeSubscription struct {
+	from   uint32
+	events chan application.ChainEvent
+}
+
+// fakeService indexes a block with 1 scalar at each height up to testTip.
+type fakeService struct {
+	lock              sync.Mutex
+	request           fakeRequest
+	chainTipFailures  int
+	subscriptionsChan chan fakeSubscription
+}
+
+func newFakeService() *fakeService {
+	return &fakeService{subscriptionsChan: make(chan fakeSubscription, 10)}
+}
+
+func (s *fakeService) lastRequest() fakeRequest {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.request
+}
+
+func (s *fakeService) failChainTip(n int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.chainTipFailures = n
+}
+
+func (s *fakeService) nextSubscription(t *testing.T) fakeSubscription {
+	select {
+	case sub := <-s.subscriptionsChan:
+		return sub
+	case <-time.After(5 * time.Second):
+		t.Fatal("no subscription started")
+		return fakeSubscription{}
+	}
+}
+
+func (s *fakeService) GetScalarsByHeight(height uint32, filter ports.ScalarsFilter, dustLimit int64) ([]string, error) {
+	txs, err := s.GetTransactionsByHeight(height, filter, dustLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	scalars := make([]string, 0, len(txs))
+	for _, tx := range txs {
+		scalars = append(scalars, hex.EncodeToString(tx.Scalar))
+	}
+	return scalars, nil
+}
+
+func (s *fakeService) GetTransactionsByHeight(height uint32, filter ports.ScalarsFilter, dustLimit int64) ([]*domain.SilentScalar, error) {
+	s.lock.Lock()
+	s.request = fakeRequest{height, filter, dustLimit}
+	s.lock.Unlock()
+
+	if height == 0 || height > testTip {
+		return nil, ports.ErrBlockNotFound{Height: int32(height)}
+	}
+
+	return []*domain.SilentScalar{{
+		TxHash: &chainhash.Hash{byte(height)},
+		Scalar: testScalar(height).SerializeCompressed(),
+		TaprootOutputs: []domain.TaprootOutput{
+			{Index: 0, Key: []byte{byte(height), 0x01}, Value: 1000},
+			{Index: 1, Key: []byte{byte(height), 0x02}, Value: 2000, Spent: true, SpentHeight: testTip},
+		},
+	}}, nil
+}
+
+func (s *fakeService) GetScalarsRange(from, to, limit uint32, filter ports.ScalarsFilter, dustLimit int64) ([]domain.BlockScalars, uint32, error) {
+	if from > to {
+		return nil, 0, application.ErrInvalidBlockRange
+	}
+
+	if limit == 0 {
+		limit = testTip
+	}
+
+	blocks := make([]domain.BlockScalars, 0)
+	height := from
+	for ; height <= to && height <= testTip && uint32(len(blocks)) < limit; height++ {
+		scalars, err := s.GetScalarsByHeight(height, filter, dustLimit)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		blocks = append(blocks, domain.BlockScalars{Height: int32(height), Hash: testBlockHash(height), Scalars: scalars})
+	}
+
+	next := uint32(0)
+	if height <= to && height <= testTip {
+		next = height
+	}
+
+	return blocks, next, nil
+}
+
+func (s *fakeService) GetMempoolScalars() ([]string, error) {
+	return []string{hex.EncodeToString(testScalar(100).SerializeCompressed())}, nil
+}
+
+func (s *fakeService) GetBlockFilter(height uint32) (string, string, error) {
+	return fmt.Sprintf("%02x", height), testBlockHash(height).String(), nil
+}
+
+func (s *fakeService) GetBlockTaprootFilter(height uint32) (string, string, error) {
+	if height > testTip {
+		return "", "", ports.ErrBlockNotFound{Height: int32(height)}
+	}
+
+	return fmt.Sprintf("%02x%02x", height, height), testBlockHash(height).String(), nil
+}
+
+func (s *fakeService) GetChainTip() (uint32, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.chainTipFailures > 0 {
+		s.chainTipFailures--
+		return 0, status.Error(codes.Unavailable, "node not ready")
+	}
+
+	return testTip, nil
+}
+
+// SubscribeScalars forwards the events sent by the test until it closes the subscription or ctx is done.
+func (s *fakeService) SubscribeScalars(ctx context.Context, from uint32) (<-chan application.ChainEvent, error) {
+	if from > testTip {
+		return nil, application.ErrInvalidBlockRange
+	}
+
+	in := make(chan application.ChainEvent)
+	out := make(chan application.ChainEvent)
+
+	go func() {
+		defer close(out)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-in:
+				if !ok {
+					return
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case out <- event:
+				}
+			}
+		}
+	}()
+
+	s.subscriptionsChan <- fakeSubscription{from, in}
+	return out, nil
+}
@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+
+	silentiumv1 "github.com/louisinger/silentiumd/api/protobuf/gen/silentium/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+)
+
+// subscriptionStartedHeader is sent by the server once a subscription is started.
+const subscriptionStartedHeader = "x-subscription-started"
+
+type grpcTransport struct {
+	conn *grpc.ClientConn
+	svc  silentiumv1.SilentiumServiceClient
+}
+
+// NewGRPCClient connects to the gRPC server at target (host:port).
+// the connection uses TLS if tlsConfig is not nil.
+func NewGRPCClient(target string, tlsConfig *tls.Config, opts ...Option) (Client, error) {
+	creds := insecure.NewCredentials()
+	if tlsConfig != nil {
+		creds = credentials.NewTLS(tlsConfig)
+	}
+
+	conn, err := grpc.Dial(target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		transport: &grpcTransport{conn, silentiumv1.NewSilentiumServiceClient(conn)},
+		options:   newOptions(opts),
+	}, nil
+}
+
+func (t *grpcTransport) getBlockScalars(ctx context.Context, req *silentiumv1.GetBlockScalarsRequest) (*silentiumv1.GetBlockScalarsResponse, error) {
+	return t.svc.GetBlockScalars(ctx, req)
+}
+
+func (t *grpcTransport) getBlockScalarsRange(ctx context.Context, req *silentiumv1.GetBlockScalarsRangeRequest) (*silentiumv1.GetBlockScalarsRangeResponse, error) {
+	return t.svc.GetBlockScalarsRange(ctx, req)
+}
+
+func (t *grpcTransport) getMempoolScalars(ctx context.Context) (*silentiumv1.GetMempoolScalarsResponse, error) {
+	return t.svc.GetMempoolScalars(ctx, &silentiumv1.GetMempoolScalarsRequest{})
+}
+
+func (t *grpcTransport) getBlockFilter(ctx context.Context, height uint32) (*silentiumv1.GetBlockFilterResponse, error) {
+	return t.svc.GetBlockFilter(ctx, &silentiumv1.GetBlockFilterRequest{BlockId: height})
+}
+
+func (t *grpcTransport) getBlockTaprootFilter(ctx context.Context, height uint32) (*silentiumv1.GetBlockTaprootFilterResponse, error) {
+	return t.svc.GetBlockTaprootFilter(ctx, &silentiumv1.GetBlockTaprootFilterRequest{BlockId: height})
+}
+
+func (t *grpcTransport) getChainTipHeight(ctx context.Context) (*silentiumv1.GetChainTipHeightResponse, error) {
+	return t.svc.GetChainTipHeight(ctx, &silentiumv1.GetChainTipHeightRequest{})
+}
+
+func (t *grpcTransport) subscribe(ctx context.Context, from uint32) (func() (*Event, error), error) {
+	stream, err := t.svc.SubscribeScalars(ctx, &silentiumv1.SubscribeScalarsRequest{From: from})
+	if err != nil {
+		return nil, err
+	}
+
+	// the server sends the header once the subscription is started, invalid requests fail here
+	header, err := stream.Header()
+	if err != nil {
+		return nil, err
+	}
+
+	// the stream ended without the header, its status is returned by Recv
+	if len(header.Get(subscriptionStartedHeader)) == 0 {
+		if _, err := stream.Recv(); err != nil {
+			return nil, err
+		}
+		return nil, status.Error(codes.Internal, "subscription started without header")
+	}
+
+	return func() (*Event, error) {
+		res, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if reorg := res.GetReorg(); reorg != nil {
+			return &Event{Type: ReorgEvent, ForkHeight: reorg.GetForkHeight()}, nil
+		}
+
+		block := res.GetBlock()
+		parsed, err := parseBlockScalars(block.GetHeight(), block.GetBlockhash(), block.GetScalars())
+		if err != nil {
+			return nil, err
+		}
+
+		return &Event{Type: BlockEvent, Block: parsed, Filter: block.GetFilter()}, nil
+	}, nil
+}
+
+func (t *grpcTransport) close() error {
+	return t.conn.Close()
+}
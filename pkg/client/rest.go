@@ -0,0 +1,315 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	silentiumv1 "github.com/louisinger/silentiumd/api/protobuf/gen/silentium/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	wsPath = "/v1/ws"
+	// wsReadTimeout closes the subscriptions not pinged by the server, it pings every 30s.
+	wsReadTimeout = 90 * time.Second
+	wsWriteWait   = 10 * time.Second
+)
+
+var unmarshalOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+type restTransport struct {
+	baseURL *url.URL
+	http    *http.Client
+	dialer  *websocket.Dialer
+}
+
+// NewRESTClient sends the requests to the REST API at baseURL (e.g. https://bitcoin.silentium.dev),
+// the subscriptions use the websocket endpoint. httpClient defaults to http.DefaultClient.
+func NewRESTClient(baseURL string, httpClient *http.Client, opts ...Option) (Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base url %s: scheme must be http or https", baseURL)
+	}
+
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	dialer := *websocket.DefaultDialer
+	if transport, ok := httpClient.Transport.(*http.Transport); ok {
+		dialer.TLSClientConfig = transport.TLSClientConfig
+		dialer.Proxy = transport.Proxy
+	}
+
+	return &client{
+		transport: &restTransport{u, httpClient, &dialer},
+		options:   newOptions(opts),
+	}, nil
+}
+
+func (t *restTransport) getBlockScalars(ctx context.Context, req *silentiumv1.GetBlockScalarsRequest) (*silentiumv1.GetBlockScalarsResponse, error) {
+	query := url.Values{}
+	if req.GetVerbose() {
+		query.Set("verbose", "true")
+	}
+	setScalarsQuery(query, req.GetFilter(), req.GetDustLimit())
+
+	res := &silentiumv1.GetBlockScalarsResponse{}
+	return res, t.get(ctx, fmt.Sprintf("/v1/block/%d/scalars", req.GetBlockId()), query, res)
+}
+
+func (t *restTransport) getBlockScalarsRange(ctx context.Context, req *silentiumv1.GetBlockScalarsRangeRequest) (*silentiumv1.GetBlockScalarsRangeResponse, error) {
+	query := url.Values{}
+	if req.GetLimit() > 0 {
+		query.Set("limit", strconv.FormatUint(uint64(req.GetLimit()), 10))
+	}
+	setScalarsQuery(query, req.GetFilter(), req.GetDustLimit())
+
+	res := &silentiumv1.GetBlockScalarsRangeResponse{}
+	return res, t.get(ctx, fmt.Sprintf("/v1/blocks/%d/%d/scalars", req.GetFrom(), req.GetTo()), query, res)
+}
+
+func (t *restTransport) getMempoolScalars(ctx context.Context) (*silentiumv1.GetMempoolScalarsResponse, error) {
+	res := &silentiumv1.GetMempoolScalarsResponse{}
+	return res, t.get(ctx, "/v1/mempool/scalars", nil, res)
+}
+
+func (t *restTransport) getBlockFilter(ctx context.Context, height uint32) (*silentiumv1.GetBlockFilterResponse, error) {
+	res := &silentiumv1.GetBlockFilterResponse{}
+	return res, t.get(ctx, fmt.Sprintf("/v1/block/%d/filter", height), nil, res)
+}
+
+func (t *restTransport) getBlockTaprootFilter(ctx context.Context, height uint32) (*silentiumv1.GetBlockTaprootFilterResponse, error) {
+	res := &silentiumv1.GetBlockTaprootFilterResponse{}
+	return res, t.get(ctx, fmt.Sprintf("/v1/block/%d/filter/taproot", height), nil, res)
+}
+
+func (t *restTransport) getChainTipHeight(ctx context.Context) (*silentiumv1.GetChainTipHeightResponse, error) {
+	res := &silentiumv1.GetChainTipHeightResponse{}
+	return res, t.get(ctx, "/v1/chain/tip", nil, res)
+}
+
+func (t *restTransport) close() error {
+	t.http.CloseIdleConnections()
+	return nil
+}
+
+// get decodes the JSON response in res, the errors are converted to gRPC status errors.
+func (t *restTransport) get(ctx context.Context, path string, query url.Values, res proto.Message) error {
+	u := *t.baseURL
+	u.Path += path
+	u.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return parseHTTPError(resp.StatusCode, body)
+	}
+
+	return unmarshalOptions.Unmarshal(body, res)
+}
+
+func setScalarsQuery(query url.Values, filter string, dustLimit uint64) {
+	if filter != "" {
+		query.Set("filter", filter)
+	}
+	if dustLimit > 0 {
+		query.Set("dust_limit", strconv.FormatUint(dustLimit, 10))
+	}
+}
+
+// parseHTTPError returns the status of the gateway error body, or maps the HTTP status if not set (e.g. by a proxy).
+func parseHTTPError(statusCode int, body []byte) error {
+	var gatewayErr struct {
+		Code    int32  `json:"code"`
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &gatewayErr); err == nil && gatewayErr.Code != 0 {
+		return status.Error(codes.Code(gatewayErr.Code), gatewayErr.Message)
+	}
+
+	code := codes.Unknown
+	switch statusCode {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		code = codes.Unavailable
+	}
+
+	return status.Errorf(code, "http status %d: %s", statusCode, strings.TrimSpace(string(body)))
+}
+
+// wsFrame is a frame of the websocket API, data depends on the type.
+type wsFrame struct {
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+}
+
+type wsBlock struct {
+	Height    uint32   `json:"height"`
+	Blockhash string   `json:"blockhash"`
+	Filter    string   `json:"filter"`
+	Scalars   []string `json:"scalars"`
+}
+
+type wsReorg struct {
+	ForkHeight uint32 `json:"fork_height"`
+}
+
+// subscribe opens a websocket subscribed to the blocks, scalars and reorg topics.
+// the server sends the blocks frame (with the filter) right before the scalars frame of the same block,
+// they are merged in a single event.
+func (t *restTransport) subscribe(ctx context.Context, from uint32) (func() (*Event, error), error) {
+	u := *t.baseURL
+	u.Scheme = "ws"
+	if t.baseURL.Scheme == "https" {
+		u.Scheme = "wss"
+	}
+	u.Path += wsPath
+
+	conn, _, err := t.dialer.DialContext(ctx, u.String(), nil)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	closed := make(chan struct{})
+	closeConn := func() {
+		select {
+		case <-closed:
+		default:
+			close(closed)
+			conn.Close()
+		}
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			closeConn()
+		case <-closed:
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(wsReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(wsWriteWait))
+	})
+
+	readFrame := func() (*wsFrame, error) {
+		var frame wsFrame
+		if err := conn.ReadJSON(&frame); err != nil {
+			closeConn()
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+
+		if frame.Type == "error" {
+			closeConn()
+			return nil, status.Error(codes.InvalidArgument, frame.Error)
+		}
+
+		return &frame, nil
+	}
+
+	if err := conn.WriteJSON(map[string]interface{}{
+		"action": "subscribe",
+		"topics": []string{"blocks", "scalars", "reorg"},
+		"from":   from,
+	}); err != nil {
+		closeConn()
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+
+	// wait for the acknowledgment
+	if _, err := readFrame(); err != nil {
+		return nil, err
+	}
+
+	var pending *wsBlock
+
+	return func() (*Event, error) {
+		for {
+			frame, err := readFrame()
+			if err != nil {
+				return nil, err
+			}
+
+			switch frame.Type {
+			case "blocks":
+				var block wsBlock
+				if err := json.Unmarshal(frame.Data, &block); err != nil {
+					return nil, err
+				}
+				pending = &block
+			case "scalars":
+				var block wsBlock
+				if err := json.Unmarshal(frame.Data, &block); err != nil {
+					return nil, err
+				}
+
+				if pending != nil && pending.Height == block.Height && pending.Blockhash == block.Blockhash {
+					block.Filter = pending.Filter
+				}
+				pending = nil
+
+				parsed, err := parseBlockScalars(block.Height, block.Blockhash, block.Scalars)
+				if err != nil {
+					return nil, err
+				}
+
+				return &Event{Type: BlockEvent, Block: parsed, Filter: block.Filter}, nil
+			case "reorg":
+				var reorg wsReorg
+				if err := json.Unmarshal(frame.Data, &reorg); err != nil {
+					return nil, err
+				}
+
+				return &Event{Type: ReorgEvent, ForkHeight: reorg.ForkHeight}, nil
+			}
+		}
+	}, nil
+}
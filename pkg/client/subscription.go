@@ -0,0 +1,235 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const subscriptionBufferSize = 100
+
+type EventType int
+
+const (
+	// BlockEvent notifies an indexed block.
+	BlockEvent EventType = iota
+	// ReorgEvent notifies that the blocks above ForkHeight are orphaned.
+	ReorgEvent
+)
+
+// Event is a chain event received by a Subscription.
+type Event struct {
+	Type EventType
+	// Block is the indexed block with its unspent scalars, set for BlockEvent.
+	Block *BlockScalars
+	// Filter is the hex-encoded taproot filter of the block (see GetBlockTaprootFilter), set for BlockEvent.
+	// it is empty for the replayed blocks indexed before the taproot filters were stored.
+	Filter string
+	// ForkHeight is the last block kept by a ReorgEvent.
+	ForkHeight uint32
+}
+
+// Subscription receives the chain events until it is closed or fails.
+type Subscription struct {
+	events chan Event
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	lock sync.Mutex
+	err  error
+}
+
+// Events returns the channel of the events, it is closed when the subscription ends, see Err.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+// Err returns the error that ended the subscription, nil if closed by the caller.
+func (s *Subscription) Err() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.err
+}
+
+// Close ends the subscription and waits for the events channel to be closed.
+func (s *Subscription) Close() {
+	s.cancel()
+	<-s.done
+}
+
+func (c *client) Subscribe(ctx context.Context, from uint32) (*Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+
+	// without replay, the stream starts at the indexed tip so a new stream can be opened from a known block
+	var tip *BlockScalars
+	if from == 0 {
+		var err error
+		if tip, err = c.getTipBlock(ctx); err != nil {
+			cancel()
+			return nil, err
+		}
+
+		if tip != nil {
+			from = tip.Height
+		}
+	}
+
+	// the first stream is opened synchronously to report the invalid requests
+	var recv func() (*Event, error)
+	if err := c.retry(ctx, func() (err error) {
+		recv, err = c.transport.subscribe(ctx, from)
+		return err
+	}); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	sub := &Subscription{
+		events: make(chan Event, subscriptionBufferSize),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go c.forwardEvents(ctx, sub, recv, from, tip)
+
+	return sub, nil
+}
+
+// getTipBlock returns the block at the indexed tip, nil if the index is empty.
+func (c *client) getTipBlock(ctx context.Context) (*BlockScalars, error) {
+	height, err := c.GetChainTipHeight(ctx)
+	if err != nil || height == 0 {
+		return nil, err
+	}
+
+	blocks, _, err := c.GetBlockScalarsRange(ctx, height, height)
+	if err != nil || len(blocks) == 0 {
+		return nil, err
+	}
+
+	return &blocks[0], nil
+}
+
+// forwardEvents sends the received events to the subscription.
+// if the stream fails with a transient error, a new one is opened from the last sent block:
+// the block replayed at its height is dropped if it has the same hash, else a ReorgEvent is sent before it.
+// tip is the block a subscription without replay starts from, it is dropped the same way but never orphaned.
+func (c *client) forwardEvents(ctx context.Context, sub *Subscription, recv func() (*Event, error), from uint32, tip *BlockScalars) {
+	var err error
+	defer func() {
+		if ctx.Err() != nil {
+			err = nil
+		}
+
+		sub.lock.Lock()
+		sub.err = err
+		sub.lock.Unlock()
+
+		close(sub.events)
+		close(sub.done)
+		sub.cancel()
+	}()
+
+	send := func(event Event) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case sub.events <- event:
+			return true
+		}
+	}
+
+	// last is the last block sent, next is the height to replay the blocks from if no block is sent
+	var last *BlockScalars
+	next := from
+
+	// replayed is the block the first block of the stream is compared to, sent if the subscriber received it
+	replayed, sent := tip, false
+	retries := 0
+
+	for {
+		var event *Event
+		event, err = recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = status.Error(codes.Unavailable, "subscription closed by the server")
+			}
+
+			if retries >= c.maxRetries || !isTransient(err) {
+				return
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(c.retryDelay << retries):
+			}
+			retries++
+
+			resumeFrom := next
+			if last != nil {
+				resumeFrom, replayed, sent = last.Height, last, true
+			}
+
+			newRecv, subErr := c.transport.subscribe(ctx, resumeFrom)
+			if subErr != nil {
+				// reported by the next attempt
+				newRecv = func() (*Event, error) { return nil, subErr }
+			}
+			recv = newRecv
+			continue
+		}
+
+		retries = 0
+
+		if replayed != nil {
+			switch {
+			case event.Type == ReorgEvent:
+				if event.ForkHeight < replayed.Height {
+					replayed = nil
+				}
+			case event.Block.Height == replayed.Height && sameBlockHash(event.Block, replayed):
+				replayed = nil
+				continue
+			case event.Block.Height <= replayed.Height && sent:
+				// the block has been orphaned while disconnected
+				replayed = nil
+				if !send(Event{Type: ReorgEvent, ForkHeight: event.Block.Height - 1}) {
+					return
+				}
+			default:
+				replayed = nil
+			}
+		}
+
+		switch event.Type {
+		case BlockEvent:
+			last = event.Block
+		case ReorgEvent:
+			if last != nil && event.ForkHeight < last.Height {
+				last = nil
+				next = event.ForkHeight + 1
+			} else if last == nil && (next == 0 || event.ForkHeight+1 < next) {
+				next = event.ForkHeight + 1
+			}
+		}
+
+		if !send(*event) {
+			return
+		}
+	}
+}
+
+// sameBlockHash returns true if the blocks have the same hash,
+// the blocks indexed before the hashes were stored can't be compared.
+func sameBlockHash(a, b *BlockScalars) bool {
+	if a.BlockHash == nil || b.BlockHash == nil {
+		return true
+	}
+	return *a.BlockHash == *b.BlockHash
+}
@@ -0,0 +1,273 @@
+// Package scanner finds the silent payment (BIP352) outputs of a receiver.
+//
+// silentiumd indexes the scalar input_hash * sum(input public keys) of each eligible transaction,
+// the receiver only needs its scan private key, spend public key and the taproot output keys to scan it.
+package scanner
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/louisinger/silentiumd/pkg/address"
+)
+
+var (
+	sharedSecretTag = []byte("BIP0352/SharedSecret")
+
+	// ErrInvalidTweak is returned for the tweaks not lower than the curve order, it has a negligible probability.
+	ErrInvalidTweak = errors.New("invalid tweak")
+)
+
+// Scanner matches the taproot outputs paying the silent payment address of its keys, or any of its labels.
+type Scanner struct {
+	scanKey  *btcec.PrivateKey
+	spendKey *btcec.PublicKey
+	// labels maps the compressed label points to their label
+	labels map[[btcec.PubKeyBytesLenCompressed]byte]label
+}
+
+type label struct {
+	m     uint32
+	tweak btcec.ModNScalar
+}
+
+// Transaction is an indexed transaction to scan.
+type Transaction struct {
+	TxHash chainhash.Hash
+	// Scalar is the silentiumd scalar of the transaction, input_hash * sum(input public keys).
+	Scalar  *btcec.PublicKey
+	Outputs []TaprootOutput
+}
+
+type TaprootOutput struct {
+	Index uint32
+	// Key is the x-only output key.
+	Key []byte
+}
+
+// Output is a taproot output paying the receiver.
+type Output struct {
+	TxHash chainhash.Hash
+	Index  uint32
+	Key    []byte
+	// Tweak is added to the spend private key to get the output private key, it includes the label tweak.
+	Tweak []byte
+	// Label is the label of the address receiving the output, nil if not labeled.
+	Label *uint32
+}
+
+// New returns a scanner of the address of scanKey and spendKey.
+// the labels are the m values of the labeled addresses to scan, 0 being the change label.
+func New(scanKey *btcec.PrivateKey, spendKey *btcec.PublicKey, labels ...uint32) (*Scanner, error) {
+	s := &Scanner{
+		scanKey:  scanKey,
+		spendKey: spendKey,
+		labels:   make(map[[btcec.PubKeyBytesLenCompressed]byte]label, len(labels)),
+	}
+
+	for _, m := range labels {
+		tweak, err := address.LabelTweak(scanKey, m)
+		if err != nil {
+			return nil, fmt.Errorf("label %d: %w", m, err)
+		}
+
+		var point btcec.JacobianPoint
+		btcec.ScalarBaseMultNonConst(tweak, &point)
+		point.ToAffine()
+
+		var key [btcec.PubKeyBytesLenCompressed]byte
+		copy(key[:], btcec.NewPublicKey(&point.X, &point.Y).SerializeCompressed())
+		s.labels[key] = label{m, *tweak}
+	}
+
+	return s, nil
+}
+
+// Zero wipes the scan private key and the label tweaks from memory, the scanner can't be used afterwards.
+// the scan key given to New is zeroed too.
+func (s *Scanner) Zero() {
+	s.scanKey.Zero()
+
+	for key := range s.labels {
+		// overwrites the map entry before removing it
+		s.labels[key] = label{}
+		delete(s.labels, key)
+	}
+}
+
+// ScanBlock returns the outputs of the transactions paying the receiver.
+func (s *Scanner) ScanBlock(txs []Transaction) ([]Output, error) {
+	outputs := make([]Output, 0)
+	for _, tx := range txs {
+		found, err := s.ScanTransaction(tx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", tx.TxHash, err)
+		}
+
+		outputs = append(outputs, found...)
+	}
+
+	return outputs, nil
+}
+
+// ScanTransaction returns the outputs of the transaction paying the receiver.
+func (s *Scanner) ScanTransaction(tx Transaction) ([]Output, error) {
+	keys := make([][]byte, 0, len(tx.Outputs))
+	for _, out := range tx.Outputs {
+		keys = append(keys, out.Key)
+	}
+
+	matches, err := s.Scan(tx.Scalar, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make([]Output, 0, len(matches))
+	for _, match := range matches {
+		match.TxHash = tx.TxHash
+		match.Index = tx.Outputs[match.Index].Index
+		outputs = append(outputs, match)
+	}
+
+	return outputs, nil
+}
+
+// Scan returns the output keys paying the receiver, the Index of the returned outputs is the position in outputKeys.
+func (s *Scanner) Scan(scalar *btcec.PublicKey, outputKeys [][]byte) ([]Output, error) {
+	// ecdh_shared_secret = b_scan * input_hash * A
+	var point, sharedSecret btcec.JacobianPoint
+	scalar.AsJacobian(&point)
+	btcec.ScalarMultNonConst(&s.scanKey.Key, &point, &sharedSecret)
+	sharedSecret.ToAffine()
+	secret := btcec.NewPublicKey(&sharedSecret.X, &sharedSecret.Y).SerializeCompressed()
+
+	var spendKey btcec.JacobianPoint
+	s.spendKey.AsJacobian(&spendKey)
+
+	remaining := make([]int, 0, len(outputKeys))
+	parsed := make(map[int]*btcec.PublicKey, len(outputKeys))
+	for i, key := range outputKeys {
+		outputKey, err := schnorr.ParsePubKey(key)
+		if err != nil {
+			continue
+		}
+		remaining = append(remaining, i)
+		parsed[i] = outputKey
+	}
+
+	outputs := make([]Output, 0)
+
+	// the outputs of a receiver are created with k = 0, 1, ..., the scan stops at the first k not found
+	for k := uint32(0); len(remaining) > 0; k++ {
+		tweak, err := sharedSecretTweak(secret, k)
+		if err != nil {
+			return nil, err
+		}
+
+		// P_k = B_spend + t_k * G
+		var tG, pk btcec.JacobianPoint
+		btcec.ScalarBaseMultNonConst(tweak, &tG)
+		btcec.AddNonConst(&spendKey, &tG, &pk)
+		pk.ToAffine()
+
+		found := false
+		for j, i := range remaining {
+			output, ok := s.match(&pk, tweak, parsed[i])
+			if !ok {
+				continue
+			}
+
+			output.Index = uint32(i)
+			output.Key = outputKeys[i]
+			outputs = append(outputs, output)
+
+			remaining = append(remaining[:j], remaining[j+1:]...)
+			found = true
+			break
+		}
+
+		if !found {
+			break
+		}
+	}
+
+	return outputs, nil
+}
+
+// match returns the tweak of the output if it is P_k, or P_k plus a label point.
+// the outputs are checked in order, an output matching P_k with a label is found before a later one matching P_k alone.
+func (s *Scanner) match(pk *btcec.JacobianPoint, tweak *btcec.ModNScalar, outputKey *btcec.PublicKey) (Output, bool) {
+	pkX, outputX := pk.X.Bytes(), schnorr.SerializePubKey(outputKey)
+	if bytes.Equal(pkX[:], outputX) {
+		return Output{Tweak: serializeScalar(tweak)}, true
+	}
+
+	if len(s.labels) == 0 {
+		return Output{}, false
+	}
+
+	// -P_k
+	negPk := *pk
+	negPk.Y.Negate(1).Normalize()
+
+	var output btcec.JacobianPoint
+	outputKey.AsJacobian(&output)
+
+	// the output key is x-only, label = output - P_k or label = -output - P_k
+	candidates := []btcec.JacobianPoint{output, output}
+	candidates[1].Y.Negate(1).Normalize()
+
+	for _, candidate := range candidates {
+		var labelPoint btcec.JacobianPoint
+		btcec.AddNonConst(&candidate, &negPk, &labelPoint)
+		if labelPoint.Z.IsZero() {
+			continue
+		}
+		labelPoint.ToAffine()
+
+		var key [btcec.PubKeyBytesLenCompressed]byte
+		copy(key[:], btcec.NewPublicKey(&labelPoint.X, &labelPoint.Y).SerializeCompressed())
+
+		l, ok := s.labels[key]
+		if !ok {
+			continue
+		}
+
+		m := l.m
+		outputTweak := new(btcec.ModNScalar).Add2(tweak, &l.tweak)
+
+		return Output{Tweak: serializeScalar(outputTweak), Label: &m}, true
+	}
+
+	return Output{}, false
+}
+
+// sharedSecretTweak returns t_k = hash_BIP0352/SharedSecret(ser_P(ecdh_shared_secret) || ser_32(k)).
+func sharedSecretTweak(sharedSecret []byte, k uint32) (*btcec.ModNScalar, error) {
+	msg := make([]byte, len(sharedSecret)+4)
+	copy(msg, sharedSecret)
+	binary.BigEndian.PutUint32(msg[len(sharedSecret):], k)
+
+	return hashToScalar(sharedSecretTag, msg)
+}
+
+func hashToScalar(tag, msg []byte) (*btcec.ModNScalar, error) {
+	hash := chainhash.TaggedHash(tag, msg)
+
+	scalar := new(btcec.ModNScalar)
+	if overflow := scalar.SetByteSlice(hash[:]); overflow || scalar.IsZero() {
+		return nil, ErrInvalidTweak
+	}
+
+	return scalar, nil
+}
+
+func serializeScalar(scalar *btcec.ModNScalar) []byte {
+	b := scalar.Bytes()
+	return b[:]
+}
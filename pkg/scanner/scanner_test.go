@@ -0,0 +1,254 @@
+package scanner_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/pkg/scanner"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanner(t *testing.T) {
+	var testVectors []testVector
+	openJSONFile(t, "../../internal/domain/test_data/test_vectors.json", &testVectors)
+
+	for _, testVector := range testVectors {
+		testVector := testVector
+
+		t.Run(testVector.Comment, func(t *testing.T) {
+			for _, receiving := range testVector.Receiving {
+				scalar := computeScalar(t, receiving.Given.Vin)
+				if scalar == nil {
+					require.Empty(t, receiving.Expected.Outputs)
+					continue
+				}
+
+				scanKey, _ := btcec.PrivKeyFromBytes(decodeHex(t, receiving.Given.KeyMaterial.ScanPrivKey))
+				spendKey, spendPubKey := btcec.PrivKeyFromBytes(decodeHex(t, receiving.Given.KeyMaterial.SpendPrivKey))
+
+				s, err := scanner.New(scanKey, spendPubKey, receiving.Given.Labels...)
+				require.NoError(t, err)
+
+				outputKeys := make([][]byte, 0, len(receiving.Given.Outputs))
+				for _, output := range receiving.Given.Outputs {
+					outputKeys = append(outputKeys, decodeHex(t, output))
+				}
+
+				outputs, err := s.Scan(scalar, outputKeys)
+				require.NoError(t, err)
+
+				found := make([]expectedOutput, 0, len(outputs))
+				for _, output := range outputs {
+					require.Equal(t, outputKeys[output.Index], output.Key)
+
+					// the output is spendable with b_spend + tweak
+					var tweak btcec.ModNScalar
+					require.False(t, tweak.SetByteSlice(output.Tweak))
+					outputPrvKey := btcec.PrivKeyFromScalar(tweak.Add(&spendKey.Key))
+					require.Equal(t, output.Key, schnorr.SerializePubKey(outputPrvKey.PubKey()))
+
+					found = append(found, expectedOutput{
+						PubKey:       hex.EncodeToString(output.Key),
+						PrivKeyTweak: hex.EncodeToString(output.Tweak),
+					})
+				}
+
+				expected := append([]expectedOutput{}, receiving.Expected.Outputs...)
+
+				sortOutputs(found)
+				sortOutputs(expected)
+				require.Equal(t, expected, found)
+			}
+		})
+	}
+}
+
+func TestScanTransaction(t *testing.T) {
+	var testVectors []testVector
+	openJSONFile(t, "../../internal/domain/test_data/test_vectors.json", &testVectors)
+
+	// a transaction with several outputs paying the same labeled address
+	var receiving receivingVector
+	for _, testVector := range testVectors {
+		for _, r := range testVector.Receiving {
+			if len(r.Given.Labels) == 1 && len(r.Given.Outputs) > 1 && len(r.Expected.Outputs) == len(r.Given.Outputs) {
+				receiving = r
+			}
+		}
+	}
+	require.NotEmpty(t, receiving.Given.Outputs)
+
+	scanKey, _ := btcec.PrivKeyFromBytes(decodeHex(t, receiving.Given.KeyMaterial.ScanPrivKey))
+	_, spendPubKey := btcec.PrivKeyFromBytes(decodeHex(t, receiving.Given.KeyMaterial.SpendPrivKey))
+
+	tx := scanner.Transaction{
+		TxHash: chainhash.Hash{0x01},
+		Scalar: computeScalar(t, receiving.Given.Vin),
+	}
+	for i, output := range receiving.Given.Outputs {
+		// the taproot outputs are not the only outputs of the transaction
+		tx.Outputs = append(tx.Outputs, scanner.TaprootOutput{Index: uint32(2 * i), Key: decodeHex(t, output)})
+	}
+
+	t.Run("without labels", func(t *testing.T) {
+		s, err := scanner.New(scanKey, spendPubKey)
+		require.NoError(t, err)
+
+		outputs, err := s.ScanTransaction(tx)
+		require.NoError(t, err)
+		require.Empty(t, outputs)
+	})
+
+	t.Run("with labels", func(t *testing.T) {
+		s, err := scanner.New(scanKey, spendPubKey, receiving.Given.Labels...)
+		require.NoError(t, err)
+
+		outputs, err := s.ScanBlock([]scanner.Transaction{tx})
+		require.NoError(t, err)
+		require.Len(t, outputs, len(receiving.Expected.Outputs))
+
+		for _, output := range outputs {
+			require.Equal(t, tx.TxHash, output.TxHash)
+			require.Equal(t, tx.Outputs[output.Index/2].Key, output.Key)
+			require.NotNil(t, output.Label)
+			require.Equal(t, receiving.Given.Labels[0], *output.Label)
+		}
+	})
+}
+
+// computeScalar computes the silentiumd scalar of the transaction inputs, nil if no input is eligible.
+func computeScalar(t *testing.T, inputs []inputVector) *btcec.PublicKey {
+	t.Helper()
+
+	vin := make([]*wire.TxIn, 0, len(inputs))
+	for _, input := range inputs {
+		vin = append(vin, input.toWire(t))
+	}
+
+	silentScalar := &domain.SilentScalar{TxIn: vin}
+	err := silentScalar.ComputeScalar(func(outpoint wire.OutPoint) ([]byte, error) {
+		for _, input := range inputs {
+			if input.TxId == outpoint.Hash.String() && input.Vout == outpoint.Index {
+				return hex.DecodeString(input.Prevout.ScriptPubKey.Hex)
+			}
+		}
+
+		return nil, errors.New("scriptPubKey not found")
+	})
+	if errors.Is(err, domain.ErrNoEligibleInputs) {
+		return nil
+	}
+	require.NoError(t, err)
+
+	scalar, err := btcec.ParsePubKey(silentScalar.Scalar)
+	require.NoError(t, err)
+
+	return scalar
+}
+
+func sortOutputs(outputs []expectedOutput) {
+	sort.Slice(outputs, func(i, j int) bool { return outputs[i].PubKey < outputs[j].PubKey })
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func decodeWitness(t *testing.T, witness []byte) wire.TxWitness {
+	t.Helper()
+
+	if len(witness) == 0 {
+		return nil
+	}
+
+	reader := bytes.NewReader(witness)
+
+	count, err := wire.ReadVarInt(reader, 0)
+	require.NoError(t, err)
+
+	wit := make(wire.TxWitness, count)
+	for i := range wit {
+		length, err := wire.ReadVarInt(reader, 0)
+		require.NoError(t, err)
+
+		wit[i] = make([]byte, length)
+		_, err = io.ReadFull(reader, wit[i])
+		require.NoError(t, err)
+	}
+
+	return wit
+}
+
+func openJSONFile(t *testing.T, path string, result interface{}) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	require.NoError(t, json.NewDecoder(file).Decode(result))
+}
+
+type inputVector struct {
+	TxId        string `json:"txid"`
+	Vout        uint32 `json:"vout"`
+	ScriptSig   string `json:"scriptSig"`
+	TxInWitness string `json:"txinwitness"`
+	Prevout     struct {
+		ScriptPubKey struct {
+			Hex string `json:"hex"`
+		} `json:"scriptPubKey"`
+	} `json:"prevout"`
+}
+
+func (iv *inputVector) toWire(t *testing.T) *wire.TxIn {
+	txid, err := chainhash.NewHashFromStr(iv.TxId)
+	require.NoError(t, err)
+
+	return &wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: *txid, Index: iv.Vout},
+		SignatureScript:  decodeHex(t, iv.ScriptSig),
+		Witness:          decodeWitness(t, decodeHex(t, iv.TxInWitness)),
+		Sequence:         wire.MaxTxInSequenceNum,
+	}
+}
+
+type expectedOutput struct {
+	PubKey       string `json:"pub_key"`
+	PrivKeyTweak string `json:"priv_key_tweak"`
+}
+
+type testVector struct {
+	Comment   string            `json:"comment"`
+	Receiving []receivingVector `json:"receiving"`
+}
+
+type receivingVector struct {
+	Given struct {
+		Vin         []inputVector `json:"vin"`
+		Outputs     []string      `json:"outputs"`
+		KeyMaterial struct {
+			SpendPrivKey string `json:"spend_priv_key"`
+			ScanPrivKey  string `json:"scan_priv_key"`
+		} `json:"key_material"`
+		Labels []uint32 `json:"labels"`
+	} `json:"given"`
+	Expected struct {
+		Outputs []expectedOutput `json:"outputs"`
+	} `json:"expected"`
+}
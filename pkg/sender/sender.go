@@ -0,0 +1,178 @@
+// Package sender derives the taproot outputs paying silent payment (BIP352) addresses.
+//
+// the eligible inputs are selected as silentiumd does to compute the scalars,
+// the receivers find the outputs by scanning the scalar of the transaction.
+package sender
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/internal/domain"
+	"github.com/louisinger/silentiumd/pkg/address"
+)
+
+var (
+	sharedSecretTag = []byte("BIP0352/SharedSecret")
+
+	// ErrNoEligibleInputs is returned if no input can be used to derive the outputs, the transaction can't pay silent payment addresses.
+	ErrNoEligibleInputs = errors.New("no eligible inputs")
+	// ErrInvalidTweak is returned for the tweaks not lower than the curve order, it has a negligible probability.
+	ErrInvalidTweak = errors.New("invalid tweak")
+	// ErrPrivateKeyMismatch is returned if the private key of an eligible input doesn't match the public key it reveals.
+	ErrPrivateKeyMismatch = errors.New("private key doesn't match the input public key")
+)
+
+// Input is a transaction input signed by the sender.
+type Input struct {
+	// TxIn is the signed input, its script sig and witness are needed to select the eligible inputs.
+	TxIn *wire.TxIn
+	// PrevoutScript is the script of the output spent by the input.
+	PrevoutScript []byte
+	PrivateKey    *btcec.PrivateKey
+}
+
+// OutputScripts returns the P2TR scripts paying the recipients, in the same order.
+// the recipients sharing a scan key get the outputs k = 0, 1, ... in their order.
+func OutputScripts(inputs []Input, recipients []*address.Address) ([][]byte, error) {
+	inputHash, privateKey, err := sumInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	// input_hash * a, shared by all the recipients
+	var tweakedKey btcec.ModNScalar
+	tweakedKey.Set(inputHash).Mul(privateKey)
+
+	// k is incremented per scan key, the shared secret is computed once per scan key
+	type scanGroup struct {
+		secret []byte
+		k      uint32
+	}
+	groups := make(map[[btcec.PubKeyBytesLenCompressed]byte]*scanGroup)
+
+	scripts := make([][]byte, 0, len(recipients))
+	for _, recipient := range recipients {
+		scanKey, spendKey := recipient.ScanKey, recipient.SpendKey
+
+		var groupKey [btcec.PubKeyBytesLenCompressed]byte
+		copy(groupKey[:], scanKey.SerializeCompressed())
+
+		group, ok := groups[groupKey]
+		if !ok {
+			// ecdh_shared_secret = input_hash * a * B_scan
+			var point, sharedSecret btcec.JacobianPoint
+			scanKey.AsJacobian(&point)
+			btcec.ScalarMultNonConst(&tweakedKey, &point, &sharedSecret)
+			sharedSecret.ToAffine()
+
+			group = &scanGroup{secret: btcec.NewPublicKey(&sharedSecret.X, &sharedSecret.Y).SerializeCompressed()}
+			groups[groupKey] = group
+		}
+
+		outputKey, err := outputKey(group.secret, group.k, spendKey)
+		if err != nil {
+			return nil, err
+		}
+		group.k++
+
+		script, err := txscript.PayToTaprootScript(outputKey)
+		if err != nil {
+			return nil, err
+		}
+
+		scripts = append(scripts, script)
+	}
+
+	return scripts, nil
+}
+
+// sumInputs returns the input hash and the sum of the eligible inputs private keys,
+// the taproot keys are negated if their public key has an odd y.
+func sumInputs(inputs []Input) (*btcec.ModNScalar, *btcec.ModNScalar, error) {
+	vin := make([]*wire.TxIn, 0, len(inputs))
+	for _, input := range inputs {
+		vin = append(vin, input.TxIn)
+	}
+
+	privateKey := new(btcec.ModNScalar)
+	eligible := 0
+
+	for _, input := range inputs {
+		getPrevout := func(wire.OutPoint) ([]byte, error) { return input.PrevoutScript, nil }
+
+		// the inputs not used by silentiumd to compute the scalar are skipped
+		inputType, pubkey, err := domain.ClassifyInput(input.TxIn, getPrevout)
+		if inputType == domain.InputWitnessV2Plus {
+			return nil, nil, fmt.Errorf("%w: %s", ErrNoEligibleInputs, err)
+		}
+
+		if err != nil || pubkey == nil {
+			continue
+		}
+
+		if input.PrivateKey == nil {
+			return nil, nil, fmt.Errorf("missing private key of input %s", input.TxIn.PreviousOutPoint)
+		}
+
+		isTaproot := inputType == domain.InputP2TR
+
+		// the taproot inputs reveal the x-only key
+		if isTaproot && !bytes.Equal(schnorr.SerializePubKey(input.PrivateKey.PubKey()), schnorr.SerializePubKey(pubkey)) ||
+			!isTaproot && !input.PrivateKey.PubKey().IsEqual(pubkey) {
+			return nil, nil, fmt.Errorf("%w: input %s", ErrPrivateKeyMismatch, input.TxIn.PreviousOutPoint)
+		}
+
+		key := input.PrivateKey.Key
+		if isTaproot && input.PrivateKey.PubKey().SerializeCompressed()[0] == 0x03 {
+			key.Negate()
+		}
+
+		privateKey.Add(&key)
+		eligible++
+	}
+
+	if eligible == 0 || privateKey.IsZero() {
+		return nil, nil, ErrNoEligibleInputs
+	}
+
+	sumPublicKeys := btcec.PrivKeyFromScalar(privateKey).PubKey()
+	inputHash := domain.InputHash(vin, sumPublicKeys)
+
+	scalar := new(btcec.ModNScalar)
+	if overflow := scalar.SetByteSlice(inputHash[:]); overflow || scalar.IsZero() {
+		return nil, nil, ErrInvalidTweak
+	}
+
+	return scalar, privateKey, nil
+}
+
+// outputKey returns P_k = B_m + hash_BIP0352/SharedSecret(ser_P(ecdh_shared_secret) || ser_32(k)) * G.
+func outputKey(sharedSecret []byte, k uint32, spendKey *btcec.PublicKey) (*btcec.PublicKey, error) {
+	msg := make([]byte, len(sharedSecret)+4)
+	copy(msg, sharedSecret)
+	binary.BigEndian.PutUint32(msg[len(sharedSecret):], k)
+
+	hash := chainhash.TaggedHash(sharedSecretTag, msg)
+
+	var tweak btcec.ModNScalar
+	if overflow := tweak.SetByteSlice(hash[:]); overflow || tweak.IsZero() {
+		return nil, ErrInvalidTweak
+	}
+
+	var spend, tG, pk btcec.JacobianPoint
+	spendKey.AsJacobian(&spend)
+	btcec.ScalarBaseMultNonConst(&tweak, &tG)
+	btcec.AddNonConst(&spend, &tG, &pk)
+	pk.ToAffine()
+
+	// the output key is x-only
+	return schnorr.ParsePubKey(schnorr.SerializePubKey(btcec.NewPublicKey(&pk.X, &pk.Y)))
+}
@@ -0,0 +1,198 @@
+package sender_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/louisinger/silentiumd/pkg/address"
+	"github.com/louisinger/silentiumd/pkg/sender"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputScripts(t *testing.T) {
+	var testVectors []testVector
+	openJSONFile(t, "../../internal/domain/test_data/test_vectors.json", &testVectors)
+
+	for _, testVector := range testVectors {
+		testVector := testVector
+
+		t.Run(testVector.Comment, func(t *testing.T) {
+			for _, sending := range testVector.Sending {
+				inputs := make([]sender.Input, 0, len(sending.Given.Vin))
+				for _, vin := range sending.Given.Vin {
+					privateKey, _ := btcec.PrivKeyFromBytes(decodeHex(t, vin.PrivateKey))
+
+					inputs = append(inputs, sender.Input{
+						TxIn:          vin.toWire(t),
+						PrevoutScript: decodeHex(t, vin.Prevout.ScriptPubKey.Hex),
+						PrivateKey:    privateKey,
+					})
+				}
+
+				scripts, err := sender.OutputScripts(inputs, decodeRecipients(t, sending.Given.Recipients))
+				if len(sending.Expected.Outputs) == 0 {
+					require.ErrorIs(t, err, sender.ErrNoEligibleInputs)
+					continue
+				}
+				require.NoError(t, err)
+				require.Len(t, scripts, len(sending.Given.Recipients))
+
+				// the vectors list every valid output if it depends on the order of the recipients
+				found := make([]string, 0, len(scripts))
+				for _, script := range scripts {
+					require.Len(t, script, 34)
+					require.Equal(t, []byte{0x51, 0x20}, script[:2])
+					found = append(found, hex.EncodeToString(script[2:]))
+				}
+
+				if len(sending.Expected.Outputs) == len(scripts) {
+					require.ElementsMatch(t, sending.Expected.Outputs, found)
+				} else {
+					require.Subset(t, sending.Expected.Outputs, found)
+				}
+			}
+		})
+	}
+}
+
+func TestOutputScriptsErrors(t *testing.T) {
+	var testVectors []testVector
+	openJSONFile(t, "../../internal/domain/test_data/test_vectors.json", &testVectors)
+
+	sending := testVectors[0].Sending[0]
+
+	inputs := make([]sender.Input, 0, len(sending.Given.Vin))
+	for _, vin := range sending.Given.Vin {
+		inputs = append(inputs, sender.Input{
+			TxIn:          vin.toWire(t),
+			PrevoutScript: decodeHex(t, vin.Prevout.ScriptPubKey.Hex),
+		})
+	}
+
+	recipients := decodeRecipients(t, sending.Given.Recipients)
+
+	t.Run("missing private key", func(t *testing.T) {
+		_, err := sender.OutputScripts(inputs, recipients)
+		require.ErrorContains(t, err, "missing private key")
+	})
+
+	t.Run("private key mismatch", func(t *testing.T) {
+		// the keys of the inputs are swapped
+		withKeys := make([]sender.Input, 0, len(inputs))
+		for i, input := range inputs {
+			other := sending.Given.Vin[(i+1)%len(inputs)]
+			input.PrivateKey, _ = btcec.PrivKeyFromBytes(decodeHex(t, other.PrivateKey))
+			withKeys = append(withKeys, input)
+		}
+
+		_, err := sender.OutputScripts(withKeys, recipients)
+		require.ErrorIs(t, err, sender.ErrPrivateKeyMismatch)
+	})
+
+	t.Run("no inputs", func(t *testing.T) {
+		_, err := sender.OutputScripts(nil, recipients)
+		require.ErrorIs(t, err, sender.ErrNoEligibleInputs)
+	})
+}
+
+func decodeRecipients(t *testing.T, recipients []string) []*address.Address {
+	t.Helper()
+
+	addresses := make([]*address.Address, 0, len(recipients))
+	for _, recipient := range recipients {
+		addr, err := address.Decode(recipient, &chaincfg.MainNetParams)
+		require.NoError(t, err)
+		addresses = append(addresses, addr)
+	}
+
+	return addresses
+}
+
+func decodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func decodeWitness(t *testing.T, witness []byte) wire.TxWitness {
+	t.Helper()
+
+	if len(witness) == 0 {
+		return nil
+	}
+
+	reader := bytes.NewReader(witness)
+
+	count, err := wire.ReadVarInt(reader, 0)
+	require.NoError(t, err)
+
+	wit := make(wire.TxWitness, count)
+	for i := range wit {
+		length, err := wire.ReadVarInt(reader, 0)
+		require.NoError(t, err)
+
+		wit[i] = make([]byte, length)
+		_, err = io.ReadFull(reader, wit[i])
+		require.NoError(t, err)
+	}
+
+	return wit
+}
+
+func openJSONFile(t *testing.T, path string, result interface{}) {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	require.NoError(t, json.NewDecoder(file).Decode(result))
+}
+
+type inputVector struct {
+	TxId        string `json:"txid"`
+	Vout        uint32 `json:"vout"`
+	ScriptSig   string `json:"scriptSig"`
+	TxInWitness string `json:"txinwitness"`
+	Prevout     struct {
+		ScriptPubKey struct {
+			Hex string `json:"hex"`
+		} `json:"scriptPubKey"`
+	} `json:"prevout"`
+	PrivateKey string `json:"private_key"`
+}
+
+func (iv *inputVector) toWire(t *testing.T) *wire.TxIn {
+	txid, err := chainhash.NewHashFromStr(iv.TxId)
+	require.NoError(t, err)
+
+	return &wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{Hash: *txid, Index: iv.Vout},
+		SignatureScript:  decodeHex(t, iv.ScriptSig),
+		Witness:          decodeWitness(t, decodeHex(t, iv.TxInWitness)),
+		Sequence:         wire.MaxTxInSequenceNum,
+	}
+}
+
+type testVector struct {
+	Comment string `json:"comment"`
+	Sending []struct {
+		Given struct {
+			Vin        []inputVector `json:"vin"`
+			Recipients []string      `json:"recipients"`
+		} `json:"given"`
+		Expected struct {
+			Outputs []string `json:"outputs"`
+		} `json:"expected"`
+	} `json:"sending"`
+}